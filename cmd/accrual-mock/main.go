@@ -0,0 +1,56 @@
+// Команда accrual-mock — заглушка системы расчёта начислений для локальной
+// разработки и e2e-сценариев без доступа к настоящему сервису.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/accrualmock"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+func main() {
+	addr := flag.String("a", "localhost:8081", "Listen address")
+	path := flag.String("c", os.Getenv("ACCRUAL_MOCK_CONFIG"), "Scenario file (yaml or json)")
+	flag.Parse()
+
+	var cfg accrualmock.Config
+	if *path != "" {
+		var err error
+		if cfg, err = accrualmock.LoadConfig(*path); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           accrualmock.New(cfg).Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("accrual mock listening on %s", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/handler"
+	mw "github.com/IvanOplesnin/gofermart.git/internal/handler/middleware"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+)
+
+const tokenCookieName = "token"
+
+// client ходит в HTTP API с токеном из профиля. Тела запросов и ответов —
+// типы internal/handler, поэтому клиент не расходится с сервером.
+type client struct {
+	http    *http.Client
+	profile profile
+}
+
+func newClient(p profile, timeout time.Duration) *client {
+	return &client{http: &http.Client{Timeout: timeout}, profile: p}
+}
+
+func (c *client) Register(ctx context.Context, login string, password string) error {
+	return c.auth(ctx, "/api/user/register", handler.RegiseterRequest{Login: login, Password: password})
+}
+
+func (c *client) Login(ctx context.Context, login string, password string) error {
+	return c.auth(ctx, "/api/user/login", handler.AuthRequest{Login: login, Password: password})
+}
+
+// auth запоминает токен и CSRF-токен из cookie ответа.
+func (c *client) auth(ctx context.Context, path string, body any) error {
+	resp, err := c.do(ctx, http.MethodPost, path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	c.profile.Token, c.profile.CSRFToken = "", ""
+	for _, ck := range resp.Cookies() {
+		switch ck.Name {
+		case tokenCookieName:
+			c.profile.Token = ck.Value
+		case mw.CSRFCookieName:
+			c.profile.CSRFToken = ck.Value
+		}
+	}
+	if c.profile.Token == "" {
+		return fmt.Errorf("%s: no token in response", path)
+	}
+	return nil
+}
+
+// AddOrder возвращает true, если заказ принят, и false, если он уже был загружен.
+func (c *client) AddOrder(ctx context.Context, number string) (bool, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/api/user/orders", "text/plain", number)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusAccepted, nil
+}
+
+func (c *client) Orders(ctx context.Context) ([]handler.Order, error) {
+	var orders []handler.Order
+	if err := c.getJSON(ctx, "/api/user/orders", &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (c *client) Balance(ctx context.Context) (handler.BalanceResponse, error) {
+	var b handler.BalanceResponse
+	if err := c.getJSON(ctx, "/api/user/balance", &b); err != nil {
+		return handler.BalanceResponse{}, err
+	}
+	return b, nil
+}
+
+func (c *client) Withdraw(ctx context.Context, order string, sum float64) error {
+	resp, err := c.do(ctx, http.MethodPost, "/api/user/balance/withdraw", "application/json",
+		handler.RequestWithdraw{OrderNumber: order, Summa: sum})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *client) Withdrawals(ctx context.Context) ([]handler.Withdraw, error) {
+	var list []handler.Withdraw
+	if err := c.getJSON(ctx, "/api/user/withdrawals", &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// getJSON декодирует тело ответа в v; 204 оставляет v нетронутым.
+func (c *client) getJSON(ctx context.Context, path string, v any) error {
+	resp, err := c.do(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: decode response: %w", path, err)
+	}
+	return nil
+}
+
+// do отправляет запрос и превращает ответы 4xx/5xx в problem.Problem.
+// Строка уходит в теле как есть, остальное — в JSON.
+func (c *client) do(ctx context.Context, method string, path string, contentType string, body any) (*http.Response, error) {
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r = strings.NewReader(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", method, path, err)
+		}
+		r = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.profile.Server, "/")+path, r)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.profile.Token != "" {
+		req.AddCookie(&http.Cookie{Name: tokenCookieName, Value: c.profile.Token})
+	}
+	if c.profile.CSRFToken != "" {
+		req.AddCookie(&http.Cookie{Name: mw.CSRFCookieName, Value: c.profile.CSRFToken})
+		req.Header.Set(mw.CSRFHeader, c.profile.CSRFToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	var p problem.Problem
+	if err := json.Unmarshal(raw, &p); err != nil || p.Code == "" {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(raw)))
+	}
+	return nil, p
+}
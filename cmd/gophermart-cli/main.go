@@ -0,0 +1,186 @@
+// Команда gophermart-cli — клиент HTTP API накопительной системы для ручной
+// проверки и поддержки. Токен после register/login хранится в локальном профиле.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const defaultServer = "http://localhost:8080"
+
+const usage = `usage:
+  gophermart-cli [flags] register <login> <password>
+  gophermart-cli [flags] login <login> <password>
+  gophermart-cli [flags] orders add <number>
+  gophermart-cli [flags] orders list
+  gophermart-cli [flags] orders get <number>
+  gophermart-cli [flags] balance
+  gophermart-cli [flags] withdraw <order> <sum>
+  gophermart-cli [flags] withdrawals
+
+flags:`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := flag.NewFlagSet("gophermart-cli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", "", "API address (default: from profile or "+defaultServer+")")
+	output := fs.String("output", formatTable, "Output format: table or json")
+	profilePath := fs.String("profile", os.Getenv("GOPHERMART_PROFILE"), "Profile file (default: <user config dir>/gophermart/profile.json)")
+	timeout := fs.Duration("timeout", 10*time.Second, "Request timeout")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output != formatTable && *output != formatJSON {
+		return fmt.Errorf("%w: unknown output %q", errUsage, *output)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	if *profilePath == "" {
+		p, err := defaultProfilePath()
+		if err != nil {
+			return err
+		}
+		*profilePath = p
+	}
+	profile, err := loadProfile(*profilePath)
+	if err != nil {
+		return err
+	}
+	if *server != "" {
+		profile.Server = *server
+	}
+	if profile.Server == "" {
+		profile.Server = defaultServer
+	}
+
+	c := newClient(profile, *timeout)
+	out := newPrinter(stdout, *output)
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+
+	switch cmd {
+	case "register", "login":
+		if len(rest) != 2 {
+			return fmt.Errorf("%w: %s <login> <password>", errUsage, cmd)
+		}
+		if cmd == "register" {
+			err = c.Register(ctx, rest[0], rest[1])
+		} else {
+			err = c.Login(ctx, rest[0], rest[1])
+		}
+		if err != nil {
+			return err
+		}
+		if err := saveProfile(*profilePath, c.profile); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "%s: ok, token saved to %s\n", cmd, *profilePath)
+		return nil
+	case "orders":
+		return runOrders(ctx, c, out, rest)
+	case "balance":
+		b, err := c.Balance(ctx)
+		if err != nil {
+			return err
+		}
+		return out.Balance(b)
+	case "withdraw":
+		if len(rest) != 2 {
+			return fmt.Errorf("%w: withdraw <order> <sum>", errUsage)
+		}
+		sum, err := strconv.ParseFloat(rest[1], 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid sum %q", errUsage, rest[1])
+		}
+		if err := c.Withdraw(ctx, rest[0], sum); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "withdrawn %v for order %s\n", sum, rest[0])
+		return nil
+	case "withdrawals":
+		list, err := c.Withdrawals(ctx)
+		if err != nil {
+			return err
+		}
+		return out.Withdrawals(list)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, cmd)
+	}
+}
+
+func runOrders(ctx context.Context, c *client, out *printer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: orders add|list|get", errUsage)
+	}
+	switch args[0] {
+	case "add":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: orders add <number>", errUsage)
+		}
+		accepted, err := c.AddOrder(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if accepted {
+			return out.Message("order %s accepted for processing", args[1])
+		}
+		return out.Message("order %s has already been uploaded", args[1])
+	case "list":
+		orders, err := c.Orders(ctx)
+		if err != nil {
+			return err
+		}
+		return out.Orders(orders)
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: orders get <number>", errUsage)
+		}
+		// Отдельного метода для одного заказа в API нет: ищем в списке.
+		orders, err := c.Orders(ctx)
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			if o.Number == args[1] {
+				return out.Order(o)
+			}
+		}
+		return fmt.Errorf("order %s not found", args[1])
+	default:
+		return fmt.Errorf("%w: unknown orders command %q", errUsage, args[0])
+	}
+}
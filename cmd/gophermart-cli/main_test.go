@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+)
+
+// fakeAPI отвечает так же, как gophermart, и проверяет cookie с токеном.
+func fakeAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	authorized := func(r *http.Request) bool {
+		c, err := r.Cookie(tokenCookieName)
+		return err == nil && c.Value == "secret-token"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/user/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: tokenCookieName, Value: "secret-token", Path: "/api"})
+	})
+	mux.HandleFunc("GET /api/user/orders", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			problem.Write(w, r, problem.New(http.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"number":"12345678903","status":"PROCESSED","accrual":500,"uploaded_at":"2026-02-19T12:00:00Z"},` +
+			`{"number":"79927398713","status":"NEW","uploaded_at":"2026-02-19T13:00:00Z"}]`))
+	})
+	mux.HandleFunc("GET /api/user/withdrawals", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/user/balance/withdraw", func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.New(http.StatusPaymentRequired, apierror.CodeInsufficientFunds, "not enough balance"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun(t *testing.T) {
+	srv := fakeAPI(t)
+	profilePath := filepath.Join(t.TempDir(), "profile.json")
+	cli := func(args ...string) (string, error) {
+		var stdout, stderr bytes.Buffer
+		args = append([]string{"-server", srv.URL, "-profile", profilePath}, args...)
+		err := run(context.Background(), args, &stdout, &stderr)
+		return stdout.String(), err
+	}
+
+	var p problem.Problem
+	if _, err := cli("orders", "list"); !errors.As(err, &p) || p.Code != apierror.CodeUnauthorized {
+		t.Fatalf("orders list before login: err = %v", err)
+	}
+
+	if _, err := cli("login", "alice", "pw"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	saved, err := loadProfile(profilePath)
+	if err != nil || saved.Token != "secret-token" || saved.Server != srv.URL {
+		t.Fatalf("profile = %+v, err = %v", saved, err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "orders table",
+			args: []string{"orders", "list"},
+			want: []string{"NUMBER", "12345678903  PROCESSED  500.00", "79927398713  NEW        -"},
+		},
+		{
+			name: "order as json",
+			args: []string{"-output", "json", "orders", "get", "79927398713"},
+			want: []string{`"number": "79927398713"`, `"accrual": null`},
+		},
+		{name: "unknown order", args: []string{"orders", "get", "1"}, wantErr: "order 1 not found"},
+		{name: "empty withdrawals", args: []string{"withdrawals"}, want: []string{"no data"}},
+		{name: "empty withdrawals as json", args: []string{"-output", "json", "withdrawals"}, want: []string{"[]"}},
+		{name: "api error", args: []string{"withdraw", "12345678903", "10"}, wantErr: apierror.CodeInsufficientFunds},
+		{name: "bad sum", args: []string{"withdraw", "12345678903", "ten"}, wantErr: errUsage.Error()},
+		{name: "unknown command", args: []string{"refund"}, wantErr: errUsage.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := cli(tt.args...)
+			checkResult(t, out, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func checkResult(t *testing.T, out string, err error, want []string, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("err = %v, want %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Fatalf("output %q does not contain %q", out, w)
+		}
+	}
+}
+
+func TestProfileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "profile.json")
+	p, err := loadProfile(path)
+	if err != nil || p != (profile{}) {
+		t.Fatalf("missing profile: %+v, %v", p, err)
+	}
+	want := profile{Server: "https://example.com", Token: "t", CSRFToken: "c"}
+	if err := saveProfile(path, want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := loadProfile(path)
+	if err != nil || got != want {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+	raw, _ := json.Marshal(got)
+	if !strings.Contains(string(raw), `"token":"t"`) {
+		t.Fatalf("unexpected json %s", raw)
+	}
+}
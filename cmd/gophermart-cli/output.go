@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/handler"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer выводит ответы таблицей для человека или JSON для скриптов.
+// JSON совпадает с телом ответа API.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) *printer {
+	return &printer{w: w, format: format}
+}
+
+func (p *printer) Message(format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if p.format == formatJSON {
+		return p.json(map[string]string{"message": msg})
+	}
+	_, err := fmt.Fprintln(p.w, msg)
+	return err
+}
+
+func (p *printer) Orders(orders []handler.Order) error {
+	if p.format == formatJSON {
+		if orders == nil {
+			orders = []handler.Order{}
+		}
+		return p.json(orders)
+	}
+	return p.table([]string{"NUMBER", "STATUS", "ACCRUAL", "UPLOADED AT"}, len(orders), func(i int) []string {
+		o := orders[i]
+		accrual := "-"
+		if o.Accrual != nil {
+			accrual = formatSum(*o.Accrual)
+		}
+		return []string{o.Number, o.Status, accrual, formatTime(o.UploadedAt)}
+	})
+}
+
+func (p *printer) Order(o handler.Order) error {
+	if p.format == formatJSON {
+		return p.json(o)
+	}
+	return p.Orders([]handler.Order{o})
+}
+
+func (p *printer) Balance(b handler.BalanceResponse) error {
+	if p.format == formatJSON {
+		return p.json(b)
+	}
+	return p.table([]string{"CURRENT", "WITHDRAWN"}, 1, func(int) []string {
+		return []string{formatSum(b.Current), formatSum(b.Withdrawn)}
+	})
+}
+
+func (p *printer) Withdrawals(list []handler.Withdraw) error {
+	if p.format == formatJSON {
+		if list == nil {
+			list = []handler.Withdraw{}
+		}
+		return p.json(list)
+	}
+	return p.table([]string{"ORDER", "SUM", "PROCESSED AT"}, len(list), func(i int) []string {
+		w := list[i]
+		return []string{w.OrderNumber, formatSum(w.Summa), formatTime(w.ProcessedAt)}
+	})
+}
+
+func (p *printer) json(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *printer) table(header []string, n int, row func(i int) []string) error {
+	if n == 0 {
+		_, err := fmt.Fprintln(p.w, "no data")
+		return err
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	writeRow(tw, header)
+	for i := range n {
+		writeRow(tw, row(i))
+	}
+	return tw.Flush()
+}
+
+func writeRow(w io.Writer, cells []string) {
+	for i, c := range cells {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, c)
+	}
+	fmt.Fprintln(w)
+}
+
+func formatSum(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatTime(t handler.RFC3339Time) string {
+	return time.Time(t).Format("2006-01-02 15:04:05")
+}
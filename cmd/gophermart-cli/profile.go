@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// profile хранит адрес сервера и токены последнего входа.
+type profile struct {
+	Server    string `json:"server"`
+	Token     string `json:"token,omitempty"`
+	CSRFToken string `json:"csrf_token,omitempty"`
+}
+
+func defaultProfilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("profile: %w", err)
+	}
+	return filepath.Join(dir, "gophermart", "profile.json"), nil
+}
+
+// loadProfile возвращает пустой профиль, если файла ещё нет.
+func loadProfile(path string) (profile, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return profile{}, nil
+	}
+	if err != nil {
+		return profile{}, fmt.Errorf("load profile: %w", err)
+	}
+	var p profile
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return profile{}, fmt.Errorf("load profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// saveProfile пишет профиль с правами 0600: в нём лежит токен.
+func saveProfile(path string, p profile) error {
+	raw, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save profile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("save profile: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		return fmt.Errorf("save profile: %w", err)
+	}
+	return nil
+}
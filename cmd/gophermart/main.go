@@ -1,41 +1,132 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	accrualclient "github.com/IvanOplesnin/gofermart.git/internal/accrual_client"
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
 	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/events"
+	"github.com/IvanOplesnin/gofermart.git/internal/grpcapi"
 	"github.com/IvanOplesnin/gofermart.git/internal/handler"
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/metrics"
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
 	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql"
+	"github.com/IvanOplesnin/gofermart.git/internal/server"
 	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	"github.com/IvanOplesnin/gofermart.git/internal/service/hasher"
-	migrate "github.com/IvanOplesnin/gofermart.git/migrations"
+	"github.com/IvanOplesnin/gofermart.git/internal/webhooks"
 	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc"
 )
 
+const shutdownTimeout = 10 * time.Second
+
+const usage = `usage:
+  gophermart [serve] [flags]
+  gophermart migrate up|down|status|redo [flags]
+  gophermart migrate to <version> [flags]
+  gophermart migrate create <name> [-dir dir]`
+
 func main() {
-	cfg := config.InitConfig()
+	cmd, args := splitCommand(os.Args[1:])
+	switch cmd {
+	case "serve":
+		runServe(args)
+	case "migrate":
+		if err := runMigrateCommand(args); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown command %q\n%s", cmd, usage)
+	}
+}
+
+// splitCommand отделяет подкоманду от флагов. Без подкоманды запускается serve,
+// как и раньше, поэтому старые скрипты запуска продолжают работать.
+func splitCommand(args []string) (string, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "serve", args
+	}
+	return args[0], args[1:]
+}
+
+func runServe(args []string) {
+	cfg, err := config.Load(args, os.LookupEnv)
+	if err != nil {
+		log.Fatalf("config error: %s", err.Error())
+	}
 	log.Println(cfg)
 	if err := logger.SetupLogger(&cfg.Logger); err != nil {
 		log.Fatalf("error setupLogger: %s", err.Error())
 	}
-	if err := runMigrate(cfg); err != nil {
-		log.Fatal(err)
-	}
 
-	db, err := psql.Connect(cfg.Dsn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db, err := psql.Connect(ctx, cfg.Database)
 	if err != nil {
 		logger.Log.Fatalf("db connect error: %s", err.Error())
 		return
 	}
+	defer db.Close()
+	if err := prepareSchema(ctx, cfg); err != nil {
+		logger.Log.Fatal(err)
+	}
+	metrics.Default.Register(psql.PoolCollector(db, "primary"))
+	go psql.MonitorPool(ctx, db, cfg.Database.SaturationCheck)
 	repo := psql.NewRepo(db)
+	if cfg.Database.Replica.DSN != "" {
+		replicaCfg := cfg.Database
+		replicaCfg.DSN = cfg.Database.Replica.DSN
+		replicaDB, err := psql.Open(ctx, replicaCfg)
+		if err != nil {
+			logger.Log.Fatalf("db replica open error: %s", err.Error())
+		}
+		defer replicaDB.Close()
+		metrics.Default.Register(psql.PoolCollector(replicaDB, "replica"))
+		repo = psql.NewRepoWithReplica(db, replicaDB, cfg.Database.Replica)
+		go repo.MonitorReplica(ctx)
+	}
 	hasher := hasher.NewSHA256()
-	accrualClient := accrualclient.New(cfg.AccrualServiceAddress, nil)
+	accrualClient := accrualclient.NewFromConfig(cfg.Accrual)
+	metrics.Default.Register(breaker.Collector(accrualClient.Breaker(), "accrual"))
+
+	sink, closeSink, err := outbox.NewSink(cfg.Outbox)
+	if err != nil {
+		logger.Log.Fatalf("outbox sink error: %s", err.Error())
+	}
+	defer func() { _ = closeSink() }()
+	var sinks outbox.Fanout
+	if sink != nil {
+		sinks = append(sinks, sink)
+	}
+	var dispatcher gophermart.Runner
+	if cfg.Webhooks.Enabled {
+		sinks = append(sinks, webhooks.NewFanoutSink(repo))
+		dispatcher = webhooks.NewDispatcher(repo, webhooks.OptionsFromConfig(cfg.Webhooks))
+	}
+	var relay gophermart.Runner
+	if len(sinks) > 0 {
+		var s outbox.Sink = sinks
+		if len(sinks) == 1 {
+			s = sinks[0]
+		}
+		r := outbox.NewRelay(repo, s, outbox.OptionsFromConfig(cfg.Outbox))
+		metrics.Default.Register(r.Collector())
+		relay = r
+	}
+
+	broker := events.NewBroker(repo)
 
 	svc, err := gophermart.New(cfg, gophermart.ServiceDeps{
 		Hasher:        hasher,
@@ -43,8 +134,15 @@ func main() {
 		WorkerDB:      repo,
 		Ordered:       repo,
 		AccrualClient: accrualClient,
+		AccrualHealth: accrualClient.Breaker(),
 		BalanceDB:     repo,
 		WithdrawerDB:  repo,
+		WebhookDB:     repo,
+		EventsDB:      repo,
+		Events:        broker,
+
+		Relay:             relay,
+		WebhookDispatcher: dispatcher,
 	})
 	if err != nil {
 		logger.Log.Fatalf("svc create error: %s", err.Error())
@@ -60,33 +158,116 @@ func main() {
 		Ordered:      svc,
 		Balancer:     svc,
 		Withdrawer:   svc,
+		Webhooks:     svc,
+		Events:       svc,
+
+		ValidateRequests: cfg.Server.ValidateRequests,
+		CORS:             cfg.Server.CORS,
+		Cookies: handler.CookieOptions{
+			Cookie: cfg.Server.Cookie,
+			CSRF:   cfg.Server.CSRFEnabled,
+		},
 	})
 
-	logger.Log.Infof("Listen on %s", cfg.RunAddress)
+	servers := make([]listener, 0, 3)
+	srv, err := server.New("api", cfg.Server.RunAddress, mux, cfg.Server.TLS, "")
+	if err != nil {
+		logger.Log.Fatalf("server create error: %s", err.Error())
+	}
+	srv.RegisterOnShutdown(broker.Stop)
+	servers = append(servers, srv)
+
+	if cfg.Server.Admin.Address != "" {
+		adminMux := handler.InitAdminHandler(handler.AdminDeps{
+			DB:      db,
+			Accrual: accrualClient.Breaker(),
+			Metrics: metrics.Default.Handler(),
+
+			AccrualCallback: svc,
+			CallbackSecret:  []byte(cfg.Accrual.CallbackSecret),
+		})
+		adminSrv, err := server.New("admin", cfg.Server.Admin.Address, adminMux, cfg.Server.Admin.TLS, cfg.Server.Admin.ClientCAFile)
+		if err != nil {
+			logger.Log.Fatalf("admin server create error: %s", err.Error())
+		}
+		servers = append(servers, adminSrv)
+	}
+
+	if cfg.Server.GRPC.Address != "" {
+		grpcSrv, err := server.NewGRPC("grpc", cfg.Server.GRPC.Address, cfg.Server.GRPC.TLS, func(opts ...grpc.ServerOption) *grpc.Server {
+			return grpcapi.New(grpcapi.Deps{
+				Registrar:    svc,
+				Auther:       svc,
+				TokenChecker: svc,
+				Ordered:      svc,
+				Balancer:     svc,
+				Withdrawer:   svc,
+			}, opts...)
+		})
+		if err != nil {
+			logger.Log.Fatalf("grpc server create error: %s", err.Error())
+		}
+		servers = append(servers, grpcSrv)
+	}
 
-	if err := http.ListenAndServe(cfg.RunAddress, mux); err != nil {
-		logger.Log.Fatalf("error ListenAndServe: %s", err.Error())
+	if err := serve(servers); err != nil {
+		logger.Log.Errorf("serve error: %s", err.Error())
 	}
 }
 
-func runMigrate(cfg *config.Config) error {
-	if cfg.Dsn == "" {
-		return nil
+// listener — HTTP- или gRPC-сервер приложения.
+type listener interface {
+	ListenAndServe() error
+	Shutdown(ctx context.Context) error
+}
+
+// serve запускает серверы и останавливает их все по сигналу или при падении любого из них.
+func serve(servers []listener) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, len(servers))
+	for _, s := range servers {
+		go func() { errCh <- s.ListenAndServe() }()
 	}
-	db, err := sql.Open("pgx", cfg.Dsn)
-	if err != nil {
-		return fmt.Errorf("open db: %w", err)
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		logger.Log.Info("shutdown signal received")
+	case serveErr = <-errCh:
 	}
-	defer db.Close()
 
-	if err := migrate.Up(db); err != nil {
-		if isInsufficientPrivilege(err) {
-			logger.Log.Infof("migrations skipped (insufficient privileges): %v", err)
-			return nil
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, s := range servers {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			logger.Log.Errorf("shutdown error: %s", err.Error())
 		}
-		return fmt.Errorf("migrate up: %w", err)
 	}
+	return serveErr
+}
+
+// prepareSchema применяет миграции, если это разрешено, и проверяет, что схема
+// не отстаёт от версии, которую ожидает бинарь.
+func prepareSchema(ctx context.Context, cfg *config.Config) error {
+	m, err := openMigrator(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
 
+	if cfg.Database.MigrateOnStart {
+		if _, err := m.Up(ctx); err != nil {
+			if isInsufficientPrivilege(err) {
+				return fmt.Errorf("%w; run `gophermart migrate up` as the schema owner or disable -migrate-on-start", err)
+			}
+			return err
+		}
+	}
+	if err := m.Check(ctx); err != nil {
+		return fmt.Errorf("%w; run `gophermart migrate up`", err)
+	}
 	return nil
 }
 
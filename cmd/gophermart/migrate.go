@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	migrate "github.com/IvanOplesnin/gofermart.git/migrations"
+	"github.com/pressly/goose/v3"
+)
+
+// runMigrateCommand выполняет `gophermart migrate <action> [args] [flags]`.
+// Флаги те же, что и у serve, но проверяются только параметры базы.
+func runMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate: action required\n%s", usage)
+	}
+	action, args := args[0], args[1:]
+
+	if action == "create" {
+		return runMigrateCreate(args)
+	}
+
+	var version int64
+	if action == "to" {
+		if len(args) == 0 {
+			return fmt.Errorf("migrate to: version required\n%s", usage)
+		}
+		v, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("migrate to: invalid version %q", args[0])
+		}
+		version, args = v, args[1:]
+	}
+
+	cfg, err := config.LoadDatabase(args, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	if err := logger.SetupLogger(&cfg.Logger); err != nil {
+		return fmt.Errorf("setup logger: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	m, err := openMigrator(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch action {
+	case "up":
+		res, err := m.Up(ctx)
+		printResults(res...)
+		return err
+	case "down":
+		res, err := m.Down(ctx)
+		if res != nil {
+			printResults(res)
+		}
+		return err
+	case "redo":
+		res, err := m.Redo(ctx)
+		printResults(res...)
+		return err
+	case "to":
+		res, err := m.To(ctx, version)
+		printResults(res...)
+		return err
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(st)
+		return nil
+	default:
+		return fmt.Errorf("migrate: unknown action %q\n%s", action, usage)
+	}
+}
+
+func runMigrateCreate(args []string) error {
+	fs := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := fs.String("dir", migrate.Dir, "Directory with migration files")
+	if len(args) == 0 {
+		return fmt.Errorf("migrate create: name required\n%s", usage)
+	}
+	name := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	return migrate.Create(*dir, name)
+}
+
+// openMigrator открывает отдельное соединение без statement_timeout пула:
+// миграции могут выполняться дольше обычных запросов.
+func openMigrator(dsn string) (*migrate.Migrator, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	m, err := migrate.New(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func printResults(res ...*goose.MigrationResult) {
+	if len(res) == 0 {
+		fmt.Println("no migrations to apply")
+		return
+	}
+	for _, r := range res {
+		fmt.Println(r.String())
+	}
+}
+
+func printStatus(st []*goose.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+	for _, s := range st {
+		applied := "-"
+		if !s.AppliedAt.IsZero() {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, applied, s.Source.Path)
+	}
+	_ = w.Flush()
+}
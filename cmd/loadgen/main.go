@@ -0,0 +1,121 @@
+// Команда loadgen — нагрузочный генератор для gophermart. Каждый из -users
+// виртуальных пользователей регистрируется и до истечения -duration в цикле
+// загружает заказ, запрашивает заказы и баланс и время от времени списывает
+// баллы. В конце печатается сводка по операциям: число запросов, RPS, доля
+// ошибок и перцентили задержки.
+//
+// С -accrual loadgen задаёт каждому заказу сценарий PROCESSED в accrual-mock
+// (PUT /mock/orders/{number}), чтобы на балансе появлялись баллы для списаний.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/pkg/client"
+)
+
+var errUsage = errors.New("invalid usage")
+
+type options struct {
+	server        string
+	accrual       string
+	users         int
+	duration      time.Duration
+	think         time.Duration
+	timeout       time.Duration
+	points        float64
+	withdrawEvery int
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	var opts options
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.server, "server", "http://localhost:8080", "gophermart API address")
+	fs.StringVar(&opts.accrual, "accrual", "", "accrual-mock address; empty — do not script accruals")
+	fs.IntVar(&opts.users, "users", 10, "Number of virtual users")
+	fs.DurationVar(&opts.duration, "duration", 30*time.Second, "Test duration")
+	fs.DurationVar(&opts.think, "think", 0, "Pause between iterations of one user")
+	fs.DurationVar(&opts.timeout, "timeout", 10*time.Second, "Request timeout")
+	fs.Float64Var(&opts.points, "points", 100, "Accrual per order scripted in accrual-mock")
+	fs.IntVar(&opts.withdrawEvery, "withdraw-every", 5, "Withdraw on every N-th iteration; 0 disables withdrawals")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || opts.users <= 0 || opts.duration <= 0 || opts.withdrawEvery < 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	httpClient := &http.Client{
+		Timeout: opts.timeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConnsPerHost: opts.users,
+		},
+	}
+	var mock *mockControl
+	if opts.accrual != "" {
+		mock = newMockControl(opts.accrual, httpClient)
+	}
+
+	rec := newRecorder()
+	numbers := newOrderNumbers(time.Now())
+	runID := time.Now().UnixNano()
+
+	ctx, cancel := context.WithTimeout(ctx, opts.duration)
+	defer cancel()
+	fmt.Fprintf(stderr, "loadgen: %d users for %s against %s\n", opts.users, opts.duration, opts.server)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := range opts.users {
+		api, err := client.New(opts.server, client.WithHTTPClient(httpClient))
+		if err != nil {
+			return err
+		}
+		u := &virtualUser{
+			login:   fmt.Sprintf("loadgen_%d_%d", runID, i),
+			api:     api,
+			mock:    mock,
+			rec:     rec,
+			numbers: numbers,
+			opts:    &opts,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	return rec.report(stdout, time.Since(start))
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/accrualmock"
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+)
+
+func TestPercentile(t *testing.T) {
+	sample := make([]time.Duration, 100)
+	for i := range sample {
+		sample[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{name: "empty", sorted: nil, p: 50, want: 0},
+		{name: "single", sorted: []time.Duration{time.Second}, p: 99, want: time.Second},
+		{name: "p50", sorted: sample, p: 50, want: 50 * time.Millisecond},
+		{name: "p99", sorted: sample, p: 99, want: 99 * time.Millisecond},
+		{name: "p100", sorted: sample, p: 100, want: 100 * time.Millisecond},
+		{name: "p0", sorted: sample, p: 0, want: time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderNumbers(t *testing.T) {
+	numbers := newOrderNumbers(time.Now())
+	seen := make(map[string]bool)
+	for range 1000 {
+		n := numbers.next()
+		if seen[n] {
+			t.Fatalf("duplicate number %s", n)
+		}
+		seen[n] = true
+		if !luhnValid(n) {
+			t.Fatalf("%s does not pass the Luhn check", n)
+		}
+	}
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if (len(number)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func TestErrKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: fmt.Errorf("wrap: %w", context.DeadlineExceeded), want: "timeout"},
+		{err: errors.New("connection refused"), want: "transport"},
+	}
+	for _, tt := range tests {
+		if got := errKind(tt.err); got != tt.want {
+			t.Errorf("errKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// fakeAPI отвечает как gophermart; списание всегда отклоняется с 402.
+func fakeAPI(t *testing.T, uploads *atomic.Int64) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/user/register", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "token", Value: "t", Path: "/api"})
+	})
+	mux.HandleFunc("POST /api/user/orders", func(w http.ResponseWriter, r *http.Request) {
+		uploads.Add(1)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("GET /api/user/orders", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/user/balance", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"current":10,"withdrawn":0}`))
+	})
+	mux.HandleFunc("POST /api/user/balance/withdraw", func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.New(http.StatusPaymentRequired, apierror.CodeInsufficientFunds, "not enough balance"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun(t *testing.T) {
+	var uploads atomic.Int64
+	api := fakeAPI(t, &uploads)
+	_, mockSrv := accrualmock.NewTestServer(accrualmock.Config{})
+	t.Cleanup(mockSrv.Close)
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), []string{
+		"-server", api.URL, "-accrual", mockSrv.URL,
+		"-users", "3", "-duration", "200ms", "-withdraw-every", "1",
+	}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	if uploads.Load() == 0 {
+		t.Fatal("no orders uploaded")
+	}
+
+	out := stdout.String()
+	for _, want := range []string{"OP", "register", "mock_script", "upload_order", "balance", "withdraw", "total", "withdraw 402:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q:\n%s", want, out)
+		}
+	}
+
+	resp, err := http.Get(mockSrv.URL + "/mock/orders")
+	if err != nil {
+		t.Fatalf("list scripts: %v", err)
+	}
+	defer resp.Body.Close()
+	var scripts accrualmock.Config
+	if err := json.NewDecoder(resp.Body).Decode(&scripts); err != nil || len(scripts.Orders) == 0 {
+		t.Fatalf("accrual scripts = %+v, %v", scripts, err)
+	}
+	for number, steps := range scripts.Orders {
+		if steps[0].Status != accrualmock.StatusProcessed || steps[0].Accrual != 100 {
+			t.Fatalf("script for %s = %+v", number, steps)
+		}
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{{"-users", "0"}, {"-duration", "0s"}, {"extra"}} {
+		var stdout, stderr bytes.Buffer
+		if err := run(context.Background(), args, &stdout, &stderr); !errors.Is(err, errUsage) {
+			t.Errorf("run(%v) = %v, want errUsage", args, err)
+		}
+	}
+}
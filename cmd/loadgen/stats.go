@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/pkg/client"
+)
+
+// recorder собирает задержки и ошибки по операциям; безопасен для конкурентного использования.
+type recorder struct {
+	mu  sync.Mutex
+	ops map[string]*opStats
+}
+
+type opStats struct {
+	latencies []time.Duration
+	errors    map[string]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{ops: make(map[string]*opStats)}
+}
+
+func (r *recorder) add(op string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.ops[op]
+	if !ok {
+		s = &opStats{errors: make(map[string]int)}
+		r.ops[op] = s
+	}
+	s.latencies = append(s.latencies, d)
+	if err != nil {
+		s.errors[errKind(err)]++
+	}
+}
+
+// errKind группирует ошибки для отчёта: по статусу ответа, таймауту или сетевой ошибке.
+func errKind(err error) string {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) {
+		return strconv.Itoa(apiErr.StatusCode)
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return "timeout"
+	}
+	return "transport"
+}
+
+// percentile возвращает p-й перцентиль отсортированной выборки (метод ближайшего ранга).
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+func (r *recorder) report(w io.Writer, elapsed time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OP\tREQUESTS\tRPS\tERRORS\tERR%\tP50\tP90\tP99\tMAX")
+	var total, totalErrs int
+	for _, op := range opsOrder {
+		s, ok := r.ops[op]
+		if !ok {
+			continue
+		}
+		sorted := slices.Clone(s.latencies)
+		slices.Sort(sorted)
+		n, errs := len(sorted), 0
+		for _, c := range s.errors {
+			errs += c
+		}
+		total, totalErrs = total+n, totalErrs+errs
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%d\t%.2f\t%s\t%s\t%s\t%s\n",
+			op, n, float64(n)/elapsed.Seconds(), errs, 100*float64(errs)/float64(n),
+			round(percentile(sorted, 50)), round(percentile(sorted, 90)),
+			round(percentile(sorted, 99)), round(sorted[n-1]))
+	}
+	if total > 0 {
+		fmt.Fprintf(tw, "total\t%d\t%.1f\t%d\t%.2f\t\t\t\t\n",
+			total, float64(total)/elapsed.Seconds(), totalErrs, 100*float64(totalErrs)/float64(total))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if totalErrs == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nerrors:")
+	for _, op := range opsOrder {
+		s, ok := r.ops[op]
+		if !ok {
+			continue
+		}
+		kinds := make([]string, 0, len(s.errors))
+		for kind := range s.errors {
+			kinds = append(kinds, kind)
+		}
+		slices.Sort(kinds)
+		for _, kind := range kinds {
+			fmt.Fprintf(w, "  %s %s: %d\n", op, kind, s.errors[kind])
+		}
+	}
+	return nil
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/IvanOplesnin/gofermart.git/pkg/client"
+)
+
+// Операции в отчёте, в порядке вывода.
+const (
+	opRegister    = "register"
+	opMockScript  = "mock_script"
+	opUploadOrder = "upload_order"
+	opOrders      = "orders"
+	opBalance     = "balance"
+	opWithdraw    = "withdraw"
+)
+
+var opsOrder = []string{opRegister, opMockScript, opUploadOrder, opOrders, opBalance, opWithdraw}
+
+// withdrawSum — сколько баллов списывает виртуальный пользователь за раз.
+const withdrawSum = 1
+
+type virtualUser struct {
+	login   string
+	api     *client.Client
+	mock    *mockControl
+	rec     *recorder
+	numbers *orderNumbers
+	opts    *options
+}
+
+func (u *virtualUser) run(ctx context.Context) {
+	if err := u.call(ctx, opRegister, func() error { return u.api.Register(ctx, u.login, "loadgen-password") }); err != nil {
+		return
+	}
+	var balance client.Balance
+	for i := 1; ctx.Err() == nil; i++ {
+		number := u.numbers.next()
+		if u.mock != nil {
+			_ = u.call(ctx, opMockScript, func() error { return u.mock.process(ctx, number, u.opts.points) })
+		}
+		_ = u.call(ctx, opUploadOrder, func() error {
+			_, err := u.api.AddOrder(ctx, number)
+			return err
+		})
+		_ = u.call(ctx, opOrders, func() error {
+			_, err := u.api.Orders(ctx)
+			return err
+		})
+		_ = u.call(ctx, opBalance, func() error {
+			b, err := u.api.Balance(ctx)
+			if err == nil {
+				balance = b
+			}
+			return err
+		})
+		// Списываем, только когда баллов заведомо хватает: 402 здесь — ошибка.
+		if u.opts.withdrawEvery > 0 && i%u.opts.withdrawEvery == 0 && balance.Current >= withdrawSum {
+			_ = u.call(ctx, opWithdraw, func() error { return u.api.Withdraw(ctx, u.numbers.next(), withdrawSum) })
+		}
+
+		if u.opts.think > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(u.opts.think):
+			}
+		}
+	}
+}
+
+// call замеряет f. Запросы, прерванные окончанием теста, в статистику не попадают.
+func (u *virtualUser) call(ctx context.Context, op string, f func() error) error {
+	start := time.Now()
+	err := f()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	u.rec.add(op, time.Since(start), err)
+	return err
+}
+
+// orderNumbers выдаёт уникальные номера, проходящие проверку Луна.
+// Старшие разряды берутся из времени запуска, поэтому номера разных
+// запусков не пересекаются и не дают 409 на повторном прогоне.
+type orderNumbers struct {
+	base int64
+	seq  atomic.Int64
+}
+
+func newOrderNumbers(now time.Time) *orderNumbers {
+	return &orderNumbers{base: now.Unix() % 1_000_000 * 1_000_000_000}
+}
+
+func (n *orderNumbers) next() string {
+	return gophermart.WithCheckDigit(strconv.FormatInt(n.base+n.seq.Add(1), 10))
+}
+
+// mockControl задаёт сценарии через управляющее API accrual-mock.
+type mockControl struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newMockControl(baseURL string, hc *http.Client) *mockControl {
+	return &mockControl{baseURL: strings.TrimRight(baseURL, "/"), http: hc}
+}
+
+// process делает так, что заказ number сразу обработан с начислением points.
+func (m *mockControl) process(ctx context.Context, number string, points float64) error {
+	body, err := json.Marshal(map[string]any{"status": "PROCESSED", "accrual": points})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, m.baseURL+"/mock/orders/"+number, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return &client.Error{StatusCode: resp.StatusCode, Message: fmt.Sprintf("PUT /mock/orders/%s", number)}
+	}
+	return nil
+}
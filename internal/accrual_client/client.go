@@ -3,84 +3,216 @@ package accrualclient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
 	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 )
 
+const (
+	defaultTimeout    = 20 * time.Second
+	defaultBackoff    = 200 * time.Millisecond
+	maxBackoff        = 5 * time.Second
+	maxResponseLength = 1 << 20
+)
+
+// Options — параметры клиента. Нулевые значения заменяются умолчаниями.
+type Options struct {
+	// HTTPClient позволяет подменить транспорт, например в тестах.
+	// Если не задан, используется клиент с собственным пулом соединений.
+	HTTPClient *http.Client
+	// Timeout ограничивает одну попытку запроса.
+	Timeout time.Duration
+	// Retries — число повторов на 5xx и сетевых ошибках. 429 не повторяется.
+	Retries      int
+	RetryBackoff time.Duration
+	MaxIdleConns int
+	// Breaker размыкает цепь после серии неудач. Если не задан, создаётся свой.
+	Breaker *breaker.Breaker
+}
+
 type Client struct {
 	baseURL string
-	timeOut time.Duration
+	http    *http.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	breaker *breaker.Breaker
 }
 
-func New(baseURL string, timeOut *time.Duration) *Client {
-	t := (time.Second * 20) 
-	if timeOut == nil {
-		timeOut = &t
-	} 
-	return &Client{
+func New(baseURL string, opts Options) *Client {
+	c := &Client{
 		baseURL: baseURL,
-		timeOut: *timeOut,
+		http:    opts.HTTPClient,
+		timeout: opts.Timeout,
+		retries: max(opts.Retries, 0),
+		backoff: opts.RetryBackoff,
+		breaker: opts.Breaker,
 	}
-}
-
-func (c *Client) GetOrder(ctx context.Context, number string) (response *gophermart.AccrualResponse, err error) {
-	uri, err := url.JoinPath(c.baseURL, "/api/orders", number)
-	if err != nil {
-		return nil, fmt.Errorf("acrualClient.GetOrder: %s", err)
+	if c.http == nil {
+		c.http = &http.Client{Transport: NewTransport(opts.MaxIdleConns)}
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultTimeout
 	}
-	mapStatus := map[string]string{
-		"REGISTERED": "NEW",
-		"INVALID":    "INVALID",
-		"PROCESSING": "PROCESSING",
-		"PROCESSED":  "PROCESSED",
+	if c.backoff <= 0 {
+		c.backoff = defaultBackoff
 	}
+	if c.breaker == nil {
+		c.breaker = breaker.New(breaker.Options{})
+	}
+	return c
+}
 
-	ctxTimeout, cancel := context.WithTimeout(ctx, c.timeOut)
-	defer cancel()
+// NewFromConfig собирает клиент по настройкам из конфига.
+func NewFromConfig(cfg config.Accrual) *Client {
+	return New(cfg.Address, Options{
+		Timeout:      cfg.Timeout,
+		Retries:      cfg.Retries,
+		RetryBackoff: cfg.RetryBackoff,
+		MaxIdleConns: cfg.MaxIdleConns,
+		Breaker: breaker.New(breaker.Options{
+			Threshold:   cfg.BreakerThreshold,
+			OpenTimeout: cfg.BreakerOpenTimeout,
+		}),
+	})
+}
 
-	request, err := http.NewRequestWithContext(ctxTimeout, http.MethodGet, uri, nil)
-	if err != nil {
-		return nil, fmt.Errorf("acrualClient.GetOrder: %w", err)
+// NewTransport возвращает транспорт, который держит до maxIdle соединений
+// к системе начислений: воркер обращается к одному хосту из нескольких горутин.
+func NewTransport(maxIdle int) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if maxIdle > 0 {
+		t.MaxIdleConns = maxIdle
+		t.MaxIdleConnsPerHost = maxIdle
 	}
-	resp, err := http.DefaultClient.Do(request)
+	return t
+}
+
+// Breaker возвращает circuit breaker клиента, чтобы воркер мог следить за его состоянием.
+func (c *Client) Breaker() *breaker.Breaker {
+	return c.breaker
+}
+
+type result struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// retryable — ошибки, после которых имеет смысл повторить запрос и которые
+// говорят о недоступности сервиса.
+func (r *result) retryable(err error) bool {
+	return err != nil || r.status >= http.StatusInternalServerError
+}
+
+func (c *Client) GetOrder(ctx context.Context, number string) (*gophermart.AccrualResponse, error) {
+	const op = "accrualClient.GetOrder"
+	log := logger.FromContext(ctx)
+
+	uri, err := url.JoinPath(c.baseURL, "/api/orders", number)
 	if err != nil {
-		return nil, fmt.Errorf("acrualClient.GetOrder: %w", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	logger.Log.Debugf("accrualClient.GetOrder: resp.StatusCode: %d", resp.StatusCode)
-	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, gophermart.ErrToManyRequests
+	if err := c.breaker.Allow(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, gophermart.ErrAccrualUnavailable, err)
 	}
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		logger.Log.Warnf("accrualClient.GetOrder: %s", resp.Request.URL)
-		return nil, fmt.Errorf("status code: %v", resp.StatusCode)
+
+	res, err := c.doWithRetry(ctx, uri)
+	switch {
+	case ctx.Err() != nil:
+		c.breaker.Release()
+		return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+	case res.retryable(err):
+		c.breaker.Failure()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %w", op, gophermart.ErrAccrualUnavailable, err)
+		}
+		return nil, fmt.Errorf("%s: %w: status code %d", op, gophermart.ErrAccrualUnavailable, res.status)
+	default:
+		c.breaker.Success()
 	}
-	if resp.StatusCode == http.StatusNoContent {
+
+	log.Debugf("%s: resp.StatusCode: %d", op, res.status)
+	switch res.status {
+	case http.StatusTooManyRequests:
+		log.Warnf("%s: 429 Retry-After=%s", op, res.header.Get("Retry-After"))
+		return nil, gophermart.ErrToManyRequests
+	case http.StatusNoContent:
 		return &gophermart.AccrualResponse{
-			Status:      "NEW",
-			OrderNumber: number,
+			Status:       gophermart.StatusNew,
+			OrderNumber:  number,
+			Unregistered: true,
 		}, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("%s: status code: %d", op, res.status)
+	}
+
+	var accrualResponse gophermart.AccrualResponse
+	if err := json.Unmarshal(res.body, &accrualResponse); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	status, ok := gophermart.StatusFromAccrual(accrualResponse.Status)
+	if !ok {
+		return nil, fmt.Errorf("%s: status not found: %s", op, accrualResponse.Status)
+	}
+	accrualResponse.Status = status
+	log.Debugf("%s: raw string: %s", op, string(res.body))
+	log.Debugf("%s: accrualResponse: %s", op, accrualResponse)
+	return &accrualResponse, nil
+}
+
+// doWithRetry повторяет запрос с экспоненциальной задержкой, пока ответ
+// retryable и не исчерпаны попытки.
+func (c *Client) doWithRetry(ctx context.Context, uri string) (*result, error) {
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		res, err := c.do(ctx, uri)
+		if !res.retryable(err) || attempt >= c.retries || ctx.Err() != nil {
+			return res, err
+		}
+		if err != nil {
+			logger.FromContext(ctx).Debugf("accrualClient: attempt %d failed: %v", attempt+1, err)
+		} else {
+			logger.FromContext(ctx).Debugf("accrualClient: attempt %d failed: status %d", attempt+1, res.status)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxBackoff)
 	}
-	raw, err := io.ReadAll(resp.Body)
+}
+
+func (c *Client) do(ctx context.Context, uri string) (*result, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
-		return nil, fmt.Errorf("accrualClient.GetOrder: %w", err)
+		return &result{}, err
 	}
-	var accrualResponse gophermart.AccrualResponse
-	if err := json.Unmarshal(raw, &accrualResponse); err != nil {
-		return nil, fmt.Errorf("accrualClient.GetOrder: %w", err)
+	resp, err := c.http.Do(request)
+	if err != nil {
+		return &result{}, err
 	}
-	if status, ok := mapStatus[accrualResponse.Status]; ok {
-		accrualResponse.Status = status
-		logger.Log.Debugf("raw string: %s", string(raw))
-		logger.Log.Debugf("accrualRespnse: %s", accrualResponse)
-		return &accrualResponse, nil
-	} else {
-		return nil, fmt.Errorf("accrualClient.GetOrder: status not found: %s", accrualResponse.Status)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseLength))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return &result{}, err
 	}
+	return &result{status: resp.StatusCode, header: resp.Header, body: body}, nil
 }
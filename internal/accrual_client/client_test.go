@@ -0,0 +1,165 @@
+package accrualclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/accrualmock"
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+func newTestClient(url string, retries int, b *breaker.Breaker) *Client {
+	return New(url, Options{
+		Timeout:      time.Second,
+		Retries:      retries,
+		RetryBackoff: time.Millisecond,
+		Breaker:      b,
+	})
+}
+
+func TestClient_StatusMapping(t *testing.T) {
+	_, srv := accrualmock.NewTestServer(accrualmock.Config{Orders: map[string][]accrualmock.Response{
+		"1": {{Status: accrualmock.StatusRegistered}},
+		"2": {{Status: accrualmock.StatusInvalid}},
+		"3": {{Status: accrualmock.StatusProcessing}},
+		"4": {{Status: accrualmock.StatusProcessed, Accrual: 500}},
+	}})
+	defer srv.Close()
+	client := newTestClient(srv.URL, 0, nil)
+
+	tests := []struct {
+		number string
+		want   gophermart.AccrualResponse
+	}{
+		{"1", gophermart.AccrualResponse{OrderNumber: "1", Status: "NEW"}},
+		{"2", gophermart.AccrualResponse{OrderNumber: "2", Status: "INVALID"}},
+		{"3", gophermart.AccrualResponse{OrderNumber: "3", Status: "PROCESSING"}},
+		{"4", gophermart.AccrualResponse{OrderNumber: "4", Status: "PROCESSED", Accrual: 500}},
+		// Незарегистрированный заказ: 204.
+		{"5", gophermart.AccrualResponse{OrderNumber: "5", Status: "NEW", Unregistered: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			got, err := client.GetOrder(context.Background(), tt.number)
+			if err != nil {
+				t.Fatalf("GetOrder: %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_UnknownStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"order":"1","status":"LOST"}`))
+	}))
+	defer srv.Close()
+
+	if _, err := newTestClient(srv.URL, 0, nil).GetOrder(context.Background(), "1"); err == nil {
+		t.Fatal("expected error for unknown status")
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	mock, srv := accrualmock.NewTestServer(accrualmock.Config{})
+	defer srv.Close()
+	client := newTestClient(srv.URL, 2, nil)
+
+	t.Run("5xx is retried", func(t *testing.T) {
+		mock.Set("1",
+			accrualmock.Response{Code: http.StatusInternalServerError},
+			accrualmock.Response{Code: http.StatusBadGateway},
+			accrualmock.Response{Status: accrualmock.StatusProcessing},
+		)
+		got, err := client.GetOrder(context.Background(), "1")
+		if err != nil {
+			t.Fatalf("GetOrder: %v", err)
+		}
+		if got.Status != "PROCESSING" || mock.Calls("1") != 3 {
+			t.Fatalf("status %s after %d calls", got.Status, mock.Calls("1"))
+		}
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		mock.Set("2", accrualmock.Response{Code: http.StatusServiceUnavailable})
+		_, err := client.GetOrder(context.Background(), "2")
+		if !errors.Is(err, gophermart.ErrAccrualUnavailable) {
+			t.Fatalf("err = %v, want ErrAccrualUnavailable", err)
+		}
+		if n := mock.Calls("2"); n != 3 {
+			t.Fatalf("calls = %d, want 3", n)
+		}
+	})
+
+	t.Run("429 is not retried", func(t *testing.T) {
+		mock.Set("3", accrualmock.Response{Code: http.StatusTooManyRequests, RetryAfter: time.Second})
+		_, err := client.GetOrder(context.Background(), "3")
+		if !errors.Is(err, gophermart.ErrToManyRequests) {
+			t.Fatalf("err = %v, want ErrToManyRequests", err)
+		}
+		if n := mock.Calls("3"); n != 1 {
+			t.Fatalf("calls = %d, want 1", n)
+		}
+	})
+}
+
+func TestClient_NetworkErrorRetried(t *testing.T) {
+	var calls atomic.Int32
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("connection reset")
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	_, srv := accrualmock.NewTestServer(accrualmock.Config{})
+	defer srv.Close()
+
+	client := New(srv.URL, Options{
+		HTTPClient:   &http.Client{Transport: transport},
+		Retries:      1,
+		RetryBackoff: time.Millisecond,
+	})
+	if _, err := client.GetOrder(context.Background(), "1"); err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestClient_BreakerOpens(t *testing.T) {
+	mock, srv := accrualmock.NewTestServer(accrualmock.Config{
+		Default: &accrualmock.Response{Code: http.StatusInternalServerError},
+	})
+	defer srv.Close()
+	b := breaker.New(breaker.Options{Threshold: 2, OpenTimeout: time.Hour})
+	client := newTestClient(srv.URL, 0, b)
+
+	for range 2 {
+		_, _ = client.GetOrder(context.Background(), "1")
+	}
+	if b.State() != breaker.Open {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+
+	mock.SetDefault(accrualmock.Response{Status: accrualmock.StatusProcessed, Accrual: 1})
+	_, err := client.GetOrder(context.Background(), "1")
+	if !errors.Is(err, breaker.ErrOpen) || !errors.Is(err, gophermart.ErrAccrualUnavailable) {
+		t.Fatalf("err = %v, want open breaker", err)
+	}
+	if client.Breaker() != b {
+		t.Fatal("Breaker must return the injected breaker")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
@@ -0,0 +1,341 @@
+// Package accrualmock — управляемая заглушка системы расчёта начислений.
+// Реализует GET /api/orders/{number}; ответ для каждого номера задаётся
+// сценарием из файла или через управляющее API /mock/*.
+package accrualmock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StatusRegistered = "REGISTERED"
+	StatusInvalid    = "INVALID"
+	StatusProcessing = "PROCESSING"
+	StatusProcessed  = "PROCESSED"
+)
+
+var ErrInvalidResponse = errors.New("invalid mock response")
+
+// Response — один ответ заглушки. Code 0 или 200 означает JSON с Status и Accrual;
+// 204, 429, 500 и любые другие коды отдаются без тела.
+type Response struct {
+	Code    int     `yaml:"code" json:"code,omitempty"`
+	Status  string  `yaml:"status" json:"status,omitempty"`
+	Accrual float64 `yaml:"accrual" json:"accrual,omitempty"`
+	// RetryAfter отдаётся в заголовке Retry-After при 429.
+	RetryAfter time.Duration `yaml:"retry_after" json:"retry_after,omitempty"`
+	// Delay задерживает ответ, чтобы проверить таймауты клиента.
+	Delay time.Duration `yaml:"delay" json:"delay,omitempty"`
+}
+
+func (r Response) code() int {
+	if r.Code == 0 {
+		return http.StatusOK
+	}
+	return r.Code
+}
+
+func (r Response) Validate() error {
+	if r.code() < 100 || r.code() > 599 {
+		return fmt.Errorf("%w: code %d", ErrInvalidResponse, r.Code)
+	}
+	if r.Accrual < 0 || r.Delay < 0 || r.RetryAfter < 0 {
+		return fmt.Errorf("%w: negative value", ErrInvalidResponse)
+	}
+	if r.code() != http.StatusOK {
+		return nil
+	}
+	switch r.Status {
+	case StatusRegistered, StatusInvalid, StatusProcessing:
+		if r.Accrual != 0 {
+			return fmt.Errorf("%w: accrual is only allowed for %s", ErrInvalidResponse, StatusProcessed)
+		}
+	case StatusProcessed:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidResponse, r.Status)
+	}
+	return nil
+}
+
+// Config — сценарии заглушки. Для номера отдаются ответы Orders[number] по очереди,
+// последний повторяется. Номера без сценария получают Default.
+type Config struct {
+	Default *Response             `yaml:"default" json:"default,omitempty"`
+	Orders  map[string][]Response `yaml:"orders" json:"orders"`
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	if c.Default != nil {
+		if err := c.Default.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("default: %w", err))
+		}
+	}
+	for number, steps := range c.Orders {
+		if len(steps) == 0 {
+			errs = append(errs, fmt.Errorf("orders.%s: %w: no responses", number, ErrInvalidResponse))
+		}
+		for i, s := range steps {
+			if err := s.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("orders.%s[%d]: %w", number, i, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadConfig читает сценарии из yaml- или json-файла.
+func LoadConfig(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("accrualmock.LoadConfig: %w", err)
+	}
+	defer f.Close()
+
+	var cfg Config
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("accrualmock.LoadConfig: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("accrualmock.LoadConfig: %w", err)
+	}
+	return cfg, nil
+}
+
+type script struct {
+	steps []Response
+	calls int
+}
+
+// Server хранит сценарии и считает обращения к каждому номеру. Безопасен
+// для конкурентного использования, поэтому сценарии можно менять во время теста.
+type Server struct {
+	mu       sync.Mutex
+	fallback Response
+	orders   map[string]*script
+}
+
+// New создаёт заглушку. Без Default неизвестные номера получают 204,
+// как незарегистрированные заказы в настоящем сервисе.
+func New(cfg Config) *Server {
+	s := &Server{}
+	s.load(cfg)
+	return s
+}
+
+// NewTestServer поднимает заглушку на httptest.Server; адрес сервера
+// передаётся клиенту начислений как базовый URL. Сервер закрывает вызывающий.
+func NewTestServer(cfg Config) (*Server, *httptest.Server) {
+	s := New(cfg)
+	return s, httptest.NewServer(s.Handler())
+}
+
+func (s *Server) load(cfg Config) {
+	s.fallback = Response{Code: http.StatusNoContent}
+	if cfg.Default != nil {
+		s.fallback = *cfg.Default
+	}
+	s.orders = make(map[string]*script, len(cfg.Orders))
+	for number, steps := range cfg.Orders {
+		s.orders[number] = &script{steps: append([]Response(nil), steps...)}
+	}
+}
+
+// Set задаёт сценарий номера и сбрасывает его счётчик обращений.
+func (s *Server) Set(number string, steps ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(steps) == 0 {
+		delete(s.orders, number)
+		return
+	}
+	s.orders[number] = &script{steps: append([]Response(nil), steps...)}
+}
+
+func (s *Server) SetDefault(r Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = r
+}
+
+// Reset заменяет все сценарии на cfg.
+func (s *Server) Reset(cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load(cfg)
+}
+
+// Calls возвращает число обращений к номеру с момента задания сценария.
+func (s *Server) Calls(number string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sc, ok := s.orders[number]; ok {
+		return sc.calls
+	}
+	return 0
+}
+
+func (s *Server) next(number string) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.orders[number]
+	if !ok {
+		return s.fallback
+	}
+	r := sc.steps[min(sc.calls, len(sc.steps)-1)]
+	sc.calls++
+	return r
+}
+
+func (s *Server) snapshot() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fallback := s.fallback
+	cfg := Config{Default: &fallback, Orders: make(map[string][]Response, len(s.orders))}
+	for number, sc := range s.orders {
+		cfg.Orders[number] = append([]Response(nil), sc.steps...)
+	}
+	return cfg
+}
+
+// Handler возвращает роутер с API заглушки и управляющими маршрутами.
+func (s *Server) Handler() http.Handler {
+	router := chi.NewRouter()
+	router.Get("/api/orders/{number}", s.getOrder)
+
+	router.Route("/mock", func(r chi.Router) {
+		r.Get("/orders", s.listScripts)
+		r.Put("/orders/{number}", s.putScript)
+		r.Delete("/orders/{number}", s.deleteScript)
+		r.Put("/default", s.putDefault)
+		r.Post("/reset", s.reset)
+	})
+	return router
+}
+
+type orderResponse struct {
+	Order   string  `json:"order"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual,omitempty"`
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, r *http.Request) {
+	number := chi.URLParam(r, "number")
+	resp := s.next(number)
+
+	if resp.Delay > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(resp.Delay):
+		}
+	}
+
+	switch code := resp.code(); code {
+	case http.StatusOK:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(orderResponse{Order: number, Status: resp.Status, Accrual: resp.Accrual})
+	case http.StatusTooManyRequests:
+		retry := resp.RetryAfter
+		if retry <= 0 {
+			retry = time.Minute
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds())))
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte("No more than N requests per minute allowed"))
+	default:
+		w.WriteHeader(code)
+	}
+}
+
+func (s *Server) listScripts(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.snapshot())
+}
+
+// decodeBody читает тело как yaml: так принимаются и JSON, и длительности вида "2s".
+func decodeBody(r *http.Request, v any) error {
+	dec := yaml.NewDecoder(r.Body)
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
+
+// putScript принимает один ответ или массив ответов.
+func (s *Server) putScript(w http.ResponseWriter, r *http.Request) {
+	var node yaml.Node
+	if err := decodeBody(r, &node); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var steps []Response
+	if len(node.Content) == 1 && node.Content[0].Kind == yaml.SequenceNode {
+		if err := node.Decode(&steps); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		var one Response
+		if err := node.Decode(&one); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		steps = []Response{one}
+	}
+	number := chi.URLParam(r, "number")
+	cfg := Config{Orders: map[string][]Response{number: steps}}
+	if err := cfg.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.Set(number, steps...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteScript(w http.ResponseWriter, r *http.Request) {
+	s.Set(chi.URLParam(r, "number"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) putDefault(w http.ResponseWriter, r *http.Request) {
+	var resp Response
+	if err := decodeBody(r, &resp); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := resp.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.SetDefault(resp)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reset без тела очищает сценарии, с телом — заменяет их конфигурацией.
+func (s *Server) reset(w http.ResponseWriter, r *http.Request) {
+	var cfg Config
+	if err := decodeBody(r, &cfg); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.Reset(cfg)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,144 @@
+package accrualmock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func do(t *testing.T, method string, url string, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServer_Scenario(t *testing.T) {
+	mock, srv := NewTestServer(Config{Orders: map[string][]Response{
+		"1": {{Status: StatusRegistered}, {Status: StatusProcessed, Accrual: 729.98}},
+		"2": {{Code: http.StatusTooManyRequests, RetryAfter: 7 * time.Second}},
+		"3": {{Code: http.StatusInternalServerError}},
+	}})
+	defer srv.Close()
+
+	resp, body := get(t, srv.URL+"/api/orders/1")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"status":"REGISTERED"`) {
+		t.Fatalf("first call: %d %s", resp.StatusCode, body)
+	}
+	for range 2 {
+		_, body = get(t, srv.URL+"/api/orders/1")
+		var got orderResponse
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != (orderResponse{Order: "1", Status: StatusProcessed, Accrual: 729.98}) {
+			t.Fatalf("last step must repeat, got %+v", got)
+		}
+	}
+	if n := mock.Calls("1"); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+
+	resp, _ = get(t, srv.URL+"/api/orders/2")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "7" {
+		t.Errorf("429: %d Retry-After=%q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	if resp, _ = get(t, srv.URL+"/api/orders/3"); resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("500 scenario: got %d", resp.StatusCode)
+	}
+	if resp, _ = get(t, srv.URL+"/api/orders/404"); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unknown order: got %d, want 204", resp.StatusCode)
+	}
+}
+
+func TestServer_Delay(t *testing.T) {
+	_, srv := NewTestServer(Config{Orders: map[string][]Response{
+		"1": {{Status: StatusProcessing, Delay: 200 * time.Millisecond}},
+	}})
+	defer srv.Close()
+
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	if _, err := client.Get(srv.URL + "/api/orders/1"); err == nil {
+		t.Fatal("expected client timeout on slow response")
+	}
+}
+
+func TestServer_ControlAPI(t *testing.T) {
+	mock, srv := NewTestServer(Config{})
+	defer srv.Close()
+
+	if code := do(t, http.MethodPut, srv.URL+"/mock/orders/5", `{"code": 429, "retry_after": "3s"}`); code != http.StatusNoContent {
+		t.Fatalf("put single: %d", code)
+	}
+	if resp, _ := get(t, srv.URL+"/api/orders/5"); resp.Header.Get("Retry-After") != "3" {
+		t.Errorf("Retry-After = %q", resp.Header.Get("Retry-After"))
+	}
+
+	if code := do(t, http.MethodPut, srv.URL+"/mock/orders/5", `[{"status":"PROCESSING"},{"status":"PROCESSED","accrual":10}]`); code != http.StatusNoContent {
+		t.Fatalf("put list: %d", code)
+	}
+	if n := mock.Calls("5"); n != 0 {
+		t.Errorf("calls must reset on Set, got %d", n)
+	}
+	if _, body := get(t, srv.URL+"/api/orders/5"); !strings.Contains(body, StatusProcessing) {
+		t.Errorf("body = %s", body)
+	}
+
+	if code := do(t, http.MethodPut, srv.URL+"/mock/orders/6", `{"status":"DONE"}`); code != http.StatusBadRequest {
+		t.Errorf("invalid status: got %d", code)
+	}
+	if code := do(t, http.MethodPut, srv.URL+"/mock/default", `{"status":"PROCESSED","accrual":1}`); code != http.StatusNoContent {
+		t.Fatalf("put default: %d", code)
+	}
+	if _, body := get(t, srv.URL+"/api/orders/777"); !strings.Contains(body, StatusProcessed) {
+		t.Errorf("default not applied: %s", body)
+	}
+
+	if code := do(t, http.MethodPost, srv.URL+"/mock/reset", ""); code != http.StatusNoContent {
+		t.Fatalf("reset: %d", code)
+	}
+	if resp, _ := get(t, srv.URL+"/api/orders/5"); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("after reset: got %d", resp.StatusCode)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("..", "..", "cmd", "accrual-mock", "scenarios.example.yaml"))
+	if err != nil {
+		t.Fatalf("example scenarios: %v", err)
+	}
+	if len(cfg.Orders["12345678903"]) != 3 {
+		t.Errorf("orders = %+v", cfg.Orders)
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(path, []byte("orders:\n  \"1\":\n    - status: PROCESSING\n      accrual: 5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "orders.1[0]") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
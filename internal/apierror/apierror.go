@@ -0,0 +1,96 @@
+// Package apierror сопоставляет ошибки сервиса с кодами, которые видят клиенты.
+// Таблица не зависит от транспорта: HTTP API строит из неё problem+json,
+// gRPC API — status с ErrorInfo.
+package apierror
+
+import (
+	"errors"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+// Машиночитаемые коды ошибок. Значения являются частью публичного API и не должны меняться.
+const (
+	CodeBadRequest              = "bad_request"
+	CodeInvalidContentType      = "invalid_content_type"
+	CodeInvalidBody             = "invalid_body"
+	CodeSchemaViolation         = "schema_violation"
+	CodeUnsupportedEncoding     = "unsupported_encoding"
+	CodeBodyTooLarge            = "body_too_large"
+	CodeEmptyCredentials        = "empty_credentials"
+	CodeUserAlreadyExists       = "user_already_exists"
+	CodeInvalidCredentials      = "invalid_credentials"
+	CodeUnauthorized            = "unauthorized"
+	CodeInvalidToken            = "invalid_token"
+	CodeInvalidCSRFToken        = "invalid_csrf_token"
+	CodeInvalidOrderNumber      = "invalid_order_number"
+	CodeOrderOwnedByAnotherUser = "order_owned_by_another_user"
+	CodeInsufficientFunds       = "insufficient_funds"
+	CodeInvalidSum              = "invalid_sum"
+	CodeInvalidSignature        = "invalid_signature"
+	CodeOrderNotFound           = "order_not_found"
+	CodeInvalidAccrualStatus    = "invalid_accrual_status"
+	CodeInvalidWebhookURL       = "invalid_webhook_url"
+	CodeInvalidWebhookSecret    = "invalid_webhook_secret"
+	CodeWebhookAlreadyExists    = "webhook_already_exists"
+	CodeWebhookNotFound         = "webhook_not_found"
+	CodeInternal                = "internal_error"
+)
+
+// Kind — класс ошибки; каждый транспорт переводит его в свой статус.
+type Kind int
+
+const (
+	Internal Kind = iota
+	InvalidArgument
+	Unauthenticated
+	InsufficientFunds
+	NotFound
+	Conflict
+	Unprocessable
+)
+
+// Error — ошибка в том виде, в каком её получает клиент.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+type mapping struct {
+	err  error
+	kind Kind
+	code string
+}
+
+// mappings — единственное место, где ошибки сервиса превращаются в код ответа.
+var mappings = []mapping{
+	{err: gophermart.ErrEmptyField, kind: InvalidArgument, code: CodeEmptyCredentials},
+	{err: gophermart.ErrUserAlreadyExists, kind: Conflict, code: CodeUserAlreadyExists},
+	{err: gophermart.ErrUserNotFound, kind: Unauthenticated, code: CodeInvalidCredentials},
+	{err: gophermart.ErrInvalidPassword, kind: Unauthenticated, code: CodeInvalidCredentials},
+	{err: gophermart.ErrNoToken, kind: Unauthenticated, code: CodeUnauthorized},
+	{err: gophermart.ErrInvalidToken, kind: Unauthenticated, code: CodeInvalidToken},
+	{err: gophermart.ErrInvalidOrderNumber, kind: Unprocessable, code: CodeInvalidOrderNumber},
+	{err: gophermart.ErrOrderOwnedByAnotherUser, kind: Conflict, code: CodeOrderOwnedByAnotherUser},
+	{err: gophermart.ErrWithdrawAlreadyProcessed, kind: Unprocessable, code: CodeInvalidOrderNumber},
+	{err: gophermart.ErrNotEnoughBalance, kind: InsufficientFunds, code: CodeInsufficientFunds},
+	{err: gophermart.ErrInvalidSum, kind: Unprocessable, code: CodeInvalidSum},
+	{err: gophermart.ErrOrderNotFound, kind: NotFound, code: CodeOrderNotFound},
+	{err: gophermart.ErrInvalidAccrualStatus, kind: Unprocessable, code: CodeInvalidAccrualStatus},
+	{err: gophermart.ErrInvalidWebhookURL, kind: Unprocessable, code: CodeInvalidWebhookURL},
+	{err: gophermart.ErrInvalidWebhookSecret, kind: Unprocessable, code: CodeInvalidWebhookSecret},
+	{err: gophermart.ErrWebhookAlreadyExists, kind: Conflict, code: CodeWebhookAlreadyExists},
+	{err: gophermart.ErrWebhookNotFound, kind: NotFound, code: CodeWebhookNotFound},
+}
+
+// FromError возвращает описание ошибки для клиента.
+// Неизвестные ошибки становятся Internal без раскрытия внутренних деталей.
+func FromError(err error) Error {
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return Error{Kind: m.kind, Code: m.code, Message: m.err.Error()}
+		}
+	}
+	return Error{Kind: Internal, Code: CodeInternal, Message: "internal server error"}
+}
@@ -0,0 +1,35 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind Kind
+		wantCode string
+	}{
+		{name: "no token", err: gophermart.ErrNoToken, wantKind: Unauthenticated, wantCode: CodeUnauthorized},
+		{name: "not enough balance", err: gophermart.ErrNotEnoughBalance, wantKind: InsufficientFunds, wantCode: CodeInsufficientFunds},
+		{name: "wrapped sentinel", err: fmt.Errorf("service.AddOrder: %w", gophermart.ErrOrderOwnedByAnotherUser), wantKind: Conflict, wantCode: CodeOrderOwnedByAnotherUser},
+		{name: "unknown error", err: errors.New("db down"), wantKind: Internal, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := FromError(tt.err)
+			if e.Kind != tt.wantKind || e.Code != tt.wantCode {
+				t.Fatalf("FromError = %+v, want kind %d code %q", e, tt.wantKind, tt.wantCode)
+			}
+			if tt.wantKind == Internal && e.Message != "internal server error" {
+				t.Fatalf("internal error leaks details: %q", e.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package breaker — простой circuit breaker для вызовов внешних сервисов.
+//
+// После Threshold неудач подряд breaker открывается и отклоняет вызовы.
+// Через OpenTimeout он переходит в half-open и пропускает один пробный вызов:
+// успех закрывает breaker, неудача снова открывает его.
+package breaker
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/metrics"
+)
+
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+const (
+	defaultThreshold   = 5
+	defaultOpenTimeout = 30 * time.Second
+)
+
+type Options struct {
+	// Threshold — число неудач подряд, после которого breaker открывается.
+	Threshold int
+	// OpenTimeout — сколько breaker остаётся открытым до пробного вызова.
+	OpenTimeout time.Duration
+}
+
+type Breaker struct {
+	mu        sync.Mutex
+	opts      Options
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	listeners []func(from State, to State)
+
+	now func() time.Time
+}
+
+func New(opts Options) *Breaker {
+	if opts.Threshold <= 0 {
+		opts.Threshold = defaultThreshold
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = defaultOpenTimeout
+	}
+	return &Breaker{opts: opts, now: time.Now}
+}
+
+// Subscribe добавляет обработчик смены состояния. Обработчики вызываются
+// вне блокировки, синхронно в горутине, которая сменила состояние.
+func (b *Breaker) Subscribe(f func(from State, to State)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, f)
+}
+
+// Allow разрешает вызов или возвращает ErrOpen. В half-open разрешается
+// только один вызов, пока не будет сообщён его результат.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	var changed func()
+	defer func() {
+		b.mu.Unlock()
+		if changed != nil {
+			changed()
+		}
+	}()
+
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.opts.OpenTimeout {
+			return ErrOpen
+		}
+		changed = b.setState(HalfOpen)
+		b.probing = true
+		return nil
+	case HalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success сообщает об успешном вызове.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	b.failures = 0
+	b.probing = false
+	changed := b.setState(Closed)
+	b.mu.Unlock()
+	if changed != nil {
+		changed()
+	}
+}
+
+// Failure сообщает о неудачном вызове.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	var changed func()
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.opts.Threshold {
+		b.probing = false
+		b.openedAt = b.now()
+		changed = b.setState(Open)
+	}
+	b.mu.Unlock()
+	if changed != nil {
+		changed()
+	}
+}
+
+// Release снимает блокировку пробного вызова, результат которого неизвестен
+// (например, вызов отменён вызывающим). Состояние не меняется.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State возвращает текущее состояние. Открытый breaker, у которого истёк
+// OpenTimeout, считается half-open: следующий вызов станет пробным.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.opts.OpenTimeout {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// setState меняет состояние под блокировкой и возвращает уведомление
+// для вызова после её снятия, либо nil, если состояние не изменилось.
+func (b *Breaker) setState(to State) func() {
+	from := b.state
+	if from == to {
+		return nil
+	}
+	b.state = to
+	listeners := slices.Clone(b.listeners)
+	return func() {
+		for _, f := range listeners {
+			f(from, to)
+		}
+	}
+}
+
+// Collector отдаёт состояние breaker в метрики: circuit_breaker_state равен 1
+// для текущего состояния, circuit_breaker_transitions_total считает переходы.
+func Collector(b *Breaker, name string) metrics.Collector {
+	transitions := metrics.NewCounter()
+	b.Subscribe(func(_ State, to State) { transitions.Inc(to.String()) })
+
+	states := []State{Closed, HalfOpen, Open}
+	return func(w *metrics.Writer) {
+		current := b.State()
+		for _, s := range states {
+			value := 0.0
+			if s == current {
+				value = 1
+			}
+			w.Gauge("circuit_breaker_state", "Current circuit breaker state.", value,
+				metrics.Label{Name: "name", Value: name}, metrics.Label{Name: "state", Value: s.String()})
+		}
+		snapshot := transitions.Snapshot()
+		for _, s := range states {
+			w.Counter("circuit_breaker_transitions_total", "Circuit breaker transitions by target state.", snapshot[s.String()],
+				metrics.Label{Name: "name", Value: name}, metrics.Label{Name: "to", Value: s.String()})
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package breaker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/metrics"
+)
+
+func newTestBreaker(threshold int) (*Breaker, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(Options{Threshold: threshold, OpenTimeout: time.Minute})
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_Transitions(t *testing.T) {
+	b, now := newTestBreaker(3)
+	var changes []string
+	b.Subscribe(func(from, to State) { changes = append(changes, from.String()+"->"+to.String()) })
+
+	for range 2 {
+		b.Failure()
+	}
+	if b.State() != Closed {
+		t.Fatalf("state = %s before threshold", b.State())
+	}
+	b.Success()
+	for range 3 {
+		b.Failure()
+	}
+	if b.State() != Open {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow on open breaker: %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if b.State() != HalfOpen {
+		t.Fatalf("state = %s after timeout, want half-open", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe must be allowed: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatal("only one probe is allowed in half-open")
+	}
+	b.Failure()
+	if b.State() != Open {
+		t.Fatalf("failed probe must reopen, got %s", b.State())
+	}
+
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Success()
+	if b.State() != Closed {
+		t.Fatalf("successful probe must close, got %s", b.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("changes = %v, want %v", changes, want)
+		}
+	}
+}
+
+func TestBreaker_Release(t *testing.T) {
+	b, now := newTestBreaker(1)
+	b.Failure()
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Release()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("released probe must allow the next one: %v", err)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	b, _ := newTestBreaker(1)
+	reg := metrics.NewRegistry()
+	reg.Register(Collector(b, "accrual"))
+	b.Failure()
+
+	var out strings.Builder
+	if _, err := reg.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`circuit_breaker_state{name="accrual",state="open"} 1`,
+		`circuit_breaker_state{name="accrual",state="closed"} 0`,
+		`circuit_breaker_transitions_total{name="accrual",to="open"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in\n%s", want, out.String())
+		}
+	}
+}
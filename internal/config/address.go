@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NormalizeAddress приводит адрес запуска к виду host:port.
+// Принимает как host:port (":8080", "localhost:8080"), так и URL ("http://localhost:8080/").
+func NormalizeAddress(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return "", fmt.Errorf("config.NormalizeAddress: empty address")
+	}
+
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return "", fmt.Errorf("config.NormalizeAddress: %w", err)
+		}
+		if u.Path != "" && u.Path != "/" {
+			return "", fmt.Errorf("config.NormalizeAddress: path is not allowed in %q", addr)
+		}
+		port := u.Port()
+		if port == "" {
+			switch u.Scheme {
+			case "http":
+				port = "80"
+			case "https":
+				port = "443"
+			default:
+				return "", fmt.Errorf("config.NormalizeAddress: unsupported scheme %q", u.Scheme)
+			}
+		}
+		return net.JoinHostPort(u.Hostname(), port), nil
+	}
+
+	host, port, err := net.SplitHostPort(strings.TrimSuffix(addr, "/"))
+	if err != nil {
+		return "", fmt.Errorf("config.NormalizeAddress: %w", err)
+	}
+	if port == "" {
+		return "", fmt.Errorf("config.NormalizeAddress: empty port in %q", addr)
+	}
+	return net.JoinHostPort(host, port), nil
+}
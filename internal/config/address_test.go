@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{name: "default url", addr: "http://localhost:8080/", want: "localhost:8080"},
+		{name: "https url without port", addr: "https://example.com", want: "example.com:443"},
+		{name: "http url without port", addr: "http://example.com/", want: "example.com:80"},
+		{name: "host and port", addr: "localhost:8080", want: "localhost:8080"},
+		{name: "port only", addr: ":8080", want: ":8080"},
+		{name: "ipv6", addr: "http://[::1]:8080", want: "[::1]:8080"},
+		{name: "empty", addr: "", wantErr: true},
+		{name: "url with path", addr: "http://localhost:8080/api", wantErr: true},
+		{name: "unknown scheme", addr: "ftp://localhost", wantErr: true},
+		{name: "missing port", addr: "localhost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAddress(tt.addr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -1,80 +1,360 @@
 package config
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"net/url"
 	"os"
-)
+	"strings"
+	"time"
 
-const (
-	RunADDRESSKEY         = "RUN_ADDRESS"
-	DSNKEY                = "DATABASE_URI"
-	ACCRUALSYSTEMADDRESS = "ACCRUAL_SYSTEM_ADDRESS"
+	"gopkg.in/yaml.v3"
 )
 
+const redacted = "[REDACTED]"
+
+// Config — итоговая конфигурация приложения. Источники применяются по возрастанию приоритета:
+// значения по умолчанию → файл (-c / CONFIG) → флаги → переменные окружения.
+type Config struct {
+	Logger   Logger   `yaml:"logging" json:"logging"`
+	Server   Server   `yaml:"server" json:"server"`
+	Database Database `yaml:"database" json:"database"`
+	Worker   Worker   `yaml:"worker" json:"worker"`
+	Accrual  Accrual  `yaml:"accrual" json:"accrual"`
+	Outbox   Outbox   `yaml:"outbox" json:"outbox"`
+	Webhooks Webhooks `yaml:"webhooks" json:"webhooks"`
+	Auth     Auth     `yaml:"auth" json:"auth"`
+}
+
 type Logger struct {
-	Level  string
-	Format string
+	Level  string `yaml:"level" json:"level"`
+	Format string `yaml:"format" json:"format"`
 }
 
-func (l *Logger) String() string {
-	return "Level: " + l.Level + "\n" +
-		"Format: " + l.Format + "\n"
+type Server struct {
+	RunAddress       string `yaml:"run_address" json:"run_address"`
+	ValidateRequests bool   `yaml:"validate_requests" json:"validate_requests"`
+	TLS              TLS    `yaml:"tls" json:"tls"`
+	Admin            Admin  `yaml:"admin" json:"admin"`
+	GRPC             GRPC   `yaml:"grpc" json:"grpc"`
+	CORS             CORS   `yaml:"cors" json:"cors"`
+	Cookie           Cookie `yaml:"cookie" json:"cookie"`
+	CSRFEnabled      bool   `yaml:"csrf_enabled" json:"csrf_enabled"`
 }
 
-type Config struct {
-	Logger
-	RunAddress            string
-	Dsn                   string
-	Secret                string
-	AccrualServiceAddress string
+// Database — параметры пула соединений. Нулевые значения оставляют умолчания pgxpool.
+type Database struct {
+	DSN string `yaml:"dsn" json:"dsn"`
+	// MigrateOnStart применяет миграции при запуске serve. Выключается, если у
+	// пользователя приложения нет прав на DDL: тогда схему обновляет migrate up.
+	MigrateOnStart    bool          `yaml:"migrate_on_start" json:"migrate_on_start"`
+	MaxConns          int32         `yaml:"max_conns" json:"max_conns"`
+	MinConns          int32         `yaml:"min_conns" json:"min_conns"`
+	MinIdleConns      int32         `yaml:"min_idle_conns" json:"min_idle_conns"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" json:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" json:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" json:"health_check_period"`
+	// StatementTimeout ограничивает каждый запрос на стороне Postgres (statement_timeout).
+	StatementTimeout time.Duration `yaml:"statement_timeout" json:"statement_timeout"`
+	ConnectTimeout   time.Duration `yaml:"connect_timeout" json:"connect_timeout"`
+	ConnectRetries   int32         `yaml:"connect_retries" json:"connect_retries"`
+	ConnectBackoff   time.Duration `yaml:"connect_backoff" json:"connect_backoff"`
+	// SaturationCheck — период проверки заполненности пула для предупреждений в лог.
+	SaturationCheck time.Duration `yaml:"saturation_check" json:"saturation_check"`
+	Replica         Replica       `yaml:"replica" json:"replica"`
 }
 
-func (c *Config) String() string {
-	return "Logger: " + c.Logger.String() + "\n" +
-		"RunAddress: " + c.RunAddress + "\n"
+// Replica — реплика для чтения списков и баланса. Пустой DSN отключает маршрутизацию.
+type Replica struct {
+	DSN string `yaml:"dsn" json:"dsn"`
+	// MaxLag — отставание, после которого чтение уходит на primary.
+	MaxLag        time.Duration `yaml:"max_lag" json:"max_lag"`
+	CheckInterval time.Duration `yaml:"check_interval" json:"check_interval"`
+	// PinWindow — сколько после записи пользователя его чтения идут на primary.
+	// Закрепление хранится в памяти процесса: при нескольких экземплярах за
+	// балансировщиком без sticky-сессий чтение на другом экземпляре может не
+	// увидеть только что сделанную запись.
+	PinWindow time.Duration `yaml:"pin_window" json:"pin_window"`
 }
 
-func InitConfig() *Config {
-	const (
-		runAddressFlagUsage = `Base URL, e.g. "http://localhost:8080/"`
-		DsnFlagUsage        = `Dsn address`
-	)
-	cfg := Config{}
+// Worker — параметры фонового опроса системы начислений.
+type Worker struct {
+	PollInterval   time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	BatchSize      int32         `yaml:"batch_size" json:"batch_size"`
+	SyncInterval   time.Duration `yaml:"sync_interval" json:"sync_interval"`
+	RateLimitPause time.Duration `yaml:"rate_limit_pause" json:"rate_limit_pause"`
+	// MaxOrderAge — возраст, после которого заказ, неизвестный системе начислений,
+	// помечается INVALID. 0 — ждать бесконечно.
+	MaxOrderAge time.Duration `yaml:"max_order_age" json:"max_order_age"`
+	// FallbackSyncInterval заменяет SyncInterval, когда система начислений присылает
+	// статусы на callback: опрос остаётся страховкой для заказов без уведомлений.
+	FallbackSyncInterval time.Duration `yaml:"fallback_sync_interval" json:"fallback_sync_interval"`
+}
+
+// Accrual — клиент системы начислений. Timeout ограничивает одну попытку;
+// повторы выполняются только на 5xx и сетевых ошибках.
+type Accrual struct {
+	Address      string        `yaml:"address" json:"address"`
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"`
+	Retries      int           `yaml:"retries" json:"retries"`
+	RetryBackoff time.Duration `yaml:"retry_backoff" json:"retry_backoff"`
+	// BreakerThreshold — неудач подряд до размыкания, BreakerOpenTimeout — пауза до пробного запроса.
+	BreakerThreshold   int           `yaml:"breaker_threshold" json:"breaker_threshold"`
+	BreakerOpenTimeout time.Duration `yaml:"breaker_open_timeout" json:"breaker_open_timeout"`
+	MaxIdleConns       int           `yaml:"max_idle_conns" json:"max_idle_conns"`
+	// CallbackSecret — общий секрет HMAC-подписи уведомлений на /internal/accrual/callback.
+	// Пустой секрет отключает приём уведомлений.
+	CallbackSecret string `yaml:"callback_secret" json:"callback_secret"`
+}
+
+// Outbox — доставка доменных событий. Пустой Sink отключает relay: события
+// остаются в таблице outbox до его включения.
+type Outbox struct {
+	// Sink — stdout, file или webhook.
+	Sink          string        `yaml:"sink" json:"sink"`
+	File          string        `yaml:"file" json:"file"`
+	WebhookURL    string        `yaml:"webhook_url" json:"webhook_url"`
+	WebhookSecret string        `yaml:"webhook_secret" json:"webhook_secret"`
+	PollInterval  time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	BatchSize     int32         `yaml:"batch_size" json:"batch_size"`
+	RetryBackoff  time.Duration `yaml:"retry_backoff" json:"retry_backoff"`
+	MaxBackoff    time.Duration `yaml:"max_backoff" json:"max_backoff"`
+	// Lease — на сколько relay забирает пачку; должна покрывать её публикацию.
+	Lease time.Duration `yaml:"lease" json:"lease"`
+	// Retention — сколько хранить доставленные события; 0 — не удалять.
+	Retention time.Duration `yaml:"retention" json:"retention"`
+}
+
+// Webhooks — доставка событий на вебхуки пользователей. Выключенная доставка
+// не отключает API регистрации: события просто не ставятся в очередь.
+type Webhooks struct {
+	Enabled      bool          `yaml:"enabled" json:"enabled"`
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	BatchSize    int32         `yaml:"batch_size" json:"batch_size"`
+	Concurrency  int           `yaml:"concurrency" json:"concurrency"`
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"`
+	RetryBackoff time.Duration `yaml:"retry_backoff" json:"retry_backoff"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" json:"max_backoff"`
+	MaxAttempts  int32         `yaml:"max_attempts" json:"max_attempts"`
+	// AllowPrivateTargets разрешает вебхуки на loopback и частные сети; только для разработки.
+	AllowPrivateTargets bool `yaml:"allow_private_targets" json:"allow_private_targets"`
+}
+
+// Auth — параметры подписи JWT. Пустой секрет допустим только в DevMode.
+type Auth struct {
+	Secret  string `yaml:"secret" json:"secret"`
+	DevMode bool   `yaml:"dev_mode" json:"dev_mode"`
+}
+
+func Default() *Config {
+	return &Config{
+		Logger: Logger{
+			Level:  "debug",
+			Format: "text",
+		},
+		Server: Server{
+			RunAddress: "http://localhost:8080/",
+			TLS:        defaultTLS(),
+			Admin:      Admin{TLS: defaultTLS()},
+			GRPC:       GRPC{TLS: defaultTLS()},
+			CORS:       defaultCORS(),
+			Cookie:     defaultCookie(),
+		},
+		Database: Database{
+			MigrateOnStart:   true,
+			StatementTimeout: 5 * time.Second,
+			ConnectTimeout:   2 * time.Second,
+			ConnectRetries:   10,
+			ConnectBackoff:   500 * time.Millisecond,
+			SaturationCheck:  30 * time.Second,
+			Replica: Replica{
+				MaxLag:        5 * time.Second,
+				CheckInterval: 5 * time.Second,
+				PinWindow:     10 * time.Second,
+			},
+		},
+		Worker: Worker{
+			PollInterval:   10 * time.Second,
+			BatchSize:      10,
+			SyncInterval:   120 * time.Second,
+			RateLimitPause: 60 * time.Second,
+			MaxOrderAge:    24 * time.Hour,
+
+			FallbackSyncInterval: 30 * time.Minute,
+		},
+		Accrual: Accrual{
+			Address:            "http://localhost:8081/",
+			Timeout:            20 * time.Second,
+			Retries:            2,
+			RetryBackoff:       200 * time.Millisecond,
+			BreakerThreshold:   5,
+			BreakerOpenTimeout: 30 * time.Second,
+			MaxIdleConns:       10,
+		},
+		Outbox: Outbox{
+			PollInterval: time.Second,
+			BatchSize:    100,
+			RetryBackoff: time.Second,
+			MaxBackoff:   5 * time.Minute,
+			Lease:        time.Minute,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Webhooks: Webhooks{
+			Enabled:      true,
+			PollInterval: time.Second,
+			BatchSize:    50,
+			Concurrency:  10,
+			Timeout:      5 * time.Second,
+			RetryBackoff: 5 * time.Second,
+			MaxBackoff:   time.Hour,
+			MaxAttempts:  10,
+		},
+	}
+}
+
+// InitConfig собирает конфигурацию из аргументов командной строки и окружения процесса.
+func InitConfig() (*Config, error) {
+	return Load(os.Args[1:], os.LookupEnv)
+}
 
-	cfg.Logger.Level = "debug"
-	if level, ok := os.LookupEnv("LOG_LEVEL"); ok {
-		cfg.Logger.Level = level
+// Load собирает и валидирует конфигурацию. lookupEnv позволяет подменить окружение в тестах.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg, err := load(args, lookupEnv)
+	if err != nil {
+		return nil, err
 	}
-	cfg.Logger.Format = "text"
-	if format, ok := os.LookupEnv("LOG_FORMAT"); ok {
-		cfg.Logger.Format = format
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config.Load: %w", err)
 	}
+	return cfg, nil
+}
 
-	cfg.RunAddress = "http://localhost:8080/"
-	cfg.AccrualServiceAddress = "http://localhost:8081/"
+// LoadDatabase собирает конфигурацию так же, как Load, но проверяет только
+// параметры базы: командам migrate не нужны секрет и адреса сервера.
+func LoadDatabase(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg, err := load(args, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateDatabase(); err != nil {
+		return nil, fmt.Errorf("config.LoadDatabase: %w", err)
+	}
+	return cfg, nil
+}
 
-	flag.StringVar(&cfg.RunAddress, "a", cfg.RunAddress, runAddressFlagUsage)
-	flag.StringVar(&cfg.Dsn, "d", cfg.Dsn, DsnFlagUsage)
-	flag.StringVar(&cfg.AccrualServiceAddress, "r", cfg.AccrualServiceAddress, "Accrual service address")
+func load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
 
-	flag.Parse()
+	// Первый проход нужен только для того, чтобы узнать путь к файлу.
+	var path string
+	pre := newFlagSet(Default(), &path)
+	pre.SetOutput(io.Discard)
+	if err := pre.Parse(args); err != nil {
+		return nil, fmt.Errorf("config.Load: %w", err)
+	}
+	if path == "" {
+		path, _ = lookupEnv(CONFIGKEY)
+	}
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, fmt.Errorf("config.Load: %w", err)
+		}
+	}
+
+	fs := newFlagSet(cfg, &path)
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("config.Load: %w", err)
+	}
+	if err := loadEnv(cfg, lookupEnv); err != nil {
+		return nil, fmt.Errorf("config.Load: %w", err)
+	}
+	return cfg, nil
+}
+
+func newFlagSet(cfg *Config, path *string) *flag.FlagSet {
+	const (
+		runAddressFlagUsage = `Base URL, e.g. "http://localhost:8080/"`
+		DsnFlagUsage        = `Dsn address`
+	)
+	fs := flag.NewFlagSet("gophermart", flag.ContinueOnError)
+	fs.StringVar(path, "c", *path, "Config file (yaml or json)")
+	fs.StringVar(&cfg.Server.RunAddress, "a", cfg.Server.RunAddress, runAddressFlagUsage)
+	fs.StringVar(&cfg.Database.DSN, "d", cfg.Database.DSN, DsnFlagUsage)
+	fs.StringVar(&cfg.Accrual.Address, "r", cfg.Accrual.Address, "Accrual service address")
+	fs.StringVar(&cfg.Server.TLS.CertFile, "cert-file", cfg.Server.TLS.CertFile, "TLS certificate file")
+	fs.StringVar(&cfg.Server.TLS.KeyFile, "key-file", cfg.Server.TLS.KeyFile, "TLS private key file")
+	fs.StringVar(&cfg.Server.GRPC.Address, "grpc-address", cfg.Server.GRPC.Address, "gRPC API address, empty to disable")
+	fs.BoolVar(&cfg.Server.ValidateRequests, "validate-requests", cfg.Server.ValidateRequests, "Validate requests against the OpenAPI schema")
+	fs.BoolVar(&cfg.Database.MigrateOnStart, "migrate-on-start", cfg.Database.MigrateOnStart, "Apply migrations before serving")
+	fs.BoolVar(&cfg.Auth.DevMode, "dev", cfg.Auth.DevMode, "Development mode: allows an empty secret")
+	return fs
+}
 
-	secret, ok := os.LookupEnv("SECRET_KEY")
-	if !ok {
-		cfg.Secret = ""
-	} else {
-		cfg.Secret = secret
+// loadFile накладывает файл на текущие значения. Формат определяется по расширению;
+// неизвестные ключи и неверные длительности считаются ошибкой.
+func loadFile(cfg *Config, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
 	}
+	switch strings.ToLower(path[strings.LastIndex(path, ".")+1:]) {
+	case "yaml", "yml", "json":
+	default:
+		return fmt.Errorf("config file %s: unsupported extension, expected .yaml, .yml or .json", path)
+	}
+	// JSON — подмножество YAML, поэтому один декодер обслуживает оба формата.
+	dec := yaml.NewDecoder(strings.NewReader(string(raw)))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
 
-	if runAddress, ok := os.LookupEnv(RunADDRESSKEY); ok {
-		cfg.RunAddress = runAddress
+// String печатает итоговую конфигурацию в YAML со скрытыми секретами.
+func (c *Config) String() string {
+	cp := *c
+	if cp.Auth.Secret != "" {
+		cp.Auth.Secret = redacted
+	}
+	if cp.Accrual.CallbackSecret != "" {
+		cp.Accrual.CallbackSecret = redacted
 	}
-	if dsn, ok := os.LookupEnv(DSNKEY); ok {
-		cfg.Dsn = dsn
+	if cp.Outbox.WebhookSecret != "" {
+		cp.Outbox.WebhookSecret = redacted
 	}
-	if accrualServiceAddress, ok := os.LookupEnv(ACCRUALSYSTEMADDRESS); ok {
-		cfg.AccrualServiceAddress = accrualServiceAddress
+	cp.Database.DSN = redactDSN(cp.Database.DSN)
+	cp.Database.Replica.DSN = redactDSN(cp.Database.Replica.DSN)
+	out, err := yaml.Marshal(&cp)
+	if err != nil {
+		return "config: " + err.Error()
 	}
+	return string(out)
+}
 
-	return &cfg
+// redactDSN скрывает пароль как в URL-форме, так и в форме key=value.
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		q := u.Query()
+		if q.Has("password") {
+			q.Set("password", redacted)
+			u.RawQuery = q.Encode()
+		}
+		return strings.Replace(u.String(), url.QueryEscape(redacted), redacted, -1)
+	}
+	parts := strings.Fields(dsn)
+	for i, p := range parts {
+		if strings.HasPrefix(p, "password=") {
+			parts[i] = "password=" + redacted
+		}
+	}
+	return strings.Join(parts, " ")
 }
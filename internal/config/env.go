@@ -0,0 +1,275 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CONFIGKEY            = "CONFIG"
+	RunADDRESSKEY        = "RUN_ADDRESS"
+	DSNKEY               = "DATABASE_URI"
+	ACCRUALSYSTEMADDRESS = "ACCRUAL_SYSTEM_ADDRESS"
+	ACCRUALTIMEOUT       = "ACCRUAL_TIMEOUT"
+	ACCRUALRETRIES       = "ACCRUAL_RETRIES"
+	ACCRUALRETRYBACKOFF  = "ACCRUAL_RETRY_BACKOFF"
+	ACCRUALBREAKERFAILS  = "ACCRUAL_BREAKER_THRESHOLD"
+	ACCRUALBREAKEROPEN   = "ACCRUAL_BREAKER_OPEN_TIMEOUT"
+	ACCRUALMAXIDLECONNS  = "ACCRUAL_MAX_IDLE_CONNS"
+	ACCRUALCALLBACKKEY   = "ACCRUAL_CALLBACK_SECRET"
+	SECRETKEY            = "SECRET_KEY"
+	DEVMODE              = "DEV_MODE"
+	LOGLEVEL             = "LOG_LEVEL"
+	LOGFORMAT            = "LOG_FORMAT"
+	VALIDATEREQUESTS     = "VALIDATE_REQUESTS"
+
+	CORSALLOWEDORIGINS   = "CORS_ALLOWED_ORIGINS"
+	CORSALLOWCREDENTIALS = "CORS_ALLOW_CREDENTIALS"
+	CORSALLOWEDMETHODS   = "CORS_ALLOWED_METHODS"
+	CORSALLOWEDHEADERS   = "CORS_ALLOWED_HEADERS"
+	CORSMAXAGE           = "CORS_MAX_AGE"
+	COOKIESECURE         = "COOKIE_SECURE"
+	COOKIESAMESITE       = "COOKIE_SAMESITE"
+	COOKIEDOMAIN         = "COOKIE_DOMAIN"
+	COOKIEMAXAGE         = "COOKIE_MAX_AGE"
+	CSRFENABLED          = "CSRF_ENABLED"
+
+	TLSCERTFILE       = "TLS_CERT_FILE"
+	TLSKEYFILE        = "TLS_KEY_FILE"
+	TLSRELOADINTERVAL = "TLS_RELOAD_INTERVAL"
+	ADMINADDRESS      = "ADMIN_ADDRESS"
+	ADMINCERTFILE     = "ADMIN_TLS_CERT_FILE"
+	ADMINKEYFILE      = "ADMIN_TLS_KEY_FILE"
+	ADMINCLIENTCAFILE = "ADMIN_CLIENT_CA_FILE"
+	GRPCADDRESS       = "GRPC_ADDRESS"
+	GRPCCERTFILE      = "GRPC_TLS_CERT_FILE"
+	GRPCKEYFILE       = "GRPC_TLS_KEY_FILE"
+
+	MIGRATEONSTART      = "MIGRATE_ON_START"
+	DBMAXCONNS          = "DB_MAX_CONNS"
+	DBMINCONNS          = "DB_MIN_CONNS"
+	DBMINIDLECONNS      = "DB_MIN_IDLE_CONNS"
+	DBMAXCONNLIFETIME   = "DB_MAX_CONN_LIFETIME"
+	DBMAXCONNIDLETIME   = "DB_MAX_CONN_IDLE_TIME"
+	DBHEALTHCHECKPERIOD = "DB_HEALTH_CHECK_PERIOD"
+	DBSTATEMENTTIMEOUT  = "DB_STATEMENT_TIMEOUT"
+	DBCONNECTTIMEOUT    = "DB_CONNECT_TIMEOUT"
+	DBCONNECTRETRIES    = "DB_CONNECT_RETRIES"
+	DBCONNECTBACKOFF    = "DB_CONNECT_BACKOFF"
+	DBSATURATIONCHECK   = "DB_SATURATION_CHECK"
+
+	REPLICADSNKEY        = "DATABASE_REPLICA_URI"
+	REPLICAMAXLAG        = "DB_REPLICA_MAX_LAG"
+	REPLICACHECKINTERVAL = "DB_REPLICA_CHECK_INTERVAL"
+	REPLICAPINWINDOW     = "DB_REPLICA_PIN_WINDOW"
+
+	WORKERPOLLINTERVAL   = "WORKER_POLL_INTERVAL"
+	WORKERBATCHSIZE      = "WORKER_BATCH_SIZE"
+	WORKERSYNCINTERVAL   = "WORKER_SYNC_INTERVAL"
+	WORKERRATELIMITPAUSE = "WORKER_RATE_LIMIT_PAUSE"
+	WORKERMAXORDERAGE    = "WORKER_MAX_ORDER_AGE"
+	WORKERFALLBACKSYNC   = "WORKER_FALLBACK_SYNC_INTERVAL"
+
+	OUTBOXSINK          = "OUTBOX_SINK"
+	OUTBOXFILE          = "OUTBOX_FILE"
+	OUTBOXWEBHOOKURL    = "OUTBOX_WEBHOOK_URL"
+	OUTBOXWEBHOOKSECRET = "OUTBOX_WEBHOOK_SECRET"
+	OUTBOXPOLLINTERVAL  = "OUTBOX_POLL_INTERVAL"
+	OUTBOXBATCHSIZE     = "OUTBOX_BATCH_SIZE"
+	OUTBOXRETRYBACKOFF  = "OUTBOX_RETRY_BACKOFF"
+	OUTBOXMAXBACKOFF    = "OUTBOX_MAX_BACKOFF"
+	OUTBOXLEASE         = "OUTBOX_LEASE"
+	OUTBOXRETENTION     = "OUTBOX_RETENTION"
+
+	WEBHOOKSENABLED      = "WEBHOOKS_ENABLED"
+	WEBHOOKSPOLLINTERVAL = "WEBHOOKS_POLL_INTERVAL"
+	WEBHOOKSBATCHSIZE    = "WEBHOOKS_BATCH_SIZE"
+	WEBHOOKSCONCURRENCY  = "WEBHOOKS_CONCURRENCY"
+	WEBHOOKSTIMEOUT      = "WEBHOOKS_TIMEOUT"
+	WEBHOOKSRETRYBACKOFF = "WEBHOOKS_RETRY_BACKOFF"
+	WEBHOOKSMAXBACKOFF   = "WEBHOOKS_MAX_BACKOFF"
+	WEBHOOKSMAXATTEMPTS  = "WEBHOOKS_MAX_ATTEMPTS"
+	WEBHOOKSALLOWPRIVATE = "WEBHOOKS_ALLOW_PRIVATE_TARGETS"
+)
+
+// envLoader читает переменные окружения и копит ошибки разбора, чтобы сообщить обо всех сразу.
+type envLoader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func loadEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	e := &envLoader{lookup: lookup}
+
+	e.str(LOGLEVEL, &cfg.Logger.Level)
+	e.str(LOGFORMAT, &cfg.Logger.Format)
+
+	e.str(RunADDRESSKEY, &cfg.Server.RunAddress)
+	e.boolean(VALIDATEREQUESTS, &cfg.Server.ValidateRequests)
+	e.list(CORSALLOWEDORIGINS, &cfg.Server.CORS.AllowedOrigins)
+	e.boolean(CORSALLOWCREDENTIALS, &cfg.Server.CORS.AllowCredentials)
+	e.list(CORSALLOWEDMETHODS, &cfg.Server.CORS.AllowedMethods)
+	e.list(CORSALLOWEDHEADERS, &cfg.Server.CORS.AllowedHeaders)
+	e.duration(CORSMAXAGE, &cfg.Server.CORS.MaxAge)
+	e.boolean(COOKIESECURE, &cfg.Server.Cookie.Secure)
+	e.str(COOKIESAMESITE, &cfg.Server.Cookie.SameSite)
+	e.str(COOKIEDOMAIN, &cfg.Server.Cookie.Domain)
+	e.duration(COOKIEMAXAGE, &cfg.Server.Cookie.MaxAge)
+	e.boolean(CSRFENABLED, &cfg.Server.CSRFEnabled)
+
+	e.str(TLSCERTFILE, &cfg.Server.TLS.CertFile)
+	e.str(TLSKEYFILE, &cfg.Server.TLS.KeyFile)
+	e.duration(TLSRELOADINTERVAL, &cfg.Server.TLS.ReloadInterval)
+	e.str(ADMINADDRESS, &cfg.Server.Admin.Address)
+	e.str(ADMINCERTFILE, &cfg.Server.Admin.TLS.CertFile)
+	e.str(ADMINKEYFILE, &cfg.Server.Admin.TLS.KeyFile)
+	e.str(ADMINCLIENTCAFILE, &cfg.Server.Admin.ClientCAFile)
+	e.str(GRPCADDRESS, &cfg.Server.GRPC.Address)
+	e.str(GRPCCERTFILE, &cfg.Server.GRPC.TLS.CertFile)
+	e.str(GRPCKEYFILE, &cfg.Server.GRPC.TLS.KeyFile)
+
+	e.str(DSNKEY, &cfg.Database.DSN)
+	e.boolean(MIGRATEONSTART, &cfg.Database.MigrateOnStart)
+	e.int32(DBMAXCONNS, &cfg.Database.MaxConns)
+	e.int32(DBMINCONNS, &cfg.Database.MinConns)
+	e.int32(DBMINIDLECONNS, &cfg.Database.MinIdleConns)
+	e.duration(DBMAXCONNLIFETIME, &cfg.Database.MaxConnLifetime)
+	e.duration(DBMAXCONNIDLETIME, &cfg.Database.MaxConnIdleTime)
+	e.duration(DBHEALTHCHECKPERIOD, &cfg.Database.HealthCheckPeriod)
+	e.duration(DBSTATEMENTTIMEOUT, &cfg.Database.StatementTimeout)
+	e.duration(DBCONNECTTIMEOUT, &cfg.Database.ConnectTimeout)
+	e.int32(DBCONNECTRETRIES, &cfg.Database.ConnectRetries)
+	e.duration(DBCONNECTBACKOFF, &cfg.Database.ConnectBackoff)
+	e.duration(DBSATURATIONCHECK, &cfg.Database.SaturationCheck)
+	e.str(REPLICADSNKEY, &cfg.Database.Replica.DSN)
+	e.duration(REPLICAMAXLAG, &cfg.Database.Replica.MaxLag)
+	e.duration(REPLICACHECKINTERVAL, &cfg.Database.Replica.CheckInterval)
+	e.duration(REPLICAPINWINDOW, &cfg.Database.Replica.PinWindow)
+
+	e.duration(WORKERPOLLINTERVAL, &cfg.Worker.PollInterval)
+	e.int32(WORKERBATCHSIZE, &cfg.Worker.BatchSize)
+	e.duration(WORKERSYNCINTERVAL, &cfg.Worker.SyncInterval)
+	e.duration(WORKERRATELIMITPAUSE, &cfg.Worker.RateLimitPause)
+	e.duration(WORKERMAXORDERAGE, &cfg.Worker.MaxOrderAge)
+	e.duration(WORKERFALLBACKSYNC, &cfg.Worker.FallbackSyncInterval)
+
+	e.str(ACCRUALSYSTEMADDRESS, &cfg.Accrual.Address)
+	e.duration(ACCRUALTIMEOUT, &cfg.Accrual.Timeout)
+	e.integer(ACCRUALRETRIES, &cfg.Accrual.Retries)
+	e.duration(ACCRUALRETRYBACKOFF, &cfg.Accrual.RetryBackoff)
+	e.integer(ACCRUALBREAKERFAILS, &cfg.Accrual.BreakerThreshold)
+	e.duration(ACCRUALBREAKEROPEN, &cfg.Accrual.BreakerOpenTimeout)
+	e.integer(ACCRUALMAXIDLECONNS, &cfg.Accrual.MaxIdleConns)
+	e.str(ACCRUALCALLBACKKEY, &cfg.Accrual.CallbackSecret)
+
+	e.str(OUTBOXSINK, &cfg.Outbox.Sink)
+	e.str(OUTBOXFILE, &cfg.Outbox.File)
+	e.str(OUTBOXWEBHOOKURL, &cfg.Outbox.WebhookURL)
+	e.str(OUTBOXWEBHOOKSECRET, &cfg.Outbox.WebhookSecret)
+	e.duration(OUTBOXPOLLINTERVAL, &cfg.Outbox.PollInterval)
+	e.int32(OUTBOXBATCHSIZE, &cfg.Outbox.BatchSize)
+	e.duration(OUTBOXRETRYBACKOFF, &cfg.Outbox.RetryBackoff)
+	e.duration(OUTBOXMAXBACKOFF, &cfg.Outbox.MaxBackoff)
+	e.duration(OUTBOXLEASE, &cfg.Outbox.Lease)
+	e.duration(OUTBOXRETENTION, &cfg.Outbox.Retention)
+
+	e.boolean(WEBHOOKSENABLED, &cfg.Webhooks.Enabled)
+	e.duration(WEBHOOKSPOLLINTERVAL, &cfg.Webhooks.PollInterval)
+	e.int32(WEBHOOKSBATCHSIZE, &cfg.Webhooks.BatchSize)
+	e.integer(WEBHOOKSCONCURRENCY, &cfg.Webhooks.Concurrency)
+	e.duration(WEBHOOKSTIMEOUT, &cfg.Webhooks.Timeout)
+	e.duration(WEBHOOKSRETRYBACKOFF, &cfg.Webhooks.RetryBackoff)
+	e.duration(WEBHOOKSMAXBACKOFF, &cfg.Webhooks.MaxBackoff)
+	e.int32(WEBHOOKSMAXATTEMPTS, &cfg.Webhooks.MaxAttempts)
+	e.boolean(WEBHOOKSALLOWPRIVATE, &cfg.Webhooks.AllowPrivateTargets)
+
+	e.str(SECRETKEY, &cfg.Auth.Secret)
+	e.boolean(DEVMODE, &cfg.Auth.DevMode)
+
+	return errors.Join(e.errs...)
+}
+
+func (e *envLoader) str(key string, dst *string) {
+	if v, ok := e.lookup(key); ok {
+		*dst = v
+	}
+}
+
+// value возвращает непустое значение переменной; пустые значения трактуются как «не задано».
+func (e *envLoader) value(key string) (string, bool) {
+	v, ok := e.lookup(key)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
+
+func (e *envLoader) list(key string, dst *[]string) {
+	if v, ok := e.lookup(key); ok {
+		*dst = splitList(v)
+	}
+}
+
+func (e *envLoader) boolean(key string, dst *bool) {
+	v, ok := e.value(key)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("env %s=%q: invalid bool", key, v))
+		return
+	}
+	*dst = b
+}
+
+func (e *envLoader) duration(key string, dst *time.Duration) {
+	v, ok := e.value(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("env %s=%q: invalid duration", key, v))
+		return
+	}
+	*dst = d
+}
+
+func (e *envLoader) int32(key string, dst *int32) {
+	v, ok := e.value(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("env %s=%q: invalid integer", key, v))
+		return
+	}
+	*dst = int32(n)
+}
+
+func (e *envLoader) integer(key string, dst *int) {
+	v, ok := e.value(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("env %s=%q: invalid integer", key, v))
+		return
+	}
+	*dst = n
+}
+
+func splitList(v string) []string {
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CORS описывает, каким origin'ам браузер разрешит обращаться к API.
+// Пустой AllowedOrigins выключает CORS.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" json:"allowed_origins"`
+	AllowCredentials bool          `yaml:"allow_credentials" json:"allow_credentials"`
+	AllowedMethods   []string      `yaml:"allowed_methods" json:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" json:"allowed_headers"`
+	MaxAge           time.Duration `yaml:"max_age" json:"max_age"`
+}
+
+// Cookie — атрибуты cookie с токеном авторизации и CSRF-токеном.
+type Cookie struct {
+	Secure   bool          `yaml:"secure" json:"secure"`
+	SameSite string        `yaml:"same_site" json:"same_site"`
+	Domain   string        `yaml:"domain" json:"domain"`
+	MaxAge   time.Duration `yaml:"max_age" json:"max_age"`
+}
+
+// SameSiteMode переводит строковое значение из конфига в http.SameSite.
+func (c *Cookie) SameSiteMode() http.SameSite {
+	switch strings.ToLower(c.SameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	case "default":
+		return http.SameSiteDefaultMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+func defaultCORS() CORS {
+	return CORS{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions},
+		AllowedHeaders: []string{"Content-Type", "Content-Encoding", "Accept-Encoding", "X-Request-ID", "X-CSRF-Token"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+func defaultCookie() Cookie {
+	return Cookie{
+		SameSite: "lax",
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import "time"
+
+// TLS включает HTTPS (и HTTP/2), если заданы оба файла.
+type TLS struct {
+	CertFile       string        `yaml:"cert_file" json:"cert_file"`
+	KeyFile        string        `yaml:"key_file" json:"key_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval" json:"reload_interval"`
+}
+
+func (t *TLS) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// Admin — отдельный слушатель для служебных маршрутов /internal/*.
+// С ClientCAFile клиент обязан предъявить сертификат, подписанный этим CA (mTLS).
+type Admin struct {
+	Address      string `yaml:"address" json:"address"`
+	TLS          TLS    `yaml:"tls" json:"tls"`
+	ClientCAFile string `yaml:"client_ca_file" json:"client_ca_file"`
+}
+
+// GRPC — слушатель gRPC API. Пустой адрес выключает его.
+type GRPC struct {
+	Address string `yaml:"address" json:"address"`
+	TLS     TLS    `yaml:"tls" json:"tls"`
+}
+
+func defaultTLS() TLS {
+	return TLS{ReloadInterval: 30 * time.Second}
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Validate проверяет итоговую конфигурацию и нормализует адреса слушателей.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	if _, err := logrus.ParseLevel(c.Logger.Level); err != nil {
+		add("logging.level: %s", err.Error())
+	}
+	switch strings.ToLower(c.Logger.Format) {
+	case "text", "json":
+	default:
+		add("logging.format: unknown format %q", c.Logger.Format)
+	}
+
+	if addr, err := NormalizeAddress(c.Server.RunAddress); err != nil {
+		add("server.run_address: %s", err.Error())
+	} else {
+		c.Server.RunAddress = addr
+	}
+	if c.Server.Admin.Address != "" {
+		if addr, err := NormalizeAddress(c.Server.Admin.Address); err != nil {
+			add("server.admin.address: %s", err.Error())
+		} else {
+			c.Server.Admin.Address = addr
+		}
+	}
+	if c.Server.GRPC.Address != "" {
+		if addr, err := NormalizeAddress(c.Server.GRPC.Address); err != nil {
+			add("server.grpc.address: %s", err.Error())
+		} else {
+			c.Server.GRPC.Address = addr
+		}
+	}
+	for name, t := range map[string]TLS{
+		"server.tls":       c.Server.TLS,
+		"server.admin.tls": c.Server.Admin.TLS,
+		"server.grpc.tls":  c.Server.GRPC.TLS,
+	} {
+		if (t.CertFile == "") != (t.KeyFile == "") {
+			add("%s: cert_file and key_file must be set together", name)
+		}
+		if t.ReloadInterval < 0 {
+			add("%s.reload_interval: must not be negative", name)
+		}
+	}
+	if c.Server.Admin.ClientCAFile != "" && !c.Server.Admin.TLS.Enabled() {
+		add("server.admin.client_ca_file: requires server.admin.tls cert_file and key_file")
+	}
+
+	switch strings.ToLower(c.Server.Cookie.SameSite) {
+	case "lax", "strict", "default":
+	case "none":
+		if !c.Server.Cookie.Secure {
+			add("server.cookie.same_site: none requires secure cookies")
+		}
+	default:
+		add("server.cookie.same_site: unknown value %q", c.Server.Cookie.SameSite)
+	}
+	nonNegative(&errs, "server.cookie.max_age", c.Server.Cookie.MaxAge)
+	nonNegative(&errs, "server.cors.max_age", c.Server.CORS.MaxAge)
+	if c.Server.CORS.AllowCredentials && slices.Contains(c.Server.CORS.AllowedOrigins, "*") {
+		add("server.cors: wildcard origin cannot be combined with allow_credentials")
+	}
+
+	errs = append(errs, c.Database.validate()...)
+
+	positive(&errs, "worker.poll_interval", c.Worker.PollInterval)
+	positive(&errs, "worker.sync_interval", c.Worker.SyncInterval)
+	positive(&errs, "worker.rate_limit_pause", c.Worker.RateLimitPause)
+	nonNegative(&errs, "worker.max_order_age", c.Worker.MaxOrderAge)
+	if c.Worker.BatchSize <= 0 {
+		add("worker.batch_size: must be positive")
+	}
+
+	if u, err := url.Parse(c.Accrual.Address); err != nil || u.Scheme == "" || u.Host == "" {
+		add("accrual.address: expected absolute URL, got %q", c.Accrual.Address)
+	}
+	positive(&errs, "accrual.timeout", c.Accrual.Timeout)
+	positive(&errs, "accrual.retry_backoff", c.Accrual.RetryBackoff)
+	positive(&errs, "accrual.breaker_open_timeout", c.Accrual.BreakerOpenTimeout)
+	if c.Accrual.Retries < 0 || c.Accrual.MaxIdleConns < 0 {
+		add("accrual: retries and max_idle_conns must not be negative")
+	}
+	if c.Accrual.BreakerThreshold <= 0 {
+		add("accrual.breaker_threshold: must be positive")
+	}
+	if c.Accrual.CallbackSecret != "" {
+		if c.Server.Admin.Address == "" {
+			add("accrual.callback_secret: callbacks are served on server.admin.address, which is not set")
+		}
+		positive(&errs, "worker.fallback_sync_interval", c.Worker.FallbackSyncInterval)
+	}
+
+	errs = append(errs, c.Outbox.validate()...)
+	if c.Webhooks.Enabled {
+		positive(&errs, "webhooks.poll_interval", c.Webhooks.PollInterval)
+		positive(&errs, "webhooks.timeout", c.Webhooks.Timeout)
+		positive(&errs, "webhooks.retry_backoff", c.Webhooks.RetryBackoff)
+		positive(&errs, "webhooks.max_backoff", c.Webhooks.MaxBackoff)
+		if c.Webhooks.BatchSize <= 0 || c.Webhooks.Concurrency <= 0 || c.Webhooks.MaxAttempts <= 0 {
+			add("webhooks: batch_size, concurrency and max_attempts must be positive")
+		}
+	}
+
+	if c.Auth.Secret == "" && !c.Auth.DevMode {
+		add("auth.secret: must not be empty outside dev mode (set SECRET_KEY or -dev)")
+	}
+
+	return errors.Join(errs...)
+}
+
+func positive(errs *[]error, name string, d time.Duration) {
+	if d <= 0 {
+		*errs = append(*errs, fmt.Errorf("%s: must be positive", name))
+	}
+}
+
+func nonNegative(errs *[]error, name string, d time.Duration) {
+	if d < 0 {
+		*errs = append(*errs, fmt.Errorf("%s: must not be negative", name))
+	}
+}
+
+// validate проверяет только параметры подключения к базе: их достаточно для команд migrate.
+func (db Database) validate() []error {
+	var errs []error
+	add := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	if db.MaxConns < 0 || db.MinConns < 0 || db.MinIdleConns < 0 || db.ConnectRetries < 0 {
+		add("database: connection counts and retries must not be negative")
+	}
+	if db.MaxConns > 0 && (db.MinConns > db.MaxConns || db.MinIdleConns > db.MaxConns) {
+		add("database: min_conns and min_idle_conns must not exceed max_conns")
+	}
+	nonNegative(&errs, "database.max_conn_lifetime", db.MaxConnLifetime)
+	nonNegative(&errs, "database.max_conn_idle_time", db.MaxConnIdleTime)
+	nonNegative(&errs, "database.health_check_period", db.HealthCheckPeriod)
+	nonNegative(&errs, "database.statement_timeout", db.StatementTimeout)
+	nonNegative(&errs, "database.saturation_check", db.SaturationCheck)
+	positive(&errs, "database.connect_timeout", db.ConnectTimeout)
+	positive(&errs, "database.connect_backoff", db.ConnectBackoff)
+	if db.Replica.DSN != "" {
+		positive(&errs, "database.replica.max_lag", db.Replica.MaxLag)
+		positive(&errs, "database.replica.check_interval", db.Replica.CheckInterval)
+		nonNegative(&errs, "database.replica.pin_window", db.Replica.PinWindow)
+	}
+	return errs
+}
+
+// ValidateDatabase проверяет конфигурацию для команд, которым нужна только база.
+func (c *Config) ValidateDatabase() error {
+	errs := c.Database.validate()
+	if c.Database.DSN == "" {
+		errs = append(errs, errors.New("database.dsn: must not be empty"))
+	}
+	return errors.Join(errs...)
+}
+
+func (o Outbox) validate() []error {
+	var errs []error
+	add := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	switch o.Sink {
+	case "", "stdout":
+	case "file":
+		if o.File == "" {
+			add("outbox.file: required for the file sink")
+		}
+	case "webhook":
+		if u, err := url.Parse(o.WebhookURL); err != nil || u.Scheme == "" || u.Host == "" {
+			add("outbox.webhook_url: expected absolute URL, got %q", o.WebhookURL)
+		}
+	default:
+		add("outbox.sink: unknown sink %q, expected stdout, file or webhook", o.Sink)
+	}
+	if o.Sink == "" {
+		return errs
+	}
+	positive(&errs, "outbox.poll_interval", o.PollInterval)
+	positive(&errs, "outbox.retry_backoff", o.RetryBackoff)
+	positive(&errs, "outbox.max_backoff", o.MaxBackoff)
+	positive(&errs, "outbox.lease", o.Lease)
+	nonNegative(&errs, "outbox.retention", o.Retention)
+	if o.BatchSize <= 0 {
+		add("outbox.batch_size: must be positive")
+	}
+	return errs
+}
@@ -0,0 +1,166 @@
+// Package events раздаёт события outbox подключённым клиентам. Каждая реплика
+// слушает канал Postgres LISTEN/NOTIFY, поэтому клиент получает событие,
+// к какому бы экземпляру сервиса он ни был подключён.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+)
+
+const (
+	// subscriberBuffer — сколько событий ждёт медленного клиента, прежде чем его отключат.
+	subscriberBuffer = 64
+
+	reconnectDelay    = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+type Listener interface {
+	// ListenEvents вызывает handle для каждого закоммиченного события и
+	// блокируется, пока не отменён ctx или не оборвалось соединение.
+	// ready вызывается, когда подписка на канал установлена.
+	ListenEvents(ctx context.Context, ready func(), handle func(outbox.Event)) error
+}
+
+// Broker держит одно соединение LISTEN и раскладывает события по подписчикам
+// их владельца. Подписка закрывается, если клиент не успевает читать или
+// соединение с базой оборвалось: клиент переподключается с Last-Event-ID
+// и дочитывает пропущенное из outbox.
+type Broker struct {
+	listener Listener
+
+	mu      sync.Mutex
+	subs    map[int32]map[chan outbox.Event]struct{}
+	stopped bool
+
+	cancel   func()
+	wg       sync.WaitGroup
+	runOnce  sync.Once
+	stopOnce sync.Once
+}
+
+func NewBroker(listener Listener) *Broker {
+	return &Broker{
+		listener: listener,
+		subs:     make(map[int32]map[chan outbox.Event]struct{}),
+	}
+}
+
+// Subscribe подписывает на события пользователя. Канал закрывается брокером
+// или вызовом unsubscribe; повторный вызов unsubscribe безопасен.
+func (b *Broker) Subscribe(userID int32) (<-chan outbox.Event, func()) {
+	ch := make(chan outbox.Event, subscriberBuffer)
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[chan outbox.Event]struct{})
+	}
+	b.subs[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, ch)
+	}
+}
+
+// remove вызывается под b.mu.
+func (b *Broker) remove(userID int32, ch chan outbox.Event) {
+	subs, ok := b.subs[userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(b.subs, userID)
+	}
+}
+
+func (b *Broker) publish(e outbox.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[e.UserID] {
+		select {
+		case ch <- e:
+		default:
+			logger.Log.WithField("user_id", e.UserID).Warn("events: subscriber is too slow, disconnecting")
+			b.remove(e.UserID, ch)
+		}
+	}
+}
+
+// reset закрывает все подписки.
+func (b *Broker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for userID, subs := range b.subs {
+		for ch := range subs {
+			b.remove(userID, ch)
+		}
+	}
+}
+
+func (b *Broker) Run() {
+	b.runOnce.Do(func() {
+		ctx, cancel := context.WithCancel(logger.WithField(context.Background(), "component", "events.broker"))
+		b.cancel = cancel
+		b.wg.Add(1)
+		go b.loop(ctx)
+	})
+	logger.Log.Info("run events.broker")
+}
+
+// Stop закрывает все подписки, поэтому его стоит вызывать до остановки
+// HTTP-сервера: иначе тот будет ждать завершения открытых потоков.
+func (b *Broker) Stop() {
+	b.stopOnce.Do(func() {
+		if b.cancel != nil {
+			b.cancel()
+		}
+	})
+	b.wg.Wait()
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+	b.reset()
+	logger.Log.Info("stop events.broker")
+}
+
+func (b *Broker) loop(ctx context.Context) {
+	defer b.wg.Done()
+
+	delay := reconnectDelay
+	for {
+		// Подписки, открытые до переподключения, могли пропустить события:
+		// закрываем их, как только LISTEN снова работает.
+		err := b.listener.ListenEvents(ctx, func() {
+			b.reset()
+			delay = reconnectDelay
+		}, b.publish)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.FromContext(ctx).Errorf("events.broker: listen: %v, reconnect in %s", err, delay)
+		b.reset()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+)
+
+// chanListener отдаёт события из канала; закрытие канала имитирует обрыв соединения.
+type chanListener struct {
+	conns chan chan outbox.Event
+	ready chan struct{}
+}
+
+func newChanListener() *chanListener {
+	return &chanListener{conns: make(chan chan outbox.Event), ready: make(chan struct{}, 1)}
+}
+
+func (l *chanListener) ListenEvents(ctx context.Context, ready func(), handle func(outbox.Event)) error {
+	var events chan outbox.Event
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case events = <-l.conns:
+	}
+	ready()
+	l.ready <- struct{}{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return errors.New("connection lost")
+			}
+			handle(e)
+		}
+	}
+}
+
+func receive(t *testing.T, ch <-chan outbox.Event) (outbox.Event, bool) {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		return e, ok
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+		return outbox.Event{}, false
+	}
+}
+
+func TestBroker_RoutesByUser(t *testing.T) {
+	b := NewBroker(nil)
+	alice, unsubAlice := b.Subscribe(1)
+	defer unsubAlice()
+	bob, unsubBob := b.Subscribe(2)
+	defer unsubBob()
+
+	b.publish(outbox.Event{ID: 10, UserID: 1})
+	b.publish(outbox.Event{ID: 11, UserID: 3})
+
+	if e, ok := receive(t, alice); !ok || e.ID != 10 {
+		t.Fatalf("alice got %+v, %v", e, ok)
+	}
+	select {
+	case e := <-bob:
+		t.Fatalf("bob got foreign event %+v", e)
+	default:
+	}
+}
+
+func TestBroker_DisconnectsSlowSubscriber(t *testing.T) {
+	b := NewBroker(nil)
+	ch, unsubscribe := b.Subscribe(1)
+	for i := 0; i <= subscriberBuffer; i++ {
+		b.publish(outbox.Event{ID: int64(i), UserID: 1})
+	}
+	for range subscriberBuffer {
+		<-ch
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("slow subscriber must be closed")
+	}
+	unsubscribe()
+	unsubscribe()
+}
+
+func TestBroker_ReconnectClosesSubscriptions(t *testing.T) {
+	l := newChanListener()
+	b := NewBroker(l)
+	b.Run()
+	defer b.Stop()
+
+	first := make(chan outbox.Event)
+	l.conns <- first
+	<-l.ready
+	ch, unsubscribe := b.Subscribe(1)
+	defer unsubscribe()
+	first <- outbox.Event{ID: 1, UserID: 1}
+	if e, ok := receive(t, ch); !ok || e.ID != 1 {
+		t.Fatalf("got %+v, %v", e, ok)
+	}
+
+	close(first)
+	if _, ok := receive(t, ch); ok {
+		t.Fatal("subscription must be closed after the connection is lost")
+	}
+}
+
+func TestBroker_StopClosesSubscriptions(t *testing.T) {
+	l := newChanListener()
+	b := NewBroker(l)
+	b.Run()
+	ch, _ := b.Subscribe(1)
+	b.Stop()
+
+	if _, ok := receive(t, ch); ok {
+		t.Fatal("subscription must be closed on Stop")
+	}
+	late, _ := b.Subscribe(1)
+	if _, ok := receive(t, late); ok {
+		t.Fatal("subscription after Stop must be closed")
+	}
+}
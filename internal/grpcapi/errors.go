@@ -0,0 +1,39 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain — домен ErrorInfo; Reason в нём совпадает с кодом problem+json HTTP API.
+const ErrorDomain = "gophermart"
+
+// grpcCodes переводит класс ошибки из apierror в код gRPC, поэтому
+// соответствие sentinel-ошибок задаётся в одном месте для обоих API.
+var grpcCodes = map[apierror.Kind]codes.Code{
+	apierror.InvalidArgument:   codes.InvalidArgument,
+	apierror.Unauthenticated:   codes.Unauthenticated,
+	apierror.InsufficientFunds: codes.FailedPrecondition,
+	apierror.NotFound:          codes.NotFound,
+	apierror.Conflict:          codes.AlreadyExists,
+	apierror.Unprocessable:     codes.InvalidArgument,
+}
+
+func statusError(ctx context.Context, err error) error {
+	e := apierror.FromError(err)
+	code, ok := grpcCodes[e.Kind]
+	if !ok {
+		logger.FromContext(ctx).Errorf("grpc: %s", err.Error())
+		code = codes.Internal
+	}
+	st := status.New(code, e.Message)
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{Reason: e.Code, Domain: ErrorDomain}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
@@ -0,0 +1,82 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	gophermartv1 "github.com/IvanOplesnin/gofermart.git/api/gophermart/v1"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationKey = "authorization"
+	bearerPrefix     = "Bearer "
+	requestIDKey     = "x-request-id"
+)
+
+// publicMethods не требуют токена.
+var publicMethods = map[string]bool{
+	gophermartv1.Gophermart_Register_FullMethodName: true,
+	gophermartv1.Gophermart_Login_FullMethodName:    true,
+}
+
+// Auth проверяет токен из метаданных "authorization: Bearer <token>" так же,
+// как mw.CheckCookie проверяет cookie, и кладёт пользователя в контекст.
+func Auth(tc service.TokenChecker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return next(ctx, req)
+		}
+		token := bearerToken(ctx)
+		if token == "" {
+			return nil, statusError(ctx, gophermart.ErrNoToken)
+		}
+		userID, err := tc.CheckToken(ctx, token)
+		if err != nil {
+			return nil, statusError(ctx, err)
+		}
+		ctx = service.WithUserID(ctx, userID)
+		ctx = logger.WithField(ctx, "user_id", userID)
+		logger.SetAccessField(ctx, "user_id", userID)
+		return next(ctx, req)
+	}
+}
+
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(authorizationKey) {
+		if len(v) > len(bearerPrefix) && strings.EqualFold(v[:len(bearerPrefix)], bearerPrefix) {
+			return strings.TrimSpace(v[len(bearerPrefix):])
+		}
+	}
+	return ""
+}
+
+// Logging — аналог mw.RequestID и mw.WithLogging: берёт x-request-id из
+// метаданных или создаёт новый и пишет строку журнала на каждый вызов.
+func Logging(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := logger.NewID()
+	if v := md.Get(requestIDKey); len(v) > 0 && v[0] != "" && len(v[0]) <= 128 {
+		id = v[0]
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	ctx = logger.WithField(ctx, "request_id", id)
+	ctx, access := logger.WithAccessFields(ctx)
+
+	resp, err := next(ctx, req)
+	logger.FromContext(ctx).WithFields(access.Fields()).WithFields(logrus.Fields{
+		"method":   info.FullMethod,
+		"code":     status.Code(err).String(),
+		"duration": time.Since(start),
+	}).Info("grpc request handled")
+	return resp, err
+}
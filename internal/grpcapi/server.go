@@ -0,0 +1,144 @@
+// Package grpcapi реализует gRPC-контракт api/gophermart/v1 поверх тех же
+// методов сервиса, что и HTTP-обработчики.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	gophermartv1 "github.com/IvanOplesnin/gofermart.git/api/gophermart/v1"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Deps — те же интерфейсы, что у HTTP-обработчиков; все их реализует gophermart.Service.
+type Deps struct {
+	Registrar    service.Registrar
+	Auther       service.Auther
+	TokenChecker service.TokenChecker
+	Ordered      service.Ordered
+	Balancer     service.Balancer
+	Withdrawer   service.Withdrawer
+}
+
+type Server struct {
+	gophermartv1.UnimplementedGophermartServer
+	deps Deps
+}
+
+// New создаёт gRPC-сервер с сервисом Gophermart, журналированием и проверкой токена.
+func New(deps Deps, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(Logging, Auth(deps.TokenChecker)))
+	s := grpc.NewServer(opts...)
+	gophermartv1.RegisterGophermartServer(s, &Server{deps: deps})
+	return s
+}
+
+func (s *Server) Register(ctx context.Context, req *gophermartv1.RegisterRequest) (*gophermartv1.AuthResponse, error) {
+	if req.GetLogin() == "" || req.GetPassword() == "" {
+		return nil, statusError(ctx, gophermart.ErrEmptyField)
+	}
+	token, err := s.deps.Registrar.Register(ctx, req.GetLogin(), req.GetPassword())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &gophermartv1.AuthResponse{Token: token}, nil
+}
+
+func (s *Server) Login(ctx context.Context, req *gophermartv1.LoginRequest) (*gophermartv1.AuthResponse, error) {
+	if req.GetLogin() == "" || req.GetPassword() == "" {
+		return nil, statusError(ctx, gophermart.ErrEmptyField)
+	}
+	token, err := s.deps.Auther.Auth(ctx, req.GetLogin(), req.GetPassword())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &gophermartv1.AuthResponse{Token: token}, nil
+}
+
+func (s *Server) AddOrder(ctx context.Context, req *gophermartv1.AddOrderRequest) (*gophermartv1.AddOrderResponse, error) {
+	userID, err := userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	exist, err := s.deps.Ordered.AddOrder(ctx, userID, req.GetNumber())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &gophermartv1.AddOrderResponse{AlreadyUploaded: exist}, nil
+}
+
+func (s *Server) ListOrders(ctx context.Context, _ *gophermartv1.ListOrdersRequest) (*gophermartv1.ListOrdersResponse, error) {
+	userID, err := userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	orders, err := s.deps.Ordered.Orders(ctx, userID)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	resp := &gophermartv1.ListOrdersResponse{Orders: make([]*gophermartv1.Order, 0, len(orders))}
+	for _, o := range orders {
+		resp.Orders = append(resp.Orders, &gophermartv1.Order{
+			Number:     o.Number,
+			Status:     o.Status,
+			Accrual:    o.Accrual,
+			UploadedAt: timestamppb.New(o.UploadedAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) GetBalance(ctx context.Context, _ *gophermartv1.GetBalanceRequest) (*gophermartv1.GetBalanceResponse, error) {
+	userID, err := userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	b, err := s.deps.Balancer.Balance(ctx, userID)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &gophermartv1.GetBalanceResponse{Current: b.Current, Withdrawn: b.Withdrawn}, nil
+}
+
+func (s *Server) Withdraw(ctx context.Context, req *gophermartv1.WithdrawRequest) (*gophermartv1.WithdrawResponse, error) {
+	userID, err := userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	if err := s.deps.Withdrawer.Withdraw(ctx, userID, req.GetOrder(), req.GetSum()); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &gophermartv1.WithdrawResponse{}, nil
+}
+
+func (s *Server) ListWithdrawals(ctx context.Context, _ *gophermartv1.ListWithdrawalsRequest) (*gophermartv1.ListWithdrawalsResponse, error) {
+	userID, err := userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	list, err := s.deps.Withdrawer.ListWithdraws(ctx, userID)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	resp := &gophermartv1.ListWithdrawalsResponse{Withdrawals: make([]*gophermartv1.Withdrawal, 0, len(list))}
+	for _, w := range list {
+		resp.Withdrawals = append(resp.Withdrawals, &gophermartv1.Withdrawal{
+			Order:       w.Order,
+			Sum:         w.Sum,
+			ProcessedAt: timestamppb.New(w.ProcessedAt),
+		})
+	}
+	return resp, nil
+}
+
+// userIDFromCtx возвращает пользователя, которого положил в контекст Auth.
+func userIDFromCtx(ctx context.Context) (int32, error) {
+	userID, ok := service.UserID(ctx)
+	if !ok {
+		return 0, errors.New("user id not found")
+	}
+	return userID, nil
+}
@@ -0,0 +1,215 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	gophermartv1 "github.com/IvanOplesnin/gofermart.git/api/gophermart/v1"
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const validToken = "valid"
+
+// fakeService записывает пользователя из контекста и возвращает заданную ошибку.
+type fakeService struct {
+	err    error
+	userID int32
+}
+
+func (f *fakeService) Register(_ context.Context, login, _ string) (string, error) {
+	return "token-" + login, f.err
+}
+
+func (f *fakeService) Auth(_ context.Context, login, _ string) (string, error) {
+	return "token-" + login, f.err
+}
+
+func (f *fakeService) CheckToken(_ context.Context, token string) (int32, error) {
+	if token != validToken {
+		return 0, gophermart.ErrInvalidToken
+	}
+	return 7, nil
+}
+
+func (f *fakeService) AddOrder(_ context.Context, userID int32, _ string) (bool, error) {
+	f.userID = userID
+	return true, f.err
+}
+
+func (f *fakeService) Orders(context.Context, int32) ([]gophermart.UserOrder, error) {
+	accrual := 12.5
+	uploaded := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []gophermart.UserOrder{
+		{Number: "1", Status: "PROCESSED", Accrual: &accrual, UploadedAt: uploaded},
+		{Number: "2", Status: "NEW", UploadedAt: uploaded},
+	}, f.err
+}
+
+func (f *fakeService) Balance(context.Context, int32) (gophermart.UserBalance, error) {
+	return gophermart.UserBalance{Current: 10, Withdrawn: 2}, f.err
+}
+
+func (f *fakeService) Withdraw(context.Context, int32, string, float64) error { return f.err }
+
+func (f *fakeService) ListWithdraws(context.Context, int32) ([]gophermart.Withdrawal, error) {
+	return nil, f.err
+}
+
+func newClient(t *testing.T, svc *fakeService) gophermartv1.GophermartClient {
+	t.Helper()
+	ln := bufconn.Listen(1 << 20)
+	srv := New(Deps{Registrar: svc, Auther: svc, TokenChecker: svc, Ordered: svc, Balancer: svc, Withdrawer: svc})
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return gophermartv1.NewGophermartClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationKey, bearerPrefix+token)
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{name: "no token", ctx: context.Background(), wantCode: codes.Unauthenticated},
+		{name: "invalid token", ctx: withToken("bad"), wantCode: codes.Unauthenticated},
+		{name: "valid token", ctx: withToken(validToken), wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{}
+			client := newClient(t, svc)
+			_, err := client.AddOrder(tt.ctx, &gophermartv1.AddOrderRequest{Number: "12345678903"})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %s, want %s (%v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && svc.userID != 7 {
+				t.Fatalf("user id in service context = %d, want 7", svc.userID)
+			}
+		})
+	}
+}
+
+func TestRegisterIsPublic(t *testing.T) {
+	client := newClient(t, &fakeService{})
+	resp, err := client.Register(context.Background(), &gophermartv1.RegisterRequest{Login: "bob", Password: "pw"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if resp.GetToken() != "token-bob" {
+		t.Fatalf("token = %q", resp.GetToken())
+	}
+	_, err = client.Register(context.Background(), &gophermartv1.RegisterRequest{Login: "bob"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("empty password: %v", err)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{name: "insufficient funds", err: gophermart.ErrNotEnoughBalance, wantCode: codes.FailedPrecondition, wantReason: apierror.CodeInsufficientFunds},
+		{name: "invalid order", err: gophermart.ErrInvalidOrderNumber, wantCode: codes.InvalidArgument, wantReason: apierror.CodeInvalidOrderNumber},
+		{name: "unknown error", err: context.DeadlineExceeded, wantCode: codes.Internal, wantReason: apierror.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(t, &fakeService{err: tt.err})
+			_, err := client.Withdraw(withToken(validToken), &gophermartv1.WithdrawRequest{Order: "1", Sum: 1})
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Fatalf("code = %s, want %s", st.Code(), tt.wantCode)
+			}
+			var reason string
+			for _, d := range st.Details() {
+				if info, ok := d.(*errdetails.ErrorInfo); ok {
+					reason = info.GetReason()
+				}
+			}
+			if reason != tt.wantReason {
+				t.Fatalf("reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestListOrders(t *testing.T) {
+	client := newClient(t, &fakeService{})
+	resp, err := client.ListOrders(withToken(validToken), &gophermartv1.ListOrdersRequest{})
+	if err != nil {
+		t.Fatalf("ListOrders: %v", err)
+	}
+	orders := resp.GetOrders()
+	if len(orders) != 2 {
+		t.Fatalf("got %d orders", len(orders))
+	}
+	if orders[0].Accrual == nil || orders[0].GetAccrual() != 12.5 {
+		t.Fatalf("accrual = %v", orders[0].Accrual)
+	}
+	if orders[1].Accrual != nil {
+		t.Fatalf("NEW order must not have accrual")
+	}
+	if got := orders[0].GetUploadedAt().AsTime(); !got.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("uploaded_at = %v", got)
+	}
+}
+
+// Logging пишет строку после Auth, но user_id в ней есть.
+func TestLogging_UserID(t *testing.T) {
+	var buf bytes.Buffer
+	out, formatter := logger.Log.Out, logger.Log.Formatter
+	logger.Log.SetOutput(&buf)
+	logger.Log.SetFormatter(&logrus.JSONFormatter{})
+	t.Cleanup(func() {
+		logger.Log.SetOutput(out)
+		logger.Log.SetFormatter(formatter)
+	})
+
+	info := &grpc.UnaryServerInfo{FullMethod: gophermartv1.Gophermart_ListOrders_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationKey, bearerPrefix+validToken))
+	auth := Auth(&fakeService{})
+	_, err := Logging(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		return auth(ctx, req, info, func(context.Context, any) (any, error) { return nil, nil })
+	})
+	if err != nil {
+		t.Fatalf("Logging: %v", err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log line %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "grpc request handled" || entry["user_id"] != float64(7) {
+		t.Fatalf("log line = %v, want grpc request handled with user_id 7", entry)
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+)
+
+// AccrualCallback применяет статус заказа, присланный системой начислений.
+type AccrualCallback interface {
+	ApplyAccrualCallback(ctx context.Context, number string, status string, accrual float64) error
+}
+
+// AccrualCallbackRequest повторяет формат ответа GET /api/orders/{number} системы начислений.
+type AccrualCallbackRequest struct {
+	Order   string  `json:"order"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual"`
+}
+
+// AccrualCallbackHandler принимает уведомление о статусе заказа. Повторная
+// доставка того же статуса безопасна и тоже отвечает 204.
+func AccrualCallbackHandler(a AccrualCallback) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(contentTypeKey) != applicationJSONValue {
+			writeBadRequest(w, r, apierror.CodeInvalidContentType, "expected Content-Type application/json")
+			return
+		}
+		var req AccrualCallbackRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "invalid JSON body")
+			return
+		}
+		if req.Order == "" {
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "order is required")
+			return
+		}
+		if err := a.ApplyAccrualCallback(r.Context(), req.Order, req.Status, req.Accrual); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
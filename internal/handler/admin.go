@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
+	mw "github.com/IvanOplesnin/gofermart.git/internal/handler/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type BreakerStater interface {
+	State() breaker.State
+}
+
+// AdminDeps — зависимости служебного роутера /internal/*.
+type AdminDeps struct {
+	DB Pinger
+	// Accrual — circuit breaker клиента начислений. Открытый breaker не валит
+	// проверку: API работает, откладывается только обновление статусов.
+	Accrual BreakerStater
+	// Metrics отдаёт метрики в формате Prometheus; nil — маршрут не регистрируется.
+	Metrics http.Handler
+	// AccrualCallback принимает уведомления системы начислений, подписанные
+	// CallbackSecret. Без секрета маршрут не регистрируется.
+	AccrualCallback AccrualCallback
+	CallbackSecret  []byte
+}
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// InitAdminHandler собирает роутер служебных маршрутов. Он обслуживается
+// отдельным слушателем, который можно закрыть mTLS.
+func InitAdminHandler(deps AdminDeps) *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(mw.RequestID)
+	router.Use(mw.WithLogging)
+
+	router.Get("/internal/health", HealthHandler(deps))
+	if deps.Metrics != nil {
+		router.Method(http.MethodGet, "/internal/metrics", deps.Metrics)
+	}
+	if deps.AccrualCallback != nil && len(deps.CallbackSecret) > 0 {
+		router.With(mw.VerifySignature(deps.CallbackSecret)).
+			Post("/internal/accrual/callback", AccrualCallbackHandler(deps.AccrualCallback))
+	}
+	return router
+}
+
+func HealthHandler(deps AdminDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := HealthResponse{Status: "ok", Checks: map[string]string{}}
+		status := http.StatusOK
+
+		if deps.DB != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+			defer cancel()
+			if err := deps.DB.Ping(ctx); err != nil {
+				resp.Status = "fail"
+				resp.Checks["db"] = err.Error()
+				status = http.StatusServiceUnavailable
+			} else {
+				resp.Checks["db"] = "ok"
+			}
+		}
+
+		if deps.Accrual != nil {
+			state := deps.Accrual.State()
+			resp.Checks["accrual"] = state.String()
+			if state != breaker.Closed && status == http.StatusOK {
+				resp.Status = "degraded"
+			}
+		}
+
+		w.Header().Set(contentTypeKey, applicationJSONValue)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/IvanOplesnin/gofermart.git/internal/signature"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+type stateFunc func() breaker.State
+
+func (f stateFunc) State() breaker.State { return f() }
+
+func TestHealthHandler(t *testing.T) {
+	ok := pingerFunc(func(context.Context) error { return nil })
+	down := pingerFunc(func(context.Context) error { return errors.New("db down") })
+
+	tests := []struct {
+		name       string
+		deps       AdminDeps
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			name:       "all ok",
+			deps:       AdminDeps{DB: ok, Accrual: stateFunc(func() breaker.State { return breaker.Closed })},
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+			wantChecks: map[string]string{"db": "ok", "accrual": "closed"},
+		},
+		{
+			name:       "accrual breaker open",
+			deps:       AdminDeps{DB: ok, Accrual: stateFunc(func() breaker.State { return breaker.Open })},
+			wantCode:   http.StatusOK,
+			wantStatus: "degraded",
+			wantChecks: map[string]string{"db": "ok", "accrual": "open"},
+		},
+		{
+			name:       "db down",
+			deps:       AdminDeps{DB: down, Accrual: stateFunc(func() breaker.State { return breaker.Open })},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "fail",
+			wantChecks: map[string]string{"db": "db down", "accrual": "open"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			HealthHandler(tt.deps)(rec, httptest.NewRequest(http.MethodGet, "/internal/health", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			var got HealthResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			for k, v := range tt.wantChecks {
+				if got.Checks[k] != v {
+					t.Errorf("checks[%s] = %q, want %q", k, got.Checks[k], v)
+				}
+			}
+		})
+	}
+}
+
+type callbackFunc func(ctx context.Context, number string, status string, accrual float64) error
+
+func (f callbackFunc) ApplyAccrualCallback(ctx context.Context, number string, status string, accrual float64) error {
+	return f(ctx, number, status, accrual)
+}
+
+func TestAccrualCallback(t *testing.T) {
+	secret := []byte("shared")
+	tests := []struct {
+		name     string
+		body     string
+		sign     bool
+		err      error
+		wantCode int
+	}{
+		{name: "applied", body: `{"order":"1","status":"PROCESSED","accrual":5}`, sign: true, wantCode: http.StatusNoContent},
+		{name: "unsigned", body: `{"order":"1","status":"PROCESSED"}`, wantCode: http.StatusUnauthorized},
+		{name: "unknown order", body: `{"order":"2","status":"PROCESSING"}`, sign: true, err: gophermart.ErrOrderNotFound, wantCode: http.StatusNotFound},
+		{name: "bad status", body: `{"order":"3","status":"DONE"}`, sign: true, err: gophermart.ErrInvalidAccrualStatus, wantCode: http.StatusUnprocessableEntity},
+		{name: "no order", body: `{"status":"PROCESSING"}`, sign: true, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := InitAdminHandler(AdminDeps{
+				AccrualCallback: callbackFunc(func(context.Context, string, string, float64) error { return tt.err }),
+				CallbackSecret:  secret,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/internal/accrual/callback", strings.NewReader(tt.body))
+			req.Header.Set(contentTypeKey, applicationJSONValue)
+			if tt.sign {
+				req.Header.Set(signature.Header, signature.Sign(secret, []byte(tt.body)))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+		})
+	}
+}
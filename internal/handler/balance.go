@@ -1,34 +1,33 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
 )
 
-type Balancer interface {
-	Balance(ctx context.Context) (BalanceResponse, error)
-}
-
 type BalanceResponse struct {
 	Current   float64 `json:"current"`
 	Withdrawn float64 `json:"withdrawn"`
 }
 
-func BalanceHandler(b Balancer) http.HandlerFunc {
+func BalanceHandler(b service.Balancer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		resp, err := b.Balance(ctx)
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		balance, err := b.Balance(r.Context(), userID)
 		if err != nil {
-			logger.Log.Errorf("BalanceHandler error: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
+		resp := BalanceResponse{Current: balance.Current, Withdrawn: balance.Withdrawn}
 		w.Header().Set(contentTypeKey, applicationJSONValue)
 		if err := json.NewEncoder(w).Encode(resp); err != nil {
-			logger.Log.Errorf("BalanceHandler error: %s", err.Error())
+			logger.FromContext(r.Context()).Errorf("BalanceHandler error: %s", err.Error())
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+// benchService отвечает заготовленными данными, чтобы замер касался только HTTP-слоя.
+type benchService struct {
+	orders    []gophermart.UserOrder
+	withdraws []gophermart.Withdrawal
+}
+
+func newBenchService(n int) *benchService {
+	s := &benchService{}
+	accrual := 500.5
+	for i := range n {
+		s.orders = append(s.orders, gophermart.UserOrder{
+			Number:     strconv.Itoa(10000000000 + i),
+			Status:     gophermart.StatusProcessed,
+			Accrual:    &accrual,
+			UploadedAt: time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC),
+		})
+		s.withdraws = append(s.withdraws, gophermart.Withdrawal{
+			Order:       strconv.Itoa(20000000000 + i),
+			Sum:         10,
+			ProcessedAt: time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC),
+		})
+	}
+	return s
+}
+
+func (s *benchService) Register(context.Context, string, string) (string, error) {
+	return "token", nil
+}
+
+func (s *benchService) Auth(context.Context, string, string) (string, error) {
+	return "token", nil
+}
+
+func (s *benchService) CheckToken(context.Context, string) (int32, error) {
+	return testUserID, nil
+}
+
+func (s *benchService) AddOrder(context.Context, int32, string) (bool, error) {
+	return false, nil
+}
+
+func (s *benchService) Orders(context.Context, int32) ([]gophermart.UserOrder, error) {
+	return s.orders, nil
+}
+
+func (s *benchService) Balance(context.Context, int32) (gophermart.UserBalance, error) {
+	return gophermart.UserBalance{Current: 500.5, Withdrawn: 42}, nil
+}
+
+func (s *benchService) Withdraw(context.Context, int32, string, float64) error {
+	return nil
+}
+
+func (s *benchService) ListWithdraws(context.Context, int32) ([]gophermart.Withdrawal, error) {
+	return s.withdraws, nil
+}
+
+// BenchmarkHandlers прогоняет запросы через весь роутер InitHandler:
+// request id, логирование, сжатие, проверку cookie и сам обработчик.
+func BenchmarkHandlers(b *testing.B) {
+	out := logger.Log.Out
+	logger.Log.SetOutput(io.Discard)
+	b.Cleanup(func() { logger.Log.SetOutput(out) })
+
+	benchmarks := []struct {
+		name     string
+		items    int
+		validate bool
+		method   string
+		path     string
+		body     string
+		ctype    string
+		gzip     bool
+		want     int
+	}{
+		{name: "register", method: http.MethodPost, path: "/api/user/register", body: `{"login":"alice","password":"pw"}`, ctype: "application/json", want: http.StatusOK},
+		{name: "login", method: http.MethodPost, path: "/api/user/login", body: `{"login":"alice","password":"pw"}`, ctype: "application/json", want: http.StatusOK},
+		{name: "add_order", method: http.MethodPost, path: "/api/user/orders", body: "12345678903", ctype: "text/plain", want: http.StatusAccepted},
+		{name: "add_order_validated", validate: true, method: http.MethodPost, path: "/api/user/orders", body: "12345678903", ctype: "text/plain", want: http.StatusAccepted},
+		{name: "orders_10", items: 10, method: http.MethodGet, path: "/api/user/orders", want: http.StatusOK},
+		{name: "orders_1000", items: 1000, method: http.MethodGet, path: "/api/user/orders", want: http.StatusOK},
+		{name: "orders_1000_gzip", items: 1000, method: http.MethodGet, path: "/api/user/orders", gzip: true, want: http.StatusOK},
+		{name: "balance", method: http.MethodGet, path: "/api/user/balance", want: http.StatusOK},
+		{name: "withdraw", method: http.MethodPost, path: "/api/user/balance/withdraw", body: `{"order":"2377225624","sum":10}`, ctype: "application/json", want: http.StatusOK},
+		{name: "withdrawals_100", items: 100, method: http.MethodGet, path: "/api/user/withdrawals", want: http.StatusOK},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			svc := newBenchService(bm.items)
+			router := InitHandler(HandlerDeps{
+				Reqistrar:        svc,
+				Auther:           svc,
+				TokenChecker:     svc,
+				Ordered:          svc,
+				Balancer:         svc,
+				Withdrawer:       svc,
+				ValidateRequests: bm.validate,
+			})
+			b.ReportAllocs()
+			for b.Loop() {
+				req := httptest.NewRequest(bm.method, bm.path, strings.NewReader(bm.body))
+				if bm.ctype != "" {
+					req.Header.Set("Content-Type", bm.ctype)
+				}
+				if bm.gzip {
+					req.Header.Set("Accept-Encoding", "gzip")
+				}
+				req.AddCookie(&http.Cookie{Name: "token", Value: "token"})
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				if rec.Code != bm.want {
+					b.Fatalf("status = %d, want %d: %s", rec.Code, bm.want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	mw "github.com/IvanOplesnin/gofermart.git/internal/handler/middleware"
+)
+
+// CookieOptions — атрибуты cookie, которые выставляются при регистрации и входе.
+type CookieOptions struct {
+	Cookie config.Cookie
+	CSRF   bool
+}
+
+func setAuthCookies(w http.ResponseWriter, opts CookieOptions, token string) {
+	if token == "" {
+		return
+	}
+	http.SetCookie(w, mw.NewCookie(opts.Cookie, tokenCookieName, token, true))
+	if opts.CSRF {
+		mw.IssueCSRFToken(w, opts.Cookie)
+	}
+}
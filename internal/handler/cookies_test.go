@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	mw "github.com/IvanOplesnin/gofermart.git/internal/handler/middleware"
+)
+
+// Фронтенд на другом origin не может прочитать cookie csrf_token и берёт
+// токен из заголовка ответа, который ему открывает CORS.
+func TestCSRF_CrossOriginFlow(t *testing.T) {
+	const origin = "https://app.example.com"
+	svc := newBenchService(0)
+	srv := httptest.NewServer(InitHandler(HandlerDeps{
+		Reqistrar:    svc,
+		Auther:       svc,
+		TokenChecker: svc,
+		Ordered:      svc,
+		Balancer:     svc,
+		Withdrawer:   svc,
+		CORS: config.CORS{
+			AllowedOrigins:   []string{origin},
+			AllowCredentials: true,
+		},
+		Cookies: CookieOptions{CSRF: true},
+	}))
+	t.Cleanup(srv.Close)
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar}
+	do := func(method, path, body, ctype, csrf string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		if ctype != "" {
+			req.Header.Set("Content-Type", ctype)
+		}
+		if csrf != "" {
+			req.Header.Set(mw.CSRFHeader, csrf)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	readable := func(resp *http.Response) string {
+		t.Helper()
+		if resp.Header.Get("Access-Control-Allow-Origin") != origin || resp.Header.Get("Access-Control-Allow-Credentials") != "true" {
+			t.Fatalf("CORS headers = %v", resp.Header)
+		}
+		if !strings.Contains(resp.Header.Get("Access-Control-Expose-Headers"), mw.CSRFHeader) {
+			t.Fatalf("%s is not exposed: %v", mw.CSRFHeader, resp.Header)
+		}
+		return resp.Header.Get(mw.CSRFHeader)
+	}
+
+	resp := do(http.MethodPost, "/api/user/login", `{"login":"alice","password":"pw"}`, "application/json", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login status = %d", resp.StatusCode)
+	}
+	token := readable(resp)
+	if token == "" {
+		t.Fatal("login did not return a csrf token header")
+	}
+
+	if resp := do(http.MethodPost, "/api/user/orders", "12345678903", "text/plain", ""); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("order without csrf header: status = %d, want 403", resp.StatusCode)
+	}
+	if resp := do(http.MethodPost, "/api/user/orders", "12345678903", "text/plain", token); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("order with csrf header: status = %d, want 202", resp.StatusCode)
+	}
+
+	// После перезагрузки страницы токен в памяти потерян: GET возвращает текущий.
+	resp = do(http.MethodGet, "/api/user/balance", "", "", "")
+	if got := readable(resp); got != token {
+		t.Fatalf("GET returned csrf token %q, want %q", got, token)
+	}
+}
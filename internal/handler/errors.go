@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+)
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	p := problem.FromError(err)
+	if p.Status >= http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Errorf("%s %s: %s", r.Method, r.URL.Path, err.Error())
+	}
+	problem.Write(w, r, p)
+}
+
+func writeBadRequest(w http.ResponseWriter, r *http.Request, code string, message string) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, code, message))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+func TestWriteError_Envelope(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/user/orders", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	rr := httptest.NewRecorder()
+
+	writeError(rr, req, fmt.Errorf("wrap: %w", gophermart.ErrOrderOwnedByAnotherUser))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d", http.StatusConflict, rr.Code)
+	}
+	if ct := rr.Header().Get(contentTypeKey); ct != "application/problem+json" {
+		t.Fatalf("expected Content-Type application/problem+json, got %q", ct)
+	}
+	var got problem.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json body %q: %v", rr.Body.String(), err)
+	}
+	if got.Code != apierror.CodeOrderOwnedByAnotherUser {
+		t.Fatalf("expected code %q, got %q", apierror.CodeOrderOwnedByAnotherUser, got.Code)
+	}
+	if got.Message != gophermart.ErrOrderOwnedByAnotherUser.Error() {
+		t.Fatalf("expected message %q, got %q", gophermart.ErrOrderOwnedByAnotherUser.Error(), got.Message)
+	}
+	if got.RequestID != "req-1" {
+		t.Fatalf("expected request_id %q, got %q", "req-1", got.RequestID)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+)
+
+const (
+	lastEventIDKey = "Last-Event-ID"
+
+	// eventsKeepAlive — период комментариев, не дающих прокси закрыть простаивающий поток.
+	eventsKeepAlive = 15 * time.Second
+	// eventsRetry — пауза перед переподключением, которую браузер берёт из поля retry.
+	eventsRetry = 3 * time.Second
+)
+
+// EventsHandler отдаёт поток Server-Sent Events. Когда поток закрывается
+// сервером, клиент переподключается с Last-Event-ID и получает пропущенное.
+func EventsHandler(es service.EventStreamer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		var lastID int64
+		if v := r.Header.Get(lastEventIDKey); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id < 0 {
+				writeBadRequest(w, r, apierror.CodeBadRequest, "invalid Last-Event-ID")
+				return
+			}
+			lastID = id
+		}
+		events, err := es.SubscribeEvents(r.Context(), userID, lastID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		w.Header().Set(contentTypeKey, "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", eventsRetry.Milliseconds())
+		if err := rc.Flush(); err != nil {
+			logger.FromContext(r.Context()).Errorf("EventsHandler: %s", err.Error())
+			return
+		}
+
+		ticker := time.NewTicker(eventsKeepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Payload)
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+)
+
+type streamerFunc func(ctx context.Context, userID int32, lastEventID int64) (<-chan outbox.Event, error)
+
+func (f streamerFunc) SubscribeEvents(ctx context.Context, userID int32, lastEventID int64) (<-chan outbox.Event, error) {
+	return f(ctx, userID, lastEventID)
+}
+
+func TestEventsHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantLastID  int64
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "new stream",
+			wantStatus: http.StatusOK,
+			wantBody: "retry: 3000\n\n" +
+				"id: 7\nevent: order.processed\ndata: {\"order\":\"1\"}\n\n",
+		},
+		{
+			name:        "resume",
+			lastEventID: "5",
+			wantLastID:  5,
+			wantStatus:  http.StatusOK,
+			wantBody: "retry: 3000\n\n" +
+				"id: 7\nevent: order.processed\ndata: {\"order\":\"1\"}\n\n",
+		},
+		{name: "bad Last-Event-ID -> 400", lastEventID: "abc", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := streamerFunc(func(_ context.Context, userID int32, lastEventID int64) (<-chan outbox.Event, error) {
+				if userID != testUserID {
+					t.Errorf("userID = %d, want %d", userID, testUserID)
+				}
+				if lastEventID != tt.wantLastID {
+					t.Errorf("lastEventID = %d, want %d", lastEventID, tt.wantLastID)
+				}
+				ch := make(chan outbox.Event, 1)
+				ch <- outbox.Event{ID: 7, Type: "order.processed", UserID: testUserID, Payload: []byte(`{"order":"1"}`)}
+				close(ch)
+				return ch, nil
+			})
+			req := httptest.NewRequest(http.MethodGet, "/api/user/events", nil)
+			if tt.lastEventID != "" {
+				req.Header.Set(lastEventIDKey, tt.lastEventID)
+			}
+			req = withUser(req)
+			rr := httptest.NewRecorder()
+			EventsHandler(es).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if ct := rr.Header().Get(contentTypeKey); ct != "text/event-stream" {
+				t.Fatalf("Content-Type = %q", ct)
+			}
+			if rr.Body.String() != tt.wantBody {
+				t.Fatalf("body = %q, want %q", rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
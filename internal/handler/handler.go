@@ -5,55 +5,109 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
 	mw "github.com/IvanOplesnin/gofermart.git/internal/handler/middleware"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/openapi"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
 	"github.com/go-chi/chi/v5"
 )
 
 const (
 	contentTypeKey       = "Content-Type"
-	acceptEncodingKey    = "Accept-Encoding"
-	contentEncodingKey   = "Content-Encoding"
 	applicationJSONValue = "application/json"
 	textPlainValue       = "text/plain"
 	tokenCookieName      = "token"
+
+	openAPIPath = "/api/openapi.json"
+	docsPath    = "/api/docs"
+
+	// compressMinSize — ответы меньше этого размера не сжимаются.
+	compressMinSize = 1024
 )
 
 type HandlerDeps struct {
-	Reqistrar    Registrar
-	Auther       Auther
-	TokenChecker mw.TokenChecker
-	Ordered      Ordered
-	Balancer     Balancer
-	Withdrawer   Withdrawer
+	Reqistrar    service.Registrar
+	Auther       service.Auther
+	TokenChecker service.TokenChecker
+	Ordered      service.Ordered
+	Balancer     service.Balancer
+	Withdrawer   service.Withdrawer
+	Webhooks     service.Webhooks
+	Events       service.EventStreamer
+
+	// ValidateRequests включает проверку запросов по OpenAPI-схеме.
+	ValidateRequests bool
+
+	CORS    config.CORS
+	Cookies CookieOptions
 }
 
 func InitHandler(deps HandlerDeps) *chi.Mux {
 	router := chi.NewRouter()
+	router.Use(mw.RequestID)
 	router.Use(mw.WithLogging)
+	router.Use(mw.CORS(deps.CORS))
+	router.Use(mw.Compress(compressMinSize))
+
+	// withBody — цепочка для маршрутов с телом: сначала распаковка, потом
+	// проверка по схеме, чтобы сжатые тела тоже проверялись. На защищённых
+	// маршрутах она стоит после CheckCookie: без токена ответ — 401, а не 400.
+	withBody := []func(http.Handler) http.Handler{mw.Decompress}
+	if deps.ValidateRequests {
+		doc, err := openapi.Load()
+		if err != nil {
+			logger.Log.Errorf("request validation disabled: %s", err.Error())
+		} else {
+			withBody = append(withBody, openapi.Validator(doc))
+		}
+	}
 
-	router.Post("/api/user/register", Register(deps.Reqistrar))
-	router.Post("/api/user/login", Login(deps.Auther))
+	router.Get(openAPIPath, openapi.SpecHandler)
+	router.Get(docsPath, openapi.DocsHandler(openAPIPath))
+
+	router.With(withBody...).Post("/api/user/register", Register(deps.Reqistrar, deps.Cookies))
+	router.With(withBody...).Post("/api/user/login", Login(deps.Auther, deps.Cookies))
 
 	router.Group(func(pr chi.Router) {
 		pr.Use(mw.CheckCookie(deps.TokenChecker))
-		pr.Post("/api/user/orders", AddOrderHandler(deps.Ordered))
+		if deps.Cookies.CSRF {
+			pr.Use(mw.CSRF(deps.Cookies.Cookie))
+		}
+		pr.With(withBody...).Post("/api/user/orders", AddOrderHandler(deps.Ordered))
 		pr.Get("/api/user/orders", OrdersHandler(deps.Ordered))
 		pr.Get("/api/user/balance", BalanceHandler(deps.Balancer))
-		pr.Post("/api/user/balance/withdraw", WithdrawHandler(deps.Withdrawer))
+		pr.With(withBody...).Post("/api/user/balance/withdraw", WithdrawHandler(deps.Withdrawer))
 		pr.Get("/api/user/withdrawals", ListWithdrawHandler(deps.Withdrawer))
+		pr.With(withBody...).Post("/api/user/webhooks", CreateWebhookHandler(deps.Webhooks))
+		pr.Get("/api/user/webhooks", ListWebhooksHandler(deps.Webhooks))
+		pr.Delete("/api/user/webhooks/{id}", DeleteWebhookHandler(deps.Webhooks))
+		pr.Get("/api/user/webhooks/{id}/deliveries", WebhookDeliveriesHandler(deps.Webhooks))
+		pr.Get("/api/user/events", EventsHandler(deps.Events))
 	})
 
 	return router
 }
 
 func UserIDFromCtx(ctx context.Context) (int32, error) {
-	claims, ok := ctx.Value(mw.ClaimsKey).(mw.Claims)
+	userID, ok := service.UserID(ctx)
 	if !ok {
 		return 0, errors.New("user id not found")
 	}
-	return claims.UserID, nil
+	return userID, nil
+}
+
+// requestUserID возвращает пользователя, которого положил в контекст mw.CheckCookie.
+func requestUserID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	userID, err := UserIDFromCtx(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return 0, false
+	}
+	return userID, true
 }
 
 type RFC3339Time time.Time
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func FuzzRFC3339Time(f *testing.F) {
+	for _, seed := range []string{
+		`"2026-02-19T12:00:00Z"`,
+		`"2026-02-19T12:00:00.123456789+03:00"`,
+		`"2026-02-19T12:00:00-23:59"`,
+		`"0000-01-01T00:00:00Z"`,
+		`null`,
+		`""`,
+		`"2026-02-30T12:00:00Z"`,
+		`"2026-02-19 12:00:00"`,
+		`1771502400`,
+		`"2026-02-19T24:00:00Z"`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var got RFC3339Time
+		if err := json.Unmarshal(data, &got); err != nil {
+			return
+		}
+		// После одной сериализации значение становится неподвижной точкой:
+		// дробная часть секунд отбрасывается, а зона сохраняется.
+		first, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", time.Time(got), err)
+		}
+		var again RFC3339Time
+		if err := json.Unmarshal(first, &again); err != nil {
+			t.Fatalf("Unmarshal(%s) of own output: %v", first, err)
+		}
+		second, err := json.Marshal(again)
+		if err != nil || !bytes.Equal(first, second) {
+			t.Fatalf("round trip %s -> %s, %v", first, second, err)
+		}
+		if !time.Time(again).Equal(time.Time(got).Truncate(time.Second)) && !time.Time(got).IsZero() {
+			t.Fatalf("round trip changed the instant: %v -> %v", time.Time(got), time.Time(again))
+		}
+	})
+}
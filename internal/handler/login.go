@@ -1,60 +1,42 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
-)
-
-type Auther interface {
-	Auth(ctx context.Context, login string, password string) (string, error)
-}
 
-var ErrUserNotFound = errors.New("user not found")
-var ErrInvalidPassword = errors.New("invalid password")
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+)
 
 type AuthRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
 }
 
-func Login(auther Auther) http.HandlerFunc {
+func Login(auther service.Auther, cookies CookieOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get(contentTypeKey) != applicationJSONValue {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidContentType, "expected Content-Type application/json")
 			return
 		}
 		raw, err := io.ReadAll(r.Body)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "failed to read request body")
 			return
 		}
 		var authReq AuthRequest
 		if err := json.Unmarshal(raw, &authReq); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "invalid json body")
 			return
 		}
 		ctx := r.Context()
 		token, err := auther.Auth(ctx, authReq.Login, authReq.Password)
-		if errors.Is(err, ErrInvalidPassword) || errors.Is(err, ErrUserNotFound) {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
-		if token != "" {
-			http.SetCookie(w, &http.Cookie{
-				Name:     tokenCookieName,
-				Value:    token,
-				Path:     "/api",
-				HttpOnly: true,
-				SameSite: http.SameSiteLaxMode,
-			})
-		}
+		setAuthCookies(w, cookies, token)
 		w.WriteHeader(http.StatusOK)
 	}
 }
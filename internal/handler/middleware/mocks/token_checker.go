@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./internal/handler/middleware/mw_check_cookie.go
+// Source: github.com/IvanOplesnin/gofermart.git/internal/service (interfaces: TokenChecker)
 //
 // Generated by this command:
 //
-//	mockgen -source=./internal/handler/middleware/mw_check_cookie.go -destination=./internal/handler/middleware/mocks/token_checker.go -package=mocks
+//	mockgen -destination=./internal/handler/middleware/mocks/token_checker.go -package=mocks github.com/IvanOplesnin/gofermart.git/internal/service TokenChecker
 //
 
 // Package mocks is a generated GoMock package.
@@ -13,7 +13,6 @@ import (
 	context "context"
 	reflect "reflect"
 
-	middleware "github.com/IvanOplesnin/gofermart.git/internal/handler/middleware"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,10 +41,10 @@ func (m *MockTokenChecker) EXPECT() *MockTokenCheckerMockRecorder {
 }
 
 // CheckToken mocks base method.
-func (m *MockTokenChecker) CheckToken(ctx context.Context, token string) (middleware.Claims, error) {
+func (m *MockTokenChecker) CheckToken(ctx context.Context, token string) (int32, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CheckToken", ctx, token)
-	ret0, _ := ret[0].(middleware.Claims)
+	ret0, _ := ret[0].(int32)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1,58 +1,46 @@
 package mw
 
 import (
-	"context"
-	"errors"
 	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 )
 
 const tokenCookieName = "token"
 
-type contextKey int
-
-const ClaimsKey contextKey = iota
-
-var ErrNotUserFound = errors.New("user not found")
-var ErrInvalidPassword = errors.New("invalid password")
-var ErrInvalidToken = errors.New("invalid token")
-
-type TokenChecker interface {
-	CheckToken(ctx context.Context, token string) (Claims, error)
-}
-
-type Claims struct {
-	UserID int32 `json:"user_id"`
-}
-
-func CheckCookie(cht TokenChecker) func(http.Handler) http.Handler {
+func CheckCookie(cht service.TokenChecker) func(http.Handler) http.Handler {
 	CheckToken := func(next http.Handler) http.Handler {
 		checkCookieFunc := func(w http.ResponseWriter, r *http.Request) {
-			var token string
 			ctx := r.Context()
+			// r.Cookie возвращает только http.ErrNoCookie.
 			c, err := r.Cookie(tokenCookieName)
-			if err == nil && c != nil {
-				token = c.Value
-			} else if err != nil && !errors.Is(err, http.ErrNoCookie) {
-				http.Error(w, "failed to read cookie", http.StatusInternalServerError)
+			if err != nil || c.Value == "" {
+				writeProblem(w, r, gophermart.ErrNoToken)
 				return
 			}
-			if token != "" {
-				claims, err := cht.CheckToken(ctx, token)
-				if err == nil {
-					ctx := context.WithValue(ctx, ClaimsKey, claims)
-					next.ServeHTTP(w, r.WithContext(ctx))
-					return
-				} else if errors.Is(err, ErrNotUserFound) || errors.Is(err, ErrInvalidPassword) || errors.Is(err, ErrInvalidToken) {
-					http.Error(w, "invalid token", http.StatusUnauthorized)
-					return
-				} else {
-					http.Error(w, "failed to check token", http.StatusInternalServerError)
-					return
-				}
+			userID, err := cht.CheckToken(ctx, c.Value)
+			if err != nil {
+				writeProblem(w, r, err)
+				return
 			}
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			ctx = service.WithUserID(ctx, userID)
+			ctx = logger.WithField(ctx, "user_id", userID)
+			logger.SetAccessField(ctx, "user_id", userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		}
 		return http.HandlerFunc(checkCookieFunc)
 	}
 	return CheckToken
 }
+
+// writeProblem отвечает ошибкой по общей таблице problem.FromError.
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	p := problem.FromError(err)
+	if p.Status >= http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Errorf("%s %s: %s", r.Method, r.URL.Path, err.Error())
+	}
+	problem.Write(w, r, p)
+}
@@ -3,11 +3,14 @@ package mw
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	"go.uber.org/mock/gomock"
 )
 
@@ -16,7 +19,7 @@ func TestCheckCookieMiddleware(t *testing.T) {
 		statusCode int
 		bodySubstr string
 		nextCalled bool
-		claims     *Claims 
+		claims     *gophermart.Claims
 	}
 
 	tests := []struct {
@@ -62,25 +65,25 @@ func TestCheckCookieMiddleware(t *testing.T) {
 			setupMock: func(m *MockTokenChecker) {
 				m.EXPECT().
 					CheckToken(gomock.Any(), "abc").
-					Return(Claims{UserID: 42}, nil).
+					Return(int32(42), nil).
 					Times(1)
 			},
 			want: want{
 				statusCode: http.StatusOK,
 				bodySubstr: "ok",
 				nextCalled: true,
-				claims:     &Claims{UserID: 42},
+				claims:     &gophermart.Claims{UserID: 42},
 			},
 		},
 		{
-			name: "ErrNotUserFound -> 401 invalid token",
+			name: "wrapped ErrInvalidToken -> 401 invalid token",
 			setCookie: func(r *http.Request) {
 				r.AddCookie(&http.Cookie{Name: tokenCookieName, Value: "abc"})
 			},
 			setupMock: func(m *MockTokenChecker) {
 				m.EXPECT().
 					CheckToken(gomock.Any(), "abc").
-					Return(Claims{}, ErrNotUserFound).
+					Return(int32(0), fmt.Errorf("service.CheckToken: %w", gophermart.ErrInvalidToken)).
 					Times(1)
 			},
 			want: want{
@@ -91,19 +94,37 @@ func TestCheckCookieMiddleware(t *testing.T) {
 			},
 		},
 		{
-			name: "ErrInvalidPassword -> 401 invalid token",
+			name: "ErrUserNotFound -> 401 invalid credentials",
 			setCookie: func(r *http.Request) {
 				r.AddCookie(&http.Cookie{Name: tokenCookieName, Value: "abc"})
 			},
 			setupMock: func(m *MockTokenChecker) {
 				m.EXPECT().
 					CheckToken(gomock.Any(), "abc").
-					Return(Claims{}, ErrInvalidPassword).
+					Return(int32(0), gophermart.ErrUserNotFound).
 					Times(1)
 			},
 			want: want{
 				statusCode: http.StatusUnauthorized,
-				bodySubstr: "invalid token",
+				bodySubstr: "invalid_credentials",
+				nextCalled: false,
+				claims:     nil,
+			},
+		},
+		{
+			name: "ErrInvalidPassword -> 401 invalid credentials",
+			setCookie: func(r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: tokenCookieName, Value: "abc"})
+			},
+			setupMock: func(m *MockTokenChecker) {
+				m.EXPECT().
+					CheckToken(gomock.Any(), "abc").
+					Return(int32(0), gophermart.ErrInvalidPassword).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				bodySubstr: "invalid_credentials",
 				nextCalled: false,
 				claims:     nil,
 			},
@@ -116,7 +137,7 @@ func TestCheckCookieMiddleware(t *testing.T) {
 			setupMock: func(m *MockTokenChecker) {
 				m.EXPECT().
 					CheckToken(gomock.Any(), "abc").
-					Return(Claims{}, ErrInvalidToken).
+					Return(int32(0), gophermart.ErrInvalidToken).
 					Times(1)
 			},
 			want: want{
@@ -127,19 +148,19 @@ func TestCheckCookieMiddleware(t *testing.T) {
 			},
 		},
 		{
-			name: "unexpected error -> 500 failed to check token",
+			name: "unexpected error -> 500 internal error",
 			setCookie: func(r *http.Request) {
 				r.AddCookie(&http.Cookie{Name: tokenCookieName, Value: "abc"})
 			},
 			setupMock: func(m *MockTokenChecker) {
 				m.EXPECT().
 					CheckToken(gomock.Any(), "abc").
-					Return(Claims{}, errors.New("db down")).
+					Return(int32(0), errors.New("db down")).
 					Times(1)
 			},
 			want: want{
 				statusCode: http.StatusInternalServerError,
-				bodySubstr: "failed to check token",
+				bodySubstr: "internal_error",
 				nextCalled: false,
 				claims:     nil,
 			},
@@ -162,22 +183,16 @@ func TestCheckCookieMiddleware(t *testing.T) {
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				nextCalled = true
 
+				userID, ok := service.UserID(r.Context())
 				if tt.want.claims != nil {
-					v := r.Context().Value(ClaimsKey)
-					if v == nil {
-						t.Fatalf("expected claims in context, got nil")
-					}
-					got, ok := v.(Claims)
 					if !ok {
-						t.Fatalf("expected Claims type in context, got %T", v)
-					}
-					if got.UserID != tt.want.claims.UserID {
-						t.Fatalf("expected UserID=%d, got %d", tt.want.claims.UserID, got.UserID)
+						t.Fatalf("expected user id in context, got none")
 					}
-				} else {
-					if v := r.Context().Value(ClaimsKey); v != nil {
-						t.Fatalf("expected no claims in context, got %v", v)
+					if userID != tt.want.claims.UserID {
+						t.Fatalf("expected UserID=%d, got %d", tt.want.claims.UserID, userID)
 					}
+				} else if ok {
+					t.Fatalf("expected no user id in context, got %d", userID)
 				}
 
 				w.WriteHeader(http.StatusOK)
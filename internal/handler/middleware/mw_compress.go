@@ -0,0 +1,223 @@
+package mw
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+	l "github.com/IvanOplesnin/gofermart.git/internal/logger"
+)
+
+const (
+	acceptEncodingKey  = "Accept-Encoding"
+	contentEncodingKey = "Content-Encoding"
+	contentLengthKey   = "Content-Length"
+	contentTypeKey     = "Content-Type"
+	varyKey            = "Vary"
+	gzipEncoding       = "gzip"
+
+	// maxDecompressedBody — предел распакованного тела: несколько килобайт gzip
+	// могут развернуться в гигабайты.
+	maxDecompressedBody = 1 << 20
+)
+
+// compressibleTypes — типы ответов, которые имеет смысл сжимать.
+var compressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any { return gzip.NewWriter(io.Discard) },
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	minSize int
+
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (c *compressWriter) WriteHeader(statusCode int) {
+	if c.status == 0 {
+		c.status = statusCode
+	}
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	if c.gz != nil {
+		return c.gz.Write(b)
+	}
+	if c.decided {
+		return c.ResponseWriter.Write(b)
+	}
+	c.buf = append(c.buf, b...)
+	if len(c.buf) >= c.minSize {
+		if err := c.flushBuffer(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flushBuffer решает, сжимать ли ответ, и отправляет накопленные байты клиенту.
+func (c *compressWriter) flushBuffer(large bool) error {
+	c.decided = true
+	h := c.Header()
+	if large && h.Get(contentEncodingKey) == "" && isCompressible(h.Get(contentTypeKey)) {
+		h.Del(contentLengthKey)
+		h.Set(contentEncodingKey, gzipEncoding)
+		c.ResponseWriter.WriteHeader(c.status)
+
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(c.ResponseWriter)
+		c.gz = gz
+		_, err := gz.Write(c.buf)
+		c.buf = nil
+		return err
+	}
+	c.ResponseWriter.WriteHeader(c.status)
+	_, err := c.ResponseWriter.Write(c.buf)
+	c.buf = nil
+	return err
+}
+
+// FlushError отправляет клиенту всё накопленное. Потоковый ответ, сброшенный
+// до набора minSize, уходит без сжатия.
+func (c *compressWriter) FlushError() error {
+	if !c.decided {
+		if c.status == 0 {
+			c.status = http.StatusOK
+		}
+		if err := c.flushBuffer(false); err != nil {
+			return err
+		}
+	}
+	if c.gz != nil {
+		if err := c.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(c.ResponseWriter).Flush()
+}
+
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func (c *compressWriter) Close() error {
+	if !c.decided {
+		if c.status == 0 {
+			return nil
+		}
+		return c.flushBuffer(false)
+	}
+	if c.gz != nil {
+		err := c.gz.Close()
+		gzipWriterPool.Put(c.gz)
+		c.gz = nil
+		return err
+	}
+	return nil
+}
+
+// Compress сжимает gzip'ом JSON-ответы размером от minSize байт, если клиент это поддерживает.
+func Compress(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(varyKey, acceptEncodingKey)
+			if !acceptsGzip(r.Header.Get(acceptEncodingKey)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, minSize: minSize}
+			defer func() {
+				if err := cw.Close(); err != nil {
+					l.FromContext(r.Context()).Errorf("mw.Compress close error: %s", err.Error())
+				}
+			}()
+			next.ServeHTTP(cw, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Decompress прозрачно распаковывает тело запроса с Content-Encoding: gzip.
+func Decompress(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		encoding := strings.ToLower(strings.TrimSpace(r.Header.Get(contentEncodingKey)))
+		switch encoding {
+		case "", "identity":
+			next.ServeHTTP(w, r)
+			return
+		case gzipEncoding:
+		default:
+			problem.Write(w, r, problem.New(http.StatusUnsupportedMediaType, apierror.CodeUnsupportedEncoding, "unsupported Content-Encoding: "+encoding))
+			return
+		}
+
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			problem.Write(w, r, problem.New(http.StatusBadRequest, apierror.CodeInvalidBody, "invalid gzip body"))
+			return
+		}
+		defer gz.Close()
+
+		// Тела запросов маленькие: распаковываем целиком, чтобы превышение
+		// предела стало 413 до обработчика, а не ошибкой чтения в нём.
+		body, err := io.ReadAll(io.LimitReader(gz, maxDecompressedBody+1))
+		if err != nil {
+			problem.Write(w, r, problem.New(http.StatusBadRequest, apierror.CodeInvalidBody, "invalid gzip body"))
+			return
+		}
+		if len(body) > maxDecompressedBody {
+			problem.Write(w, r, problem.New(http.StatusRequestEntityTooLarge, apierror.CodeBodyTooLarge, "decompressed body is too large"))
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.Header.Del(contentEncodingKey)
+		r.Header.Set(contentLengthKey, strconv.Itoa(len(body)))
+		r.ContentLength = int64(len(body))
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), gzipEncoding) && strings.TrimSpace(name) != "*" {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		if q == "q=0" || q == "q=0.0" || q == "q=0.00" || q == "q=0.000" {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func isCompressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	for _, t := range compressibleTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
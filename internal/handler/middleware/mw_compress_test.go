@@ -0,0 +1,198 @@
+package mw
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressMiddleware(t *testing.T) {
+	large := "[" + strings.Repeat(`{"number":"12345678903"},`, 100) + "{}]"
+
+	type want struct {
+		statusCode int
+		gzipped    bool
+		body       string
+	}
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		status         int
+		body           string
+		want           want
+	}{
+		{
+			name:           "large json with gzip accepted -> compressed",
+			acceptEncoding: "gzip, deflate",
+			contentType:    "application/json",
+			status:         http.StatusOK,
+			body:           large,
+			want:           want{statusCode: http.StatusOK, gzipped: true, body: large},
+		},
+		{
+			name:           "small json -> not compressed",
+			acceptEncoding: "gzip",
+			contentType:    "application/json",
+			status:         http.StatusOK,
+			body:           `{"current":1}`,
+			want:           want{statusCode: http.StatusOK, gzipped: false, body: `{"current":1}`},
+		},
+		{
+			name:           "client without gzip -> not compressed",
+			acceptEncoding: "",
+			contentType:    "application/json",
+			status:         http.StatusOK,
+			body:           large,
+			want:           want{statusCode: http.StatusOK, gzipped: false, body: large},
+		},
+		{
+			name:           "gzip with q=0 -> not compressed",
+			acceptEncoding: "gzip;q=0",
+			contentType:    "application/json",
+			status:         http.StatusOK,
+			body:           large,
+			want:           want{statusCode: http.StatusOK, gzipped: false, body: large},
+		},
+		{
+			name:           "non json content -> not compressed",
+			acceptEncoding: "gzip",
+			contentType:    "text/plain",
+			status:         http.StatusOK,
+			body:           large,
+			want:           want{statusCode: http.StatusOK, gzipped: false, body: large},
+		},
+		{
+			name:           "no content -> status preserved",
+			acceptEncoding: "gzip",
+			status:         http.StatusNoContent,
+			want:           want{statusCode: http.StatusNoContent, gzipped: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set(contentTypeKey, tt.contentType)
+				}
+				w.WriteHeader(tt.status)
+				if tt.body != "" {
+					_, _ = w.Write([]byte(tt.body))
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/any", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(acceptEncodingKey, tt.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+			Compress(1024)(next).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want.statusCode {
+				t.Fatalf("expected status %d, got %d", tt.want.statusCode, rr.Code)
+			}
+			gotGzip := rr.Header().Get(contentEncodingKey) == gzipEncoding
+			if gotGzip != tt.want.gzipped {
+				t.Fatalf("expected gzipped=%v, got %v", tt.want.gzipped, gotGzip)
+			}
+
+			body := rr.Body.Bytes()
+			if gotGzip {
+				gz, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				body, err = io.ReadAll(gz)
+				if err != nil {
+					t.Fatalf("read gzip body: %v", err)
+				}
+			}
+			if string(body) != tt.want.body {
+				t.Fatalf("expected body %q, got %q", tt.want.body, string(body))
+			}
+		})
+	}
+}
+
+func TestCompressMiddleware_Flush(t *testing.T) {
+	rr := httptest.NewRecorder()
+	var afterFlush string
+	h := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentTypeKey, "text/event-stream")
+		_, _ = io.WriteString(w, "data: 1\n\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush: %v", err)
+		}
+		afterFlush = rr.Body.String()
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(acceptEncodingKey, gzipEncoding)
+	h.ServeHTTP(rr, req)
+
+	if afterFlush != "data: 1\n\n" {
+		t.Fatalf("body after flush = %q, want the buffered event", afterFlush)
+	}
+	if !rr.Flushed || rr.Header().Get(contentEncodingKey) != "" {
+		t.Fatalf("flushed=%v encoding=%q, want flushed and not compressed", rr.Flushed, rr.Header().Get(contentEncodingKey))
+	}
+}
+
+func TestDecompressMiddleware(t *testing.T) {
+	gzipBody := func(s string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte(s))
+		_ = gz.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "plain body passes through", encoding: "", body: []byte("12345678903"), wantStatus: http.StatusOK, wantBody: "12345678903"},
+		{name: "gzip body is decompressed", encoding: "gzip", body: gzipBody("12345678903"), wantStatus: http.StatusOK, wantBody: "12345678903"},
+		{name: "broken gzip -> 400", encoding: "gzip", body: []byte("not gzip"), wantStatus: http.StatusBadRequest},
+		{name: "unsupported encoding -> 415", encoding: "br", body: []byte("x"), wantStatus: http.StatusUnsupportedMediaType},
+		{name: "body at limit passes", encoding: "gzip", body: gzipBody(strings.Repeat("0", maxDecompressedBody)), wantStatus: http.StatusOK},
+		{name: "compression bomb -> 413", encoding: "gzip", body: gzipBody(strings.Repeat("0", 64*maxDecompressedBody)), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "truncated gzip -> 400", encoding: "gzip", body: gzipBody("12345678903")[:15], wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				raw, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("read body: %v", err)
+				}
+				got = string(raw)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/orders", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set(contentEncodingKey, tt.encoding)
+			}
+			rr := httptest.NewRecorder()
+			Decompress(next).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantBody != "" && got != tt.wantBody {
+				t.Fatalf("expected body %q, got %q", tt.wantBody, got)
+			}
+		})
+	}
+}
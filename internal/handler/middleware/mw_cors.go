@@ -0,0 +1,94 @@
+package mw
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+)
+
+const (
+	originKey                  = "Origin"
+	allowOriginKey             = "Access-Control-Allow-Origin"
+	allowCredentialsKey        = "Access-Control-Allow-Credentials"
+	allowMethodsKey            = "Access-Control-Allow-Methods"
+	allowHeadersKey            = "Access-Control-Allow-Headers"
+	exposeHeadersKey           = "Access-Control-Expose-Headers"
+	maxAgeKey                  = "Access-Control-Max-Age"
+	requestMethodKey           = "Access-Control-Request-Method"
+	wildcardOrigin             = "*"
+	corsExposedHeadersDefaults = "X-Request-ID, X-CSRF-Token"
+)
+
+// CORS разрешает браузерам с указанных origin'ов обращаться к API и отвечает на preflight-запросы.
+// При пустом списке AllowedOrigins middleware ничего не делает. Origin'ам,
+// которые разрешены только через "*", отвечаем "*" без credentials: иначе
+// любой сайт читал бы данные пользователя от его имени.
+func CORS(cfg config.CORS) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(cfg.AllowedOrigins))
+	allowAny := false
+	for _, o := range cfg.AllowedOrigins {
+		if o == wildcardOrigin {
+			allowAny = true
+			continue
+		}
+		allowed[strings.TrimRight(o, "/")] = struct{}{}
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		if len(cfg.AllowedOrigins) == 0 {
+			return next
+		}
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get(originKey)
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Add(varyKey, originKey)
+
+			_, listed := allowed[origin]
+			ok := listed || allowAny
+			preflight := r.Method == http.MethodOptions && r.Header.Get(requestMethodKey) != ""
+
+			if !ok {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// С credentials браузер не принимает "*", поэтому явно указанный
+			// origin отражаем как есть.
+			if listed {
+				h.Set(allowOriginKey, origin)
+				if cfg.AllowCredentials {
+					h.Set(allowCredentialsKey, "true")
+				}
+			} else {
+				h.Set(allowOriginKey, wildcardOrigin)
+			}
+
+			if preflight {
+				h.Add(varyKey, requestMethodKey)
+				h.Set(allowMethodsKey, methods)
+				h.Set(allowHeadersKey, headers)
+				if cfg.MaxAge > 0 {
+					h.Set(maxAgeKey, maxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set(exposeHeadersKey, corsExposedHeadersDefaults)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,198 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := config.CORS{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Content-Type", "X-CSRF-Token"},
+		MaxAge:           10 * time.Minute,
+	}
+
+	type want struct {
+		statusCode  int
+		allowOrigin string
+		nextCalled  bool
+		maxAge      string
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		origin  string
+		reqMeth string
+		want    want
+	}{
+		{
+			name:   "no origin -> passthrough",
+			method: http.MethodGet,
+			want:   want{statusCode: http.StatusOK, nextCalled: true},
+		},
+		{
+			name:   "allowed origin -> headers set",
+			method: http.MethodGet,
+			origin: "https://app.example.com",
+			want:   want{statusCode: http.StatusOK, allowOrigin: "https://app.example.com", nextCalled: true},
+		},
+		{
+			name:   "foreign origin -> no cors headers",
+			method: http.MethodGet,
+			origin: "https://evil.example.com",
+			want:   want{statusCode: http.StatusOK, nextCalled: true},
+		},
+		{
+			name:    "allowed preflight -> 204",
+			method:  http.MethodOptions,
+			origin:  "https://app.example.com",
+			reqMeth: http.MethodPost,
+			want:    want{statusCode: http.StatusNoContent, allowOrigin: "https://app.example.com", maxAge: "600"},
+		},
+		{
+			name:    "foreign preflight -> 403",
+			method:  http.MethodOptions,
+			origin:  "https://evil.example.com",
+			reqMeth: http.MethodPost,
+			want:    want{statusCode: http.StatusForbidden},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextCalled := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/api/user/orders", nil)
+			if tt.origin != "" {
+				req.Header.Set(originKey, tt.origin)
+			}
+			if tt.reqMeth != "" {
+				req.Header.Set(requestMethodKey, tt.reqMeth)
+			}
+			rr := httptest.NewRecorder()
+			CORS(cfg)(next).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want.statusCode {
+				t.Fatalf("expected status %d, got %d", tt.want.statusCode, rr.Code)
+			}
+			if got := rr.Header().Get(allowOriginKey); got != tt.want.allowOrigin {
+				t.Fatalf("expected %s %q, got %q", allowOriginKey, tt.want.allowOrigin, got)
+			}
+			if tt.want.allowOrigin != "" && rr.Header().Get(allowCredentialsKey) != "true" {
+				t.Fatalf("expected %s=true", allowCredentialsKey)
+			}
+			if got := rr.Header().Get(maxAgeKey); got != tt.want.maxAge {
+				t.Fatalf("expected %s %q, got %q", maxAgeKey, tt.want.maxAge, got)
+			}
+			if nextCalled != tt.want.nextCalled {
+				t.Fatalf("expected nextCalled=%v, got %v", tt.want.nextCalled, nextCalled)
+			}
+		})
+	}
+}
+
+// С "*" в списке чужой origin получает "*" без credentials, а явно
+// указанный — свой origin с credentials.
+func TestCORSMiddleware_WildcardWithCredentials(t *testing.T) {
+	cfg := config.CORS{
+		AllowedOrigins:   []string{"https://app.example.com", "*"},
+		AllowCredentials: true,
+	}
+	tests := []struct {
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{origin: "https://app.example.com", allowOrigin: "https://app.example.com", credentials: "true"},
+		{origin: "https://evil.example.com", allowOrigin: "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			req := httptest.NewRequest(http.MethodGet, "/api/user/balance", nil)
+			req.Header.Set(originKey, tt.origin)
+			rr := httptest.NewRecorder()
+			CORS(cfg)(next).ServeHTTP(rr, req)
+
+			if got := rr.Header().Get(allowOriginKey); got != tt.allowOrigin {
+				t.Fatalf("%s = %q, want %q", allowOriginKey, got, tt.allowOrigin)
+			}
+			if got := rr.Header().Get(allowCredentialsKey); got != tt.credentials {
+				t.Fatalf("%s = %q, want %q", allowCredentialsKey, got, tt.credentials)
+			}
+		})
+	}
+}
+
+func TestCSRFMiddleware(t *testing.T) {
+	cfg := config.Cookie{SameSite: "strict", Secure: true}
+
+	tests := []struct {
+		name       string
+		method     string
+		cookie     string
+		header     string
+		wantStatus int
+		wantIssued bool
+		wantHeader string
+	}{
+		{name: "get without cookie -> token issued", method: http.MethodGet, wantStatus: http.StatusOK, wantIssued: true},
+		{name: "get with cookie -> token echoed in header", method: http.MethodGet, cookie: "t1", wantStatus: http.StatusOK, wantHeader: "t1"},
+		{name: "post without header -> 403", method: http.MethodPost, cookie: "t1", wantStatus: http.StatusForbidden},
+		{name: "post without cookie -> 403", method: http.MethodPost, header: "t1", wantStatus: http.StatusForbidden},
+		{name: "post with mismatched token -> 403", method: http.MethodPost, cookie: "t1", header: "t2", wantStatus: http.StatusForbidden},
+		{name: "post with matching token -> ok", method: http.MethodPost, cookie: "t1", header: "t1", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/api/user/orders", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			CSRF(cfg)(next).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			var issued *http.Cookie
+			for _, c := range rr.Result().Cookies() {
+				if c.Name == CSRFCookieName {
+					issued = c
+				}
+			}
+			if (issued != nil) != tt.wantIssued {
+				t.Fatalf("expected issued=%v, got %v", tt.wantIssued, issued != nil)
+			}
+			if issued != nil && (!issued.Secure || issued.HttpOnly || issued.SameSite != http.SameSiteStrictMode) {
+				t.Fatalf("unexpected csrf cookie attributes: %+v", issued)
+			}
+			wantHeader := tt.wantHeader
+			if issued != nil {
+				wantHeader = issued.Value
+			}
+			if got := rr.Header().Get(CSRFHeader); got != wantHeader {
+				t.Fatalf("%s = %q, want %q", CSRFHeader, got, wantHeader)
+			}
+		})
+	}
+}
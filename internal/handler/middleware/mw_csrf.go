@@ -0,0 +1,76 @@
+package mw
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+	l "github.com/IvanOplesnin/gofermart.git/internal/logger"
+)
+
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeader     = "X-CSRF-Token"
+	cookiePath     = "/api"
+)
+
+// NewCookie собирает cookie с атрибутами из конфига.
+func NewCookie(cfg config.Cookie, name string, value string, httpOnly bool) *http.Cookie {
+	c := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     cookiePath,
+		Domain:   cfg.Domain,
+		Secure:   cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cfg.SameSiteMode(),
+	}
+	if cfg.MaxAge > 0 {
+		c.MaxAge = int(cfg.MaxAge.Seconds())
+	}
+	return c
+}
+
+// IssueCSRFToken выдаёт новый CSRF-токен: в cookie и в заголовке X-CSRF-Token
+// ответа. Фронтенд с того же origin может прочитать cookie, а с другого —
+// только заголовок (CORS открывает его через Access-Control-Expose-Headers).
+// Значение он дублирует в заголовке запроса (double-submit).
+func IssueCSRFToken(w http.ResponseWriter, cfg config.Cookie) {
+	token := l.NewID()
+	http.SetCookie(w, NewCookie(cfg, CSRFCookieName, token, false))
+	w.Header().Set(CSRFHeader, token)
+}
+
+// CSRF проверяет double-submit токен для изменяющих запросов. На безопасных
+// выдаёт токен, если у клиента его ещё нет, а иначе возвращает текущий
+// в заголовке X-CSRF-Token: фронтенд с другого origin не видит cookie и
+// после перезагрузки страницы узнаёт токен только так.
+func CSRF(cfg config.Cookie) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			c, err := r.Cookie(CSRFCookieName)
+			hasCookie := err == nil && c.Value != ""
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				if hasCookie {
+					w.Header().Set(CSRFHeader, c.Value)
+				} else {
+					IssueCSRFToken(w, cfg)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := r.Header.Get(CSRFHeader)
+			if !hasCookie || header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(c.Value)) != 1 {
+				problem.Write(w, r, problem.New(http.StatusForbidden, apierror.CodeInvalidCSRFToken, "missing or invalid csrf token"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -1,7 +1,5 @@
 package mw
 
-
-
 import (
 	"net/http"
 	"time"
@@ -27,7 +25,7 @@ func (r *loggingResponseWriter) Write(b []byte) (int, error) {
 		r.WriteHeader(http.StatusOK)
 	}
 	size, err := r.ResponseWriter.Write(b)
-	r.responseData.size += size 
+	r.responseData.size += size
 	return size, err
 }
 
@@ -36,6 +34,10 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func WithLogging(next http.Handler) http.Handler {
 	logFn := func(wr http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -51,10 +53,11 @@ func WithLogging(next http.Handler) http.Handler {
 			ResponseWriter: wr,
 			responseData:   responseData,
 		}
-		next.ServeHTTP(&lw, r)
+		ctx, access := l.WithAccessFields(r.Context())
+		next.ServeHTTP(&lw, r.WithContext(ctx))
 		duration := time.Since(start)
 
-		l.Log.WithFields(logrus.Fields{
+		l.FromContext(ctx).WithFields(access.Fields()).WithFields(logrus.Fields{
 			"uri":      uri,
 			"method":   method,
 			"status":   responseData.status,
@@ -0,0 +1,46 @@
+package mw
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	l "github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/mock/gomock"
+)
+
+// Строка журнала пишется до CheckCookie и после ответа, но содержит user_id.
+func TestWithLogging_UserID(t *testing.T) {
+	var buf bytes.Buffer
+	out, formatter := l.Log.Out, l.Log.Formatter
+	l.Log.SetOutput(&buf)
+	l.Log.SetFormatter(&logrus.JSONFormatter{})
+	t.Cleanup(func() {
+		l.Log.SetOutput(out)
+		l.Log.SetFormatter(formatter)
+	})
+
+	ctrl := gomock.NewController(t)
+	tc := NewMockTokenChecker(ctrl)
+	tc.EXPECT().CheckToken(gomock.Any(), "abc").Return(int32(7), nil)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := RequestID(WithLogging(CheckCookie(tc)(next)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
+	req.AddCookie(&http.Cookie{Name: tokenCookieName, Value: "abc"})
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log line %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "request handled" || entry["user_id"] != float64(7) || entry["request_id"] == nil {
+		t.Fatalf("log line = %v, want request handled with user_id 7 and request_id", entry)
+	}
+}
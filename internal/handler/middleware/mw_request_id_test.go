@@ -0,0 +1,59 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	l "github.com/IvanOplesnin/gofermart.git/internal/logger"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		incoming  string
+		wantEqual bool
+	}{
+		{name: "client id is echoed", incoming: "abc-123", wantEqual: true},
+		{name: "missing id is generated", incoming: "", wantEqual: false},
+		{name: "invalid id is replaced", incoming: "bad id\n", wantEqual: false},
+		{name: "too long id is replaced", incoming: strings.Repeat("a", maxRequestIDLength+1), wantEqual: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxID string
+			var entryID any
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = RequestIDFromCtx(r.Context())
+				entryID = l.FromContext(r.Context()).Data["request_id"]
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/any", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rr := httptest.NewRecorder()
+			RequestID(next).ServeHTTP(rr, req)
+
+			got := rr.Header().Get(RequestIDHeader)
+			if got == "" {
+				t.Fatalf("expected %s header in response", RequestIDHeader)
+			}
+			if tt.wantEqual && got != tt.incoming {
+				t.Fatalf("expected request id %q, got %q", tt.incoming, got)
+			}
+			if !tt.wantEqual && got == tt.incoming {
+				t.Fatalf("expected generated request id, got client value %q", got)
+			}
+			if ctxID != got {
+				t.Fatalf("expected request id in context %q, got %q", got, ctxID)
+			}
+			if entryID != got {
+				t.Fatalf("expected request_id log field %q, got %v", got, entryID)
+			}
+		})
+	}
+}
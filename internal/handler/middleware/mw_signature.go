@@ -0,0 +1,33 @@
+package mw
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+	"github.com/IvanOplesnin/gofermart.git/internal/signature"
+)
+
+const maxSignedBody = 1 << 20
+
+// VerifySignature пропускает только запросы, тело которых подписано HMAC-SHA256
+// общим секретом. Тело читается целиком и передаётся дальше без изменений.
+func VerifySignature(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBody))
+			if err != nil {
+				problem.Write(w, r, problem.New(http.StatusBadRequest, apierror.CodeInvalidBody, "failed to read request body"))
+				return
+			}
+			if !signature.Valid(secret, body, r.Header.Get(signature.Header)) {
+				problem.Write(w, r, problem.New(http.StatusUnauthorized, apierror.CodeInvalidSignature, "invalid signature"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
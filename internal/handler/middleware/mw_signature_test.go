@@ -0,0 +1,54 @@
+package mw
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/signature"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("shared")
+	body := `{"order":"79927398713","status":"PROCESSED","accrual":500}`
+
+	tests := []struct {
+		name       string
+		signature  string
+		wantCode   int
+		nextCalled bool
+	}{
+		{name: "valid", signature: signature.Sign(secret, []byte(body)), wantCode: http.StatusOK, nextCalled: true},
+		{name: "missing", signature: "", wantCode: http.StatusUnauthorized},
+		{name: "wrong secret", signature: signature.Sign([]byte("other"), []byte(body)), wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				b, _ := io.ReadAll(r.Body)
+				gotBody = string(b)
+			})
+			req := httptest.NewRequest(http.MethodPost, "/internal/accrual/callback", strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set(signature.Header, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			VerifySignature(secret)(next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if called != tt.nextCalled {
+				t.Fatalf("next called = %v, want %v", called, tt.nextCalled)
+			}
+			if called && gotBody != body {
+				t.Errorf("body = %q, want %q", gotBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package openapi содержит OpenAPI-описание HTTP API, вшитое в бинарь,
+// хендлеры для его отдачи и middleware валидации запросов по схеме.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+//go:embed openapi.json
+var specJSON []byte
+
+const (
+	contentTypeKey       = "Content-Type"
+	applicationJSONValue = "application/json"
+	textHTMLValue        = "text/html; charset=utf-8"
+	schemaRefPrefix      = "#/components/schemas/"
+)
+
+// Document — подмножество OpenAPI 3, которое нужно серверу для маршрутизации и валидации.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+// Operation возвращает описание метода или nil, если метод не описан.
+func (p *PathItem) Operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return p.Get
+	case http.MethodPost:
+		return p.Post
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPatch:
+		return p.Patch
+	case http.MethodDelete:
+		return p.Delete
+	}
+	return nil
+}
+
+type Operation struct {
+	OperationID string                     `json:"operationId"`
+	RequestBody *RequestBody               `json:"requestBody,omitempty"`
+	Responses   map[string]json.RawMessage `json:"responses"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref              string             `json:"$ref,omitempty"`
+	Type             SchemaType         `json:"type,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	Enum             []any              `json:"enum,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty"`
+	Minimum          *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum *float64           `json:"exclusiveMinimum,omitempty"`
+	Format           string             `json:"format,omitempty"`
+}
+
+// SchemaType допускает как одиночный тип, так и список типов (OpenAPI 3.1).
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("openapi: invalid schema type %s: %w", string(data), err)
+	}
+	*t = many
+	return nil
+}
+
+var (
+	loadOnce sync.Once
+	loadDoc  *Document
+	loadErr  error
+)
+
+// Load разбирает вшитый документ. Результат кэшируется.
+func Load() (*Document, error) {
+	loadOnce.Do(func() {
+		var doc Document
+		if err := json.Unmarshal(specJSON, &doc); err != nil {
+			loadErr = fmt.Errorf("openapi.Load: %w", err)
+			return
+		}
+		loadDoc = &doc
+	})
+	return loadDoc, loadErr
+}
+
+// Raw возвращает исходный JSON документа.
+func Raw() []byte {
+	return specJSON
+}
+
+// Find возвращает операцию для пути и метода.
+func (d *Document) Find(path string, method string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok || item == nil {
+		return nil
+	}
+	return item.Operation(method)
+}
+
+func (d *Document) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, schemaRefPrefix)]
+	}
+	return s
+}
+
+// SpecHandler отдаёт OpenAPI-документ.
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentTypeKey, applicationJSONValue)
+	_, _ = w.Write(specJSON)
+}
+
+// DocsHandler отдаёт страницу Swagger UI, которая загружает документ с specURL.
+func DocsHandler(specURL string) http.HandlerFunc {
+	page := fmt.Sprintf(docsPage, specURL)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentTypeKey, textHTMLValue)
+		_, _ = w.Write([]byte(page))
+	}
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>Gophermart API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui", withCredentials: true});
+  </script>
+</body>
+</html>
+`
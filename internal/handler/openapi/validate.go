@@ -0,0 +1,227 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/problem"
+)
+
+const (
+	contentEncodingKey = "Content-Encoding"
+
+	// maxBody — сколько байт тела читается для проверки.
+	maxBody = 1 << 20
+)
+
+// Validator проверяет Content-Type и тело запроса по схеме документа.
+// Запросы к путям, которых нет в документе, пропускаются без изменений:
+// на них ответит роутер. Сжатое тело должно быть распаковано раньше
+// (mw.Decompress): проверить его нельзя, поэтому такой запрос отклоняется.
+func Validator(doc *Document) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			op := doc.Find(r.URL.Path, r.Method)
+			if op == nil || op.RequestBody == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if enc := r.Header.Get(contentEncodingKey); enc != "" && enc != "identity" {
+				problem.Write(w, r, problem.New(http.StatusUnsupportedMediaType, apierror.CodeUnsupportedEncoding, "unsupported Content-Encoding: "+enc))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+			violations, err := doc.validateRequest(r, op.RequestBody)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.Write(w, r, problem.New(http.StatusRequestEntityTooLarge, apierror.CodeBodyTooLarge, "request body is too large"))
+				return
+			}
+			if err != nil {
+				problem.Write(w, r, problem.New(http.StatusBadRequest, apierror.CodeInvalidBody, "failed to read request body"))
+				return
+			}
+			if len(violations) > 0 {
+				p := problem.New(http.StatusBadRequest, apierror.CodeSchemaViolation, "request does not match the API schema")
+				problem.Write(w, r, p.WithDetails(violations))
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func (d *Document) validateRequest(r *http.Request, body *RequestBody) ([]string, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(contentTypeKey))
+	if err != nil {
+		mediaType = ""
+	}
+	media, ok := body.Content[mediaType]
+	if !ok {
+		return []string{fmt.Sprintf("unsupported Content-Type %q, expected one of %s", mediaType, mediaTypes(body))}, nil
+	}
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+
+	if len(raw) == 0 {
+		if body.Required {
+			return []string{"request body is required"}, nil
+		}
+		return nil, nil
+	}
+	if media.Schema == nil {
+		return nil, nil
+	}
+
+	var value any
+	if strings.HasSuffix(mediaType, "json") {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return []string{"body is not valid json: " + err.Error()}, nil
+		}
+	} else {
+		value = string(raw)
+	}
+
+	var violations []string
+	d.validateValue("body", value, media.Schema, &violations)
+	return violations, nil
+}
+
+func (d *Document) validateValue(path string, value any, schema *Schema, violations *[]string) {
+	schema = d.resolve(schema)
+	if schema == nil {
+		return
+	}
+	if len(schema.Type) > 0 && !matchesType(value, schema.Type) {
+		*violations = append(*violations, fmt.Sprintf("%s: expected type %s", path, strings.Join(schema.Type, "|")))
+		return
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, fmt.Sprintf("%s.%s: is required", path, name))
+			}
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if pv, ok := v[name]; ok {
+				d.validateValue(path+"."+name, pv, schema.Properties[name], violations)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			d.validateValue(fmt.Sprintf("%s[%d]", path, i), item, schema.Items, violations)
+		}
+	case string:
+		if schema.MinLength != nil && len([]rune(v)) < *schema.MinLength {
+			*violations = append(*violations, fmt.Sprintf("%s: length must be at least %d", path, *schema.MinLength))
+		}
+		if schema.Pattern != "" && !compilePattern(schema.Pattern).MatchString(v) {
+			*violations = append(*violations, fmt.Sprintf("%s: does not match pattern %s", path, schema.Pattern))
+		}
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			*violations = append(*violations, fmt.Sprintf("%s: invalid number", path))
+			return
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			*violations = append(*violations, fmt.Sprintf("%s: must be >= %v", path, *schema.Minimum))
+		}
+		if schema.ExclusiveMinimum != nil && f <= *schema.ExclusiveMinimum {
+			*violations = append(*violations, fmt.Sprintf("%s: must be > %v", path, *schema.ExclusiveMinimum))
+		}
+	}
+	if len(schema.Enum) > 0 && !inEnum(value, schema.Enum) {
+		*violations = append(*violations, fmt.Sprintf("%s: value is not allowed", path))
+	}
+}
+
+func matchesType(value any, types SchemaType) bool {
+	for _, t := range types {
+		switch t {
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(json.Number); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := value.(json.Number); ok {
+				if _, err := n.Int64(); err == nil {
+					return true
+				}
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(value any, enum []any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func mediaTypes(body *RequestBody) string {
+	types := make([]string, 0, len(body.Content))
+	for t := range body.Content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
+}
+
+var patternCache sync.Map
+
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patternCache.Store(pattern, re)
+	return re
+}
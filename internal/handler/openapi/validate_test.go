@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Fatalf("load openapi: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		encoding    string
+		body        string
+		wantStatus  int
+		wantNext    bool
+	}{
+		{name: "valid register", method: http.MethodPost, path: "/api/user/register", contentType: "application/json", body: `{"login":"a","password":"b"}`, wantStatus: http.StatusOK, wantNext: true},
+		{name: "register missing password", method: http.MethodPost, path: "/api/user/register", contentType: "application/json", body: `{"login":"a"}`, wantStatus: http.StatusBadRequest},
+		{name: "register wrong type", method: http.MethodPost, path: "/api/user/register", contentType: "application/json", body: `{"login":1,"password":"b"}`, wantStatus: http.StatusBadRequest},
+		{name: "register broken json", method: http.MethodPost, path: "/api/user/register", contentType: "application/json", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "order wrong content type", method: http.MethodPost, path: "/api/user/orders", contentType: "application/json", body: `12345678903`, wantStatus: http.StatusBadRequest},
+		{name: "order not digits", method: http.MethodPost, path: "/api/user/orders", contentType: "text/plain", body: `abc`, wantStatus: http.StatusBadRequest},
+		{name: "valid order", method: http.MethodPost, path: "/api/user/orders", contentType: "text/plain", body: "12345678903\n", wantStatus: http.StatusOK, wantNext: true},
+		{name: "withdraw sum is string", method: http.MethodPost, path: "/api/user/balance/withdraw", contentType: "application/json", body: `{"order":"2377225624","sum":"751"}`, wantStatus: http.StatusBadRequest},
+		{name: "valid withdraw with charset", method: http.MethodPost, path: "/api/user/balance/withdraw", contentType: "application/json; charset=utf-8", body: `{"order":"2377225624","sum":751}`, wantStatus: http.StatusOK, wantNext: true},
+		{name: "get without body", method: http.MethodGet, path: "/api/user/orders", wantStatus: http.StatusOK, wantNext: true},
+		{name: "compressed body -> 415", method: http.MethodPost, path: "/api/user/orders", contentType: "text/plain", encoding: "gzip", body: "x", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "body over limit -> 413", method: http.MethodPost, path: "/api/user/orders", contentType: "text/plain", body: strings.Repeat("1", maxBody+1), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "unknown path is passed to router", method: http.MethodPost, path: "/unknown", contentType: "text/plain", body: "x", wantStatus: http.StatusOK, wantNext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextCalled := false
+			var gotBody string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				raw, _ := io.ReadAll(r.Body)
+				gotBody = string(raw)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(contentTypeKey, tt.contentType)
+			}
+			if tt.encoding != "" {
+				req.Header.Set(contentEncodingKey, tt.encoding)
+			}
+			rr := httptest.NewRecorder()
+			Validator(doc)(next).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if nextCalled != tt.wantNext {
+				t.Fatalf("expected nextCalled=%v, got %v", tt.wantNext, nextCalled)
+			}
+			if nextCalled && gotBody != tt.body {
+				t.Fatalf("expected body to be preserved %q, got %q", tt.body, gotBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler/openapi"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestOpenAPI_CoversAllRoutes(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load openapi: %v", err)
+	}
+
+	router := InitHandler(HandlerDeps{ValidateRequests: true})
+
+	registered := make(map[string]bool)
+	err = chi.Walk(router, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered[method+" "+route] = true
+		if doc.Find(route, method) == nil {
+			t.Errorf("route %s %s is missing from openapi.json", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk routes: %v", err)
+	}
+
+	for path, item := range doc.Paths {
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			if item.Operation(method) == nil {
+				continue
+			}
+			if !registered[method+" "+path] {
+				t.Errorf("openapi.json describes %s %s, but the router does not serve it", method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPI_ServedByRouter(t *testing.T) {
+	router := InitHandler(HandlerDeps{})
+
+	rr := serve(router, http.MethodGet, openAPIPath)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"openapi"`) {
+		t.Fatalf("expected openapi document, got %q", rr.Body.String())
+	}
+
+	rr = serve(router, http.MethodGet, docsPath)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), openAPIPath) {
+		t.Fatalf("expected docs page to reference %s", openAPIPath)
+	}
+}
+
+// Через роутер тело сначала распаковывается, потом проверяется по схеме,
+// а на защищённых маршрутах всё это происходит после проверки токена.
+func TestOpenAPI_ValidatorInRouter(t *testing.T) {
+	gzipBody := func(s string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte(s))
+		_ = gz.Close()
+		return buf.Bytes()
+	}
+	svc := newBenchService(0)
+	router := InitHandler(HandlerDeps{
+		Reqistrar:        svc,
+		Auther:           svc,
+		TokenChecker:     svc,
+		Ordered:          svc,
+		Balancer:         svc,
+		Withdrawer:       svc,
+		ValidateRequests: true,
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		ctype      string
+		body       []byte
+		gzip       bool
+		noCookie   bool
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "gzip order is validated", path: "/api/user/orders", ctype: "text/plain", body: gzipBody("abc"), gzip: true, wantStatus: http.StatusBadRequest, wantCode: apierror.CodeSchemaViolation},
+		{name: "gzip order passes", path: "/api/user/orders", ctype: "text/plain", body: gzipBody("12345678903"), gzip: true, wantStatus: http.StatusAccepted},
+		{name: "gzip register is validated", path: "/api/user/register", ctype: "application/json", body: gzipBody(`{"login":"a"}`), gzip: true, wantStatus: http.StatusBadRequest, wantCode: apierror.CodeSchemaViolation},
+		{name: "gzip withdraw is validated", path: "/api/user/balance/withdraw", ctype: "application/json", body: gzipBody(`{"order":"1","sum":"x"}`), gzip: true, wantStatus: http.StatusBadRequest, wantCode: apierror.CodeSchemaViolation},
+		{name: "no token before schema", path: "/api/user/orders", ctype: "text/plain", body: []byte("abc"), noCookie: true, wantStatus: http.StatusUnauthorized, wantCode: apierror.CodeUnauthorized},
+		{name: "gzip bomb", path: "/api/user/orders", ctype: "text/plain", body: gzipBody(strings.Repeat("1", 16<<20)), gzip: true, wantStatus: http.StatusRequestEntityTooLarge, wantCode: apierror.CodeBodyTooLarge},
+		{name: "plain body over limit", path: "/api/user/orders", ctype: "text/plain", body: bytes.Repeat([]byte("1"), 2<<20), wantStatus: http.StatusRequestEntityTooLarge, wantCode: apierror.CodeBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.ctype)
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			if !tt.noCookie {
+				req.AddCookie(&http.Cookie{Name: "token", Value: "token"})
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantCode != "" && !strings.Contains(rr.Body.String(), `"code":"`+tt.wantCode+`"`) {
+				t.Fatalf("body = %s, want code %s", rr.Body.String(), tt.wantCode)
+			}
+		})
+	}
+}
+
+func serve(h http.Handler, method string, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
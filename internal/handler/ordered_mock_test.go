@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./internal/handler/orders.go
+// Source: github.com/IvanOplesnin/gofermart.git/internal/service (interfaces: Ordered)
 //
 // Generated by this command:
 //
-//	mockgen -source=./internal/handler/orders.go -destination=./internal/handler/ordered_mock_test.go -package=handler
+//	mockgen -destination=./internal/handler/ordered_mock_test.go -package=handler github.com/IvanOplesnin/gofermart.git/internal/service Ordered
 //
 
 // Package handler is a generated GoMock package.
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	gophermart "github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,31 +42,31 @@ func (m *MockOrdered) EXPECT() *MockOrderedMockRecorder {
 }
 
 // AddOrder mocks base method.
-func (m *MockOrdered) AddOrder(ctx context.Context, orderID string) (bool, error) {
+func (m *MockOrdered) AddOrder(ctx context.Context, userID int32, orderID string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddOrder", ctx, orderID)
+	ret := m.ctrl.Call(m, "AddOrder", ctx, userID, orderID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddOrder indicates an expected call of AddOrder.
-func (mr *MockOrderedMockRecorder) AddOrder(ctx, orderID any) *gomock.Call {
+func (mr *MockOrderedMockRecorder) AddOrder(ctx, userID, orderID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrdered)(nil).AddOrder), ctx, orderID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrdered)(nil).AddOrder), ctx, userID, orderID)
 }
 
 // Orders mocks base method.
-func (m *MockOrdered) Orders(ctx context.Context) ([]Order, error) {
+func (m *MockOrdered) Orders(ctx context.Context, userID int32) ([]gophermart.UserOrder, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Orders", ctx)
-	ret0, _ := ret[0].([]Order)
+	ret := m.ctrl.Call(m, "Orders", ctx, userID)
+	ret0, _ := ret[0].([]gophermart.UserOrder)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Orders indicates an expected call of Orders.
-func (mr *MockOrderedMockRecorder) Orders(ctx any) *gomock.Call {
+func (mr *MockOrderedMockRecorder) Orders(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Orders", reflect.TypeOf((*MockOrdered)(nil).Orders), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Orders", reflect.TypeOf((*MockOrdered)(nil).Orders), ctx, userID)
 }
@@ -1,20 +1,15 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
 )
 
-type Ordered interface {
-	AddOrder(ctx context.Context, orderID string) (exist bool, err error)
-	Orders(ctx context.Context) ([]Order, error)
-}
-
 type Order struct {
 	Number     string      `json:"number"`
 	Status     string      `json:"status"`
@@ -22,32 +17,24 @@ type Order struct {
 	UploadedAt RFC3339Time `json:"uploaded_at"`
 }
 
-var ErrInvalidOrderID = errors.New("invalid order id")
-var ErrAnotherUserOrder = errors.New("another user order")
-
-func AddOrderHandler(o Ordered) http.HandlerFunc {
+func AddOrderHandler(o service.Ordered) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get(contentTypeKey) != textPlainValue {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidContentType, "expected Content-Type text/plain")
 			return
 		}
 		number, err := io.ReadAll(r.Body)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		ctx := r.Context()
-		exist, err := o.AddOrder(ctx, string(number))
-		if errors.Is(err, ErrInvalidOrderID) {
-			w.WriteHeader(http.StatusUnprocessableEntity)
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "failed to read request body")
 			return
 		}
-		if errors.Is(err, ErrAnotherUserOrder) {
-			w.WriteHeader(http.StatusConflict)
+		userID, ok := requestUserID(w, r)
+		if !ok {
 			return
 		}
+		exist, err := o.AddOrder(r.Context(), userID, string(number))
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 		if exist {
@@ -58,22 +45,33 @@ func AddOrderHandler(o Ordered) http.HandlerFunc {
 	}
 }
 
-func OrdersHandler(o Ordered) http.HandlerFunc {
+func OrdersHandler(o service.Ordered) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		orders, err := o.Orders(ctx)
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		orders, err := o.Orders(r.Context(), userID)
 		if err != nil {
-			logger.Log.Errorf("ordersHandler error: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 		if len(orders) == 0 {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
+		resp := make([]Order, 0, len(orders))
+		for _, item := range orders {
+			resp = append(resp, Order{
+				Number:     item.Number,
+				Status:     item.Status,
+				Accrual:    item.Accrual,
+				UploadedAt: RFC3339Time(item.UploadedAt),
+			})
+		}
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(orders); err != nil {
-			logger.Log.Errorf("ordersHandler error: %s", err.Error())
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.FromContext(r.Context()).Errorf("ordersHandler error: %s", err.Error())
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -11,9 +10,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	"go.uber.org/mock/gomock"
 )
 
+const testUserID int32 = 1
+
+// withUser кладёт в контекст запроса пользователя, как это делает mw.CheckCookie.
+func withUser(r *http.Request) *http.Request {
+	return r.WithContext(service.WithUserID(r.Context(), testUserID))
+}
+
 func TestAddOrderHandler(t *testing.T) {
 	type want struct {
 		statusCode int
@@ -31,7 +39,7 @@ func TestAddOrderHandler(t *testing.T) {
 			contentType: "application/json",
 			body:        []byte("123"),
 			setupMock: func(m *MockOrdered) {
-				m.EXPECT().AddOrder(gomock.Any(), gomock.Any()).Times(0)
+				m.EXPECT().AddOrder(gomock.Any(), testUserID, gomock.Any()).Times(0)
 			},
 			want: want{statusCode: http.StatusBadRequest},
 		},
@@ -41,8 +49,8 @@ func TestAddOrderHandler(t *testing.T) {
 			body:        []byte("not-valid"),
 			setupMock: func(m *MockOrdered) {
 				m.EXPECT().
-					AddOrder(gomock.Any(), "not-valid").
-					Return(false, ErrInvalidOrderID).
+					AddOrder(gomock.Any(), testUserID, "not-valid").
+					Return(false, gophermart.ErrInvalidOrderNumber).
 					Times(1)
 			},
 			want: want{statusCode: http.StatusUnprocessableEntity},
@@ -53,8 +61,8 @@ func TestAddOrderHandler(t *testing.T) {
 			body:        []byte("12345678903"),
 			setupMock: func(m *MockOrdered) {
 				m.EXPECT().
-					AddOrder(gomock.Any(), "12345678903").
-					Return(false, ErrAnotherUserOrder).
+					AddOrder(gomock.Any(), testUserID, "12345678903").
+					Return(false, gophermart.ErrOrderOwnedByAnotherUser).
 					Times(1)
 			},
 			want: want{statusCode: http.StatusConflict},
@@ -65,7 +73,7 @@ func TestAddOrderHandler(t *testing.T) {
 			body:        []byte("12345678903"),
 			setupMock: func(m *MockOrdered) {
 				m.EXPECT().
-					AddOrder(gomock.Any(), "12345678903").
+					AddOrder(gomock.Any(), testUserID, "12345678903").
 					Return(false, errors.New("db down")).
 					Times(1)
 			},
@@ -77,7 +85,7 @@ func TestAddOrderHandler(t *testing.T) {
 			body:        []byte("12345678903"),
 			setupMock: func(m *MockOrdered) {
 				m.EXPECT().
-					AddOrder(gomock.Any(), "12345678903").
+					AddOrder(gomock.Any(), testUserID, "12345678903").
 					Return(true, nil).
 					Times(1)
 			},
@@ -89,7 +97,7 @@ func TestAddOrderHandler(t *testing.T) {
 			body:        []byte("12345678903"),
 			setupMock: func(m *MockOrdered) {
 				m.EXPECT().
-					AddOrder(gomock.Any(), "12345678903").
+					AddOrder(gomock.Any(), testUserID, "12345678903").
 					Return(false, nil).
 					Times(1)
 			},
@@ -114,8 +122,7 @@ func TestAddOrderHandler(t *testing.T) {
 			if tt.contentType != "" {
 				req.Header.Set(contentTypeKey, tt.contentType)
 			}
-			// на всякий случай фиксируем контекст
-			req = req.WithContext(context.Background())
+			req = withUser(req)
 
 			rr := httptest.NewRecorder()
 			h.ServeHTTP(rr, req)
@@ -143,7 +150,7 @@ func TestOrdersHandler(t *testing.T) {
 			name: "service error -> 500",
 			setupMock: func(m *MockOrdered) {
 				m.EXPECT().
-					Orders(gomock.Any()).
+					Orders(gomock.Any(), testUserID).
 					Return(nil, errors.New("db down")).
 					Times(1)
 			},
@@ -153,8 +160,8 @@ func TestOrdersHandler(t *testing.T) {
 			name: "empty list -> 204",
 			setupMock: func(m *MockOrdered) {
 				m.EXPECT().
-					Orders(gomock.Any()).
-					Return([]Order{}, nil).
+					Orders(gomock.Any(), testUserID).
+					Return([]gophermart.UserOrder{}, nil).
 					Times(1)
 			},
 			want: want{statusCode: http.StatusNoContent},
@@ -163,23 +170,23 @@ func TestOrdersHandler(t *testing.T) {
 			name: "non-empty list -> 200 and json",
 			setupMock: func(m *MockOrdered) {
 				accrual := 12.34
-				orders := []Order{
+				orders := []gophermart.UserOrder{
 					{
 						Number:     "12345678903",
 						Status:     "NEW",
 						Accrual:    &accrual,
-						UploadedAt: RFC3339Time(time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC)),
+						UploadedAt: time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC),
 					},
 					{
 						Number:     "55555555555",
 						Status:     "PROCESSED",
 						Accrual:    nil,
-						UploadedAt: RFC3339Time(time.Date(2026, 2, 19, 13, 0, 0, 0, time.UTC)),
+						UploadedAt: time.Date(2026, 2, 19, 13, 0, 0, 0, time.UTC),
 					},
 				}
 
 				m.EXPECT().
-					Orders(gomock.Any()).
+					Orders(gomock.Any(), testUserID).
 					Return(orders, nil).
 					Times(1)
 			},
@@ -206,15 +213,15 @@ func TestOrdersHandler(t *testing.T) {
 		{
 			name: "encoder error path is hard to trigger; still validate no extra text on success",
 			setupMock: func(m *MockOrdered) {
-				orders := []Order{
+				orders := []gophermart.UserOrder{
 					{
 						Number:     "1",
 						Status:     "NEW",
 						Accrual:    nil,
-						UploadedAt: RFC3339Time(time.Now().UTC()),
+						UploadedAt: time.Now().UTC(),
 					},
 				}
-				m.EXPECT().Orders(gomock.Any()).Return(orders, nil).Times(1)
+				m.EXPECT().Orders(gomock.Any(), testUserID).Return(orders, nil).Times(1)
 			},
 			want: want{
 				statusCode:  http.StatusOK,
@@ -247,7 +254,7 @@ func TestOrdersHandler(t *testing.T) {
 			h := OrdersHandler(ordered)
 
 			req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
-			req = req.WithContext(context.Background())
+			req = withUser(req)
 
 			rr := httptest.NewRecorder()
 			h.ServeHTTP(rr, req)
@@ -269,3 +276,23 @@ func TestOrdersHandler(t *testing.T) {
 		})
 	}
 }
+
+// Номер заказа доходит до сервиса байт в байт: проверка и нормализация — его забота.
+func FuzzAddOrderHandler(f *testing.F) {
+	for _, seed := range []string{"12345678903", "1234 5678 903\n", "", "\x00\xff", "１２３"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, body string) {
+		ctrl := gomock.NewController(t)
+		m := NewMockOrdered(ctrl)
+		m.EXPECT().AddOrder(gomock.Any(), testUserID, body).Return(false, nil).Times(1)
+
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(body)))
+		req.Header.Set("Content-Type", "text/plain")
+		rec := httptest.NewRecorder()
+		AddOrderHandler(m).ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("body %q: status = %d", body, rec.Code)
+		}
+	})
+}
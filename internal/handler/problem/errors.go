@@ -0,0 +1,28 @@
+package problem
+
+import (
+	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+)
+
+// statuses переводит класс ошибки из apierror в HTTP-статус.
+var statuses = map[apierror.Kind]int{
+	apierror.InvalidArgument:   http.StatusBadRequest,
+	apierror.Unauthenticated:   http.StatusUnauthorized,
+	apierror.InsufficientFunds: http.StatusPaymentRequired,
+	apierror.NotFound:          http.StatusNotFound,
+	apierror.Conflict:          http.StatusConflict,
+	apierror.Unprocessable:     http.StatusUnprocessableEntity,
+}
+
+// FromError возвращает описание ошибки для ответа клиенту.
+// Неизвестные ошибки превращаются в 500 без раскрытия внутренних деталей.
+func FromError(err error) Problem {
+	e := apierror.FromError(err)
+	status, ok := statuses[e.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	return New(status, e.Code, e.Message)
+}
@@ -0,0 +1,43 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid order number", err: gophermart.ErrInvalidOrderNumber, wantStatus: http.StatusUnprocessableEntity, wantCode: apierror.CodeInvalidOrderNumber},
+		{name: "another user order", err: gophermart.ErrOrderOwnedByAnotherUser, wantStatus: http.StatusConflict, wantCode: apierror.CodeOrderOwnedByAnotherUser},
+		{name: "user already exists", err: gophermart.ErrUserAlreadyExists, wantStatus: http.StatusConflict, wantCode: apierror.CodeUserAlreadyExists},
+		{name: "not enough balance", err: gophermart.ErrNotEnoughBalance, wantStatus: http.StatusPaymentRequired, wantCode: apierror.CodeInsufficientFunds},
+		{name: "invalid sum", err: gophermart.ErrInvalidSum, wantStatus: http.StatusUnprocessableEntity, wantCode: apierror.CodeInvalidSum},
+		{name: "wrapped sentinel", err: fmt.Errorf("service.AddOrder: %w", gophermart.ErrOrderOwnedByAnotherUser), wantStatus: http.StatusConflict, wantCode: apierror.CodeOrderOwnedByAnotherUser},
+		{name: "invalid token", err: gophermart.ErrInvalidToken, wantStatus: http.StatusUnauthorized, wantCode: apierror.CodeInvalidToken},
+		{name: "no token", err: gophermart.ErrNoToken, wantStatus: http.StatusUnauthorized, wantCode: apierror.CodeUnauthorized},
+		{name: "empty credentials", err: gophermart.ErrEmptyField, wantStatus: http.StatusBadRequest, wantCode: apierror.CodeEmptyCredentials},
+		{name: "unknown error", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: apierror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := FromError(tt.err)
+			if p.Status != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, p.Status)
+			}
+			if p.Code != tt.wantCode {
+				t.Fatalf("expected code %q, got %q", tt.wantCode, p.Code)
+			}
+		})
+	}
+}
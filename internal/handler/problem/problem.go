@@ -0,0 +1,68 @@
+// Package problem формирует ответы об ошибках в формате RFC 7807 (application/problem+json).
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+)
+
+const (
+	contentTypeKey    = "Content-Type"
+	problemJSONValue  = "application/problem+json"
+	requestIDHeader   = "X-Request-ID"
+	problemTypePrefix = "/problems/"
+)
+
+// Problem — тело ответа об ошибке, общее для всех эндпоинтов.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func New(status int, code string, message string) Problem {
+	return Problem{
+		Type:    problemTypePrefix + code,
+		Title:   http.StatusText(status),
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (p Problem) WithDetails(details any) Problem {
+	p.Details = details
+	return p
+}
+
+func (p Problem) Error() string {
+	return p.Code + ": " + p.Message
+}
+
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get(requestIDHeader)
+	}
+	if p.RequestID == "" && r != nil {
+		p.RequestID = r.Header.Get(requestIDHeader)
+	}
+	w.Header().Set(contentTypeKey, problemJSONValue)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logger.FromContext(requestContext(r)).Errorf("problem.Write encode error: %s", err.Error())
+	}
+}
+
+func requestContext(r *http.Request) context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	return r.Context()
+}
@@ -1,76 +1,54 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 )
 
-type Registrar interface {
-	Register(ctx context.Context, login string, password string) (string, error)
-}
-
 type RegiseterRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
 }
 
-var ErrUserAlreadyExists = errors.New("login is exists")
-var ErrEmptyField = errors.New("empty field login or password")
-
-func Register(reg Registrar) http.HandlerFunc {
+func Register(reg service.Registrar, cookies CookieOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get(contentTypeKey) != applicationJSONValue {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidContentType, "expected Content-Type application/json")
 			return
 		}
 		raw, err := io.ReadAll(r.Body)
 		defer r.Body.Close()
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "failed to read request body")
 			return
 		}
 
 		var regReq RegiseterRequest
 		if err := json.Unmarshal(raw, &regReq); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "invalid json body")
 			return
 		}
-		logger.Log.Debugf("user Login: %s", regReq.Login)
+		logger.FromContext(r.Context()).Debugf("user Login: %s", regReq.Login)
 		ctx := r.Context()
 
 		if regReq.Login == "" || regReq.Password == "" {
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, r, gophermart.ErrEmptyField)
 			return
 		}
 
 		token, err := reg.Register(ctx, regReq.Login, regReq.Password)
-		logger.Log.Debugf("token %s", token)
-		if errors.Is(err, ErrUserAlreadyExists) {
-			w.WriteHeader(http.StatusConflict)
-			return
-		}
-		if errors.Is(err, ErrEmptyField) {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+		logger.FromContext(r.Context()).Debugf("token %s", token)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
-		if token != "" {
-			http.SetCookie(w, &http.Cookie{
-				Name:     tokenCookieName,
-				Value:    token,
-				Path:     "/api",
-				HttpOnly: true,
-				SameSite: http.SameSiteLaxMode,
-			})
-		}
+		setAuthCookies(w, cookies, token)
 		w.WriteHeader(http.StatusOK)
 	}
 }
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// Webhook — зарегистрированный вебхук. Секрет после регистрации не отдаётся.
+type Webhook struct {
+	ID        int32       `json:"id"`
+	URL       string      `json:"url"`
+	CreatedAt RFC3339Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64        `json:"id"`
+	EventID        int64        `json:"event_id"`
+	EventType      string       `json:"event_type"`
+	Status         string       `json:"status"`
+	Attempts       int32        `json:"attempts"`
+	LastStatusCode *int32       `json:"last_status_code,omitempty"`
+	LastError      string       `json:"last_error,omitempty"`
+	CreatedAt      RFC3339Time  `json:"created_at"`
+	DeliveredAt    *RFC3339Time `json:"delivered_at,omitempty"`
+	NextAttemptAt  *RFC3339Time `json:"next_attempt_at,omitempty"`
+}
+
+func CreateWebhookHandler(wh service.Webhooks) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(contentTypeKey) != applicationJSONValue {
+			writeBadRequest(w, r, apierror.CodeInvalidContentType, "expected Content-Type application/json")
+			return
+		}
+		var req WebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "invalid JSON body")
+			return
+		}
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		webhook, err := wh.CreateWebhook(r.Context(), userID, req.URL, req.Secret)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeJSON(w, r, http.StatusCreated, toWebhook(webhook))
+	}
+}
+
+func ListWebhooksHandler(wh service.Webhooks) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		list, err := wh.Webhooks(r.Context(), userID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if len(list) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		resp := make([]Webhook, 0, len(list))
+		for _, item := range list {
+			resp = append(resp, toWebhook(item))
+		}
+		writeJSON(w, r, http.StatusOK, resp)
+	}
+}
+
+func DeleteWebhookHandler(wh service.Webhooks) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		id, ok := webhookID(w, r)
+		if !ok {
+			return
+		}
+		if err := wh.DeleteWebhook(r.Context(), userID, id); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func WebhookDeliveriesHandler(wh service.Webhooks) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		id, ok := webhookID(w, r)
+		if !ok {
+			return
+		}
+		list, err := wh.WebhookDeliveries(r.Context(), userID, id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if len(list) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		resp := make([]WebhookDelivery, 0, len(list))
+		for _, item := range list {
+			resp = append(resp, toWebhookDelivery(item))
+		}
+		writeJSON(w, r, http.StatusOK, resp)
+	}
+}
+
+func toWebhook(w gophermart.Webhook) Webhook {
+	return Webhook{ID: w.ID, URL: w.URL, CreatedAt: RFC3339Time(w.CreatedAt)}
+}
+
+// toWebhookDelivery опускает незаполненные поля: нулевой код ответа и нулевое время.
+func toWebhookDelivery(d gophermart.WebhookDelivery) WebhookDelivery {
+	wd := WebhookDelivery{
+		ID:        d.ID,
+		EventID:   d.EventID,
+		EventType: d.EventType,
+		Status:    d.Status,
+		Attempts:  d.Attempts,
+		LastError: d.LastError,
+		CreatedAt: RFC3339Time(d.CreatedAt),
+	}
+	if d.LastStatusCode != 0 {
+		code := d.LastStatusCode
+		wd.LastStatusCode = &code
+	}
+	if !d.DeliveredAt.IsZero() {
+		t := RFC3339Time(d.DeliveredAt)
+		wd.DeliveredAt = &t
+	}
+	if !d.NextAttemptAt.IsZero() {
+		t := RFC3339Time(d.NextAttemptAt)
+		wd.NextAttemptAt = &t
+	}
+	return wd
+}
+
+func webhookID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 32)
+	if err != nil || id <= 0 {
+		writeBadRequest(w, r, apierror.CodeBadRequest, "invalid webhook id")
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set(contentTypeKey, applicationJSONValue)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.FromContext(r.Context()).Errorf("writeJSON: %s", err.Error())
+	}
+}
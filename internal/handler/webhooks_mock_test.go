@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/IvanOplesnin/gofermart.git/internal/service (interfaces: Webhooks)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./internal/handler/webhooks_mock_test.go -package=handler github.com/IvanOplesnin/gofermart.git/internal/service Webhooks
+//
+
+// Package handler is a generated GoMock package.
+package handler
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gophermart "github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWebhooks is a mock of Webhooks interface.
+type MockWebhooks struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhooksMockRecorder is the mock recorder for MockWebhooks.
+type MockWebhooksMockRecorder struct {
+	mock *MockWebhooks
+}
+
+// NewMockWebhooks creates a new mock instance.
+func NewMockWebhooks(ctrl *gomock.Controller) *MockWebhooks {
+	mock := &MockWebhooks{ctrl: ctrl}
+	mock.recorder = &MockWebhooksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhooks) EXPECT() *MockWebhooksMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhooks) CreateWebhook(ctx context.Context, userID int32, url, secret string) (gophermart.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, userID, url, secret)
+	ret0, _ := ret[0].(gophermart.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhooksMockRecorder) CreateWebhook(ctx, userID, url, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhooks)(nil).CreateWebhook), ctx, userID, url, secret)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhooks) DeleteWebhook(ctx context.Context, userID, id int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhooksMockRecorder) DeleteWebhook(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhooks)(nil).DeleteWebhook), ctx, userID, id)
+}
+
+// WebhookDeliveries mocks base method.
+func (m *MockWebhooks) WebhookDeliveries(ctx context.Context, userID, id int32) ([]gophermart.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebhookDeliveries", ctx, userID, id)
+	ret0, _ := ret[0].([]gophermart.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WebhookDeliveries indicates an expected call of WebhookDeliveries.
+func (mr *MockWebhooksMockRecorder) WebhookDeliveries(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookDeliveries", reflect.TypeOf((*MockWebhooks)(nil).WebhookDeliveries), ctx, userID, id)
+}
+
+// Webhooks mocks base method.
+func (m *MockWebhooks) Webhooks(ctx context.Context, userID int32) ([]gophermart.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Webhooks", ctx, userID)
+	ret0, _ := ret[0].([]gophermart.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Webhooks indicates an expected call of Webhooks.
+func (mr *MockWebhooksMockRecorder) Webhooks(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Webhooks", reflect.TypeOf((*MockWebhooks)(nil).Webhooks), ctx, userID)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		setupMock   func(m *MockWebhooks)
+		wantStatus  int
+	}{
+		{
+			name:        "wrong content-type -> 400",
+			contentType: "text/plain",
+			body:        `{"url":"https://example.com/hook","secret":"0123456789abcdef"}`,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "bad json -> 400",
+			contentType: applicationJSONValue,
+			body:        `{"url":`,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "created -> 201",
+			contentType: applicationJSONValue,
+			body:        `{"url":"https://example.com/hook","secret":"0123456789abcdef"}`,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().
+					CreateWebhook(gomock.Any(), testUserID, "https://example.com/hook", "0123456789abcdef").
+					Return(gophermart.Webhook{ID: 1, UserID: testUserID, URL: "https://example.com/hook"}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:        "invalid url -> 422",
+			contentType: applicationJSONValue,
+			body:        `{"url":"ftp://example.com","secret":"0123456789abcdef"}`,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(gophermart.Webhook{}, gophermart.ErrInvalidWebhookURL)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "duplicate -> 409",
+			contentType: applicationJSONValue,
+			body:        `{"url":"https://example.com/hook","secret":"0123456789abcdef"}`,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(gophermart.Webhook{}, gophermart.ErrWebhookAlreadyExists)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			m := NewMockWebhooks(ctrl)
+			tt.setupMock(m)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/webhooks", strings.NewReader(tt.body))
+			req.Header.Set(contentTypeKey, tt.contentType)
+			req = withUser(req)
+			rr := httptest.NewRecorder()
+			CreateWebhookHandler(m).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhookIDHandlers(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		handler    func(service.Webhooks) http.HandlerFunc
+		setupMock  func(m *MockWebhooks)
+		wantStatus int
+	}{
+		{
+			name:    "delete: bad id -> 400",
+			id:      "abc",
+			handler: DeleteWebhookHandler,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().DeleteWebhook(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "delete: ok -> 204",
+			id:      "3",
+			handler: DeleteWebhookHandler,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().DeleteWebhook(gomock.Any(), testUserID, int32(3)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "delete: foreign or missing -> 404",
+			id:      "3",
+			handler: DeleteWebhookHandler,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().DeleteWebhook(gomock.Any(), testUserID, int32(3)).Return(gophermart.ErrWebhookNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:    "deliveries: empty -> 204",
+			id:      "3",
+			handler: WebhookDeliveriesHandler,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().WebhookDeliveries(gomock.Any(), testUserID, int32(3)).Return(nil, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "deliveries: list -> 200",
+			id:      "3",
+			handler: WebhookDeliveriesHandler,
+			setupMock: func(m *MockWebhooks) {
+				m.EXPECT().WebhookDeliveries(gomock.Any(), testUserID, int32(3)).Return([]gophermart.WebhookDelivery{{ID: 1, Status: "DELIVERED"}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			m := NewMockWebhooks(ctrl)
+			tt.setupMock(m)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = withUser(req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+			rr := httptest.NewRecorder()
+			tt.handler(m).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+		})
+	}
+}
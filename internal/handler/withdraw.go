@@ -1,20 +1,15 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/apierror"
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/service"
 )
 
-type Withdrawer interface {
-	Withdraw(ctx context.Context, orderNumber string, summa float64) error
-	ListWithdraws(ctx context.Context) ([]Withdraw, error)
-}
-
 type RequestWithdraw struct {
 	OrderNumber string  `json:"order"`
 	Summa       float64 `json:"sum"`
@@ -26,71 +21,62 @@ type Withdraw struct {
 	ProcessedAt RFC3339Time `json:"processed_at"`
 }
 
-var ErrInvalidOrderNumber = errors.New("invalid order number")
-var ErrEnoughMoney = errors.New("not enough money")
-var ErrInvalidSumma = errors.New("invalid summa")
-
-func WithdrawHandler(wd Withdrawer) http.HandlerFunc {
+func WithdrawHandler(wd service.Withdrawer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ct := r.Header.Get(contentTypeKey)
 		if ct == "" || !strings.HasPrefix(ct, applicationJSONValue) {
-			w.WriteHeader(http.StatusBadRequest)
+			writeBadRequest(w, r, apierror.CodeInvalidContentType, "expected Content-Type application/json")
 			return
 		}
 
 		var withdrawData RequestWithdraw
 		if err := json.NewDecoder(r.Body).Decode(&withdrawData); err != nil {
-			logger.Log.Errorf("WithdrawHandler decode error: %s", err.Error())
-			w.WriteHeader(http.StatusBadRequest)
+			logger.FromContext(r.Context()).Errorf("WithdrawHandler decode error: %s", err.Error())
+			writeBadRequest(w, r, apierror.CodeInvalidBody, "invalid json body")
 			return
 		}
 
-		err := wd.Withdraw(r.Context(), withdrawData.OrderNumber, withdrawData.Summa)
-
-		switch {
-		case err == nil:
-			w.WriteHeader(http.StatusOK)
-			return
-
-		case errors.Is(err, ErrEnoughMoney):
-			logger.Log.Infof("WithdrawHandler not enough money: order=%s", withdrawData.OrderNumber)
-			w.WriteHeader(http.StatusPaymentRequired)
-			return
-
-		case errors.Is(err, ErrInvalidOrderNumber):
-			logger.Log.Infof("WithdrawHandler invalid order_number: order=%s", withdrawData.OrderNumber)
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			return
-
-		case errors.Is(err, ErrInvalidSumma):
-			logger.Log.Infof("WithdrawHandler invalid summa: order=%s sum=%v", withdrawData.OrderNumber, withdrawData.Summa)
-			w.WriteHeader(http.StatusUnprocessableEntity)
+		userID, ok := requestUserID(w, r)
+		if !ok {
 			return
+		}
+		err := wd.Withdraw(r.Context(), userID, withdrawData.OrderNumber, withdrawData.Summa)
 
-		default:
-			logger.Log.Errorf("WithdrawHandler error: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+		if err != nil {
+			logger.FromContext(r.Context()).Infof("WithdrawHandler: order=%s sum=%v: %s", withdrawData.OrderNumber, withdrawData.Summa, err.Error())
+			writeError(w, r, err)
 			return
 		}
+		w.WriteHeader(http.StatusOK)
 	}
 }
 
-func ListWithdrawHandler(wd Withdrawer) http.HandlerFunc {
+func ListWithdrawHandler(wd service.Withdrawer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		withdraws, err := wd.ListWithdraws(ctx)
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+		withdraws, err := wd.ListWithdraws(r.Context(), userID)
 		if err != nil {
-			logger.Log.Errorf("ListWithdrawHandler error: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 		if len(withdraws) == 0 {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
+		resp := make([]Withdraw, 0, len(withdraws))
+		for _, item := range withdraws {
+			resp = append(resp, Withdraw{
+				OrderNumber: item.Order,
+				Summa:       item.Sum,
+				ProcessedAt: RFC3339Time(item.ProcessedAt),
+			})
+		}
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(withdraws); err != nil {
-			logger.Log.Errorf("ListWithdrawHandler error: %s", err.Error())
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.FromContext(r.Context()).Errorf("ListWithdrawHandler error: %s", err.Error())
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
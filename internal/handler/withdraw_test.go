@@ -2,7 +2,6 @@ package handler
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -11,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	"go.uber.org/mock/gomock"
 )
 
@@ -31,7 +31,7 @@ func TestWithdrawHandler(t *testing.T) {
 			contentType: "",
 			body:        []byte(`{"order":"12345678903","sum":10}`),
 			setupMock: func(m *MockWithdrawer) {
-				m.EXPECT().Withdraw(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				m.EXPECT().Withdraw(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			want: want{statusCode: http.StatusBadRequest},
 		},
@@ -40,7 +40,7 @@ func TestWithdrawHandler(t *testing.T) {
 			contentType: "text/plain",
 			body:        []byte(`{"order":"12345678903","sum":10}`),
 			setupMock: func(m *MockWithdrawer) {
-				m.EXPECT().Withdraw(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				m.EXPECT().Withdraw(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			want: want{statusCode: http.StatusBadRequest},
 		},
@@ -50,7 +50,7 @@ func TestWithdrawHandler(t *testing.T) {
 			body:        []byte(`{"order":"12345678903","sum":10}`),
 			setupMock: func(m *MockWithdrawer) {
 				m.EXPECT().
-					Withdraw(gomock.Any(), "12345678903", 10.0).
+					Withdraw(gomock.Any(), testUserID, "12345678903", 10.0).
 					Return(nil).
 					Times(1)
 			},
@@ -61,7 +61,7 @@ func TestWithdrawHandler(t *testing.T) {
 			contentType: applicationJSONValue,
 			body:        []byte(`{"order":"12345678903","sum":10`), // missing }
 			setupMock: func(m *MockWithdrawer) {
-				m.EXPECT().Withdraw(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				m.EXPECT().Withdraw(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			want: want{statusCode: http.StatusBadRequest},
 		},
@@ -71,8 +71,8 @@ func TestWithdrawHandler(t *testing.T) {
 			body:        []byte(`{"order":"12345678903","sum":10}`),
 			setupMock: func(m *MockWithdrawer) {
 				m.EXPECT().
-					Withdraw(gomock.Any(), "12345678903", 10.0).
-					Return(ErrEnoughMoney).
+					Withdraw(gomock.Any(), testUserID, "12345678903", 10.0).
+					Return(gophermart.ErrNotEnoughBalance).
 					Times(1)
 			},
 			want: want{statusCode: http.StatusPaymentRequired},
@@ -83,8 +83,8 @@ func TestWithdrawHandler(t *testing.T) {
 			body:        []byte(`{"order":"bad","sum":10}`),
 			setupMock: func(m *MockWithdrawer) {
 				m.EXPECT().
-					Withdraw(gomock.Any(), "bad", 10.0).
-					Return(ErrInvalidOrderNumber).
+					Withdraw(gomock.Any(), testUserID, "bad", 10.0).
+					Return(gophermart.ErrWithdrawAlreadyProcessed).
 					Times(1)
 			},
 			want: want{statusCode: http.StatusUnprocessableEntity},
@@ -95,8 +95,8 @@ func TestWithdrawHandler(t *testing.T) {
 			body:        []byte(`{"order":"12345678903","sum":-1}`),
 			setupMock: func(m *MockWithdrawer) {
 				m.EXPECT().
-					Withdraw(gomock.Any(), "12345678903", -1.0).
-					Return(ErrInvalidSumma).
+					Withdraw(gomock.Any(), testUserID, "12345678903", -1.0).
+					Return(gophermart.ErrInvalidSum).
 					Times(1)
 			},
 			want: want{statusCode: http.StatusUnprocessableEntity},
@@ -107,7 +107,7 @@ func TestWithdrawHandler(t *testing.T) {
 			body:        []byte(`{"order":"12345678903","sum":10}`),
 			setupMock: func(m *MockWithdrawer) {
 				m.EXPECT().
-					Withdraw(gomock.Any(), "12345678903", 10.0).
+					Withdraw(gomock.Any(), testUserID, "12345678903", 10.0).
 					Return(errors.New("db down")).
 					Times(1)
 			},
@@ -129,7 +129,7 @@ func TestWithdrawHandler(t *testing.T) {
 			h := WithdrawHandler(wd)
 
 			req := httptest.NewRequest(http.MethodPost, "/api/user/balance/withdraw", bytes.NewReader(tt.body))
-			req = req.WithContext(context.Background())
+			req = withUser(req)
 
 			if tt.contentType != "" {
 				req.Header.Set(contentTypeKey, tt.contentType)
@@ -161,7 +161,7 @@ func TestListWithdrawHandler(t *testing.T) {
 			name: "service error -> 500",
 			setupMock: func(m *MockWithdrawer) {
 				m.EXPECT().
-					ListWithdraws(gomock.Any()).
+					ListWithdraws(gomock.Any(), testUserID).
 					Return(nil, errors.New("db down")).
 					Times(1)
 			},
@@ -171,8 +171,8 @@ func TestListWithdrawHandler(t *testing.T) {
 			name: "empty list -> 204",
 			setupMock: func(m *MockWithdrawer) {
 				m.EXPECT().
-					ListWithdraws(gomock.Any()).
-					Return([]Withdraw{}, nil).
+					ListWithdraws(gomock.Any(), testUserID).
+					Return([]gophermart.Withdrawal{}, nil).
 					Times(1)
 			},
 			want: want{statusCode: http.StatusNoContent},
@@ -180,20 +180,20 @@ func TestListWithdrawHandler(t *testing.T) {
 		{
 			name: "non-empty list -> 200 and json",
 			setupMock: func(m *MockWithdrawer) {
-				ws := []Withdraw{
+				ws := []gophermart.Withdrawal{
 					{
-						OrderNumber: "12345678903",
-						Summa:       12.34,
-						ProcessedAt: RFC3339Time(time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC)),
+						Order:       "12345678903",
+						Sum:         12.34,
+						ProcessedAt: time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC),
 					},
 					{
-						OrderNumber: "55555555555",
-						Summa:       1.00,
-						ProcessedAt: RFC3339Time(time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC)),
+						Order:       "55555555555",
+						Sum:         1.00,
+						ProcessedAt: time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC),
 					},
 				}
 				m.EXPECT().
-					ListWithdraws(gomock.Any()).
+					ListWithdraws(gomock.Any(), testUserID).
 					Return(ws, nil).
 					Times(1)
 			},
@@ -222,14 +222,14 @@ func TestListWithdrawHandler(t *testing.T) {
 		{
 			name: "json is array on success",
 			setupMock: func(m *MockWithdrawer) {
-				ws := []Withdraw{
+				ws := []gophermart.Withdrawal{
 					{
-						OrderNumber: "1",
-						Summa:       1,
-						ProcessedAt: RFC3339Time(time.Now().UTC()),
+						Order:       "1",
+						Sum:         1,
+						ProcessedAt: time.Now().UTC(),
 					},
 				}
-				m.EXPECT().ListWithdraws(gomock.Any()).Return(ws, nil).Times(1)
+				m.EXPECT().ListWithdraws(gomock.Any(), testUserID).Return(ws, nil).Times(1)
 			},
 			want: want{
 				statusCode:  http.StatusOK,
@@ -261,7 +261,7 @@ func TestListWithdrawHandler(t *testing.T) {
 			h := ListWithdrawHandler(wd)
 
 			req := httptest.NewRequest(http.MethodGet, "/api/user/withdrawals", nil)
-			req = req.WithContext(context.Background())
+			req = withUser(req)
 
 			rr := httptest.NewRecorder()
 			h.ServeHTTP(rr, req)
@@ -283,3 +283,42 @@ func TestListWithdrawHandler(t *testing.T) {
 		})
 	}
 }
+
+// Обработчик передаёт сервису ровно то, что декодирует json.Decoder,
+// а на тело, которое не декодируется, отвечает 400 без обращения к сервису.
+func FuzzWithdrawHandler(f *testing.F) {
+	for _, seed := range []string{
+		`{"order":"12345678903","sum":10}`,
+		`{"order":"12345678903","sum":1e400}`,
+		`{"order":12345678903,"sum":10}`,
+		`{"sum":-0.001}`,
+		`{"order":"1"} trailing`,
+		`[]`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		var want RequestWithdraw
+		decodeErr := json.NewDecoder(bytes.NewReader(body)).Decode(&want)
+
+		ctrl := gomock.NewController(t)
+		m := NewMockWithdrawer(ctrl)
+		if decodeErr == nil {
+			m.EXPECT().Withdraw(gomock.Any(), testUserID, want.OrderNumber, want.Summa).Return(nil).Times(1)
+		}
+
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/user/balance/withdraw", bytes.NewReader(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		WithdrawHandler(m).ServeHTTP(rec, req)
+
+		wantCode := http.StatusOK
+		if decodeErr != nil {
+			wantCode = http.StatusBadRequest
+		}
+		if rec.Code != wantCode {
+			t.Fatalf("body %q: status = %d, want %d", body, rec.Code, wantCode)
+		}
+	})
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./internal/handler/withdraw.go
+// Source: github.com/IvanOplesnin/gofermart.git/internal/service (interfaces: Withdrawer)
 //
 // Generated by this command:
 //
-//	mockgen -source=./internal/handler/withdraw.go -destination=./internal/handler/withdrawer_mock_test.go -package=handler
+//	mockgen -destination=./internal/handler/withdrawer_mock_test.go -package=handler github.com/IvanOplesnin/gofermart.git/internal/service Withdrawer
 //
 
 // Package handler is a generated GoMock package.
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	gophermart "github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,30 +42,30 @@ func (m *MockWithdrawer) EXPECT() *MockWithdrawerMockRecorder {
 }
 
 // ListWithdraws mocks base method.
-func (m *MockWithdrawer) ListWithdraws(ctx context.Context) ([]Withdraw, error) {
+func (m *MockWithdrawer) ListWithdraws(ctx context.Context, userID int32) ([]gophermart.Withdrawal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListWithdraws", ctx)
-	ret0, _ := ret[0].([]Withdraw)
+	ret := m.ctrl.Call(m, "ListWithdraws", ctx, userID)
+	ret0, _ := ret[0].([]gophermart.Withdrawal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListWithdraws indicates an expected call of ListWithdraws.
-func (mr *MockWithdrawerMockRecorder) ListWithdraws(ctx any) *gomock.Call {
+func (mr *MockWithdrawerMockRecorder) ListWithdraws(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithdraws", reflect.TypeOf((*MockWithdrawer)(nil).ListWithdraws), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithdraws", reflect.TypeOf((*MockWithdrawer)(nil).ListWithdraws), ctx, userID)
 }
 
 // Withdraw mocks base method.
-func (m *MockWithdrawer) Withdraw(ctx context.Context, orderNumber string, summa float64) error {
+func (m *MockWithdrawer) Withdraw(ctx context.Context, userID int32, orderNumber string, summa float64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Withdraw", ctx, orderNumber, summa)
+	ret := m.ctrl.Call(m, "Withdraw", ctx, userID, orderNumber, summa)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Withdraw indicates an expected call of Withdraw.
-func (mr *MockWithdrawerMockRecorder) Withdraw(ctx, orderNumber, summa any) *gomock.Call {
+func (mr *MockWithdrawerMockRecorder) Withdraw(ctx, userID, orderNumber, summa any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWithdrawer)(nil).Withdraw), ctx, orderNumber, summa)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWithdrawer)(nil).Withdraw), ctx, userID, orderNumber, summa)
 }
@@ -0,0 +1,70 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BenchmarkRepo_ListPending показывает, как выборка воркера ведёт себя по мере
+// роста order_numbers. Таблица наполняется между подбенчмарками, поэтому
+// запускайте их целиком:
+//
+//	go test -tags integration -run '^$' -bench ListPending ./internal/integration/...
+func BenchmarkRepo_ListPending(b *testing.B) {
+	db := connect(b)
+	repo := psql.NewRepo(db)
+	ctx := context.Background()
+
+	userID, err := repo.AddUser(ctx, uniqueLogin(), "hash")
+	if err != nil {
+		b.Fatalf("AddUser: %v", err)
+	}
+	statuses := []string{gophermart.StatusNew, gophermart.StatusProcessing}
+
+	seeded := 0
+	for _, size := range []int{1_000, 10_000, 100_000, 1_000_000} {
+		seedOrders(b, db, userID, size-seeded)
+		seeded = size
+
+		b.Run(fmt.Sprintf("orders=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := repo.ListPending(ctx, 10, statuses, time.Now()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// seedOrders добавляет n заказов пользователя: поровну во всех статусах,
+// у половины следующая синхронизация отложена на час.
+func seedOrders(b *testing.B, db *pgxpool.Pool, userID int32, n int) {
+	b.Helper()
+	ctx := context.Background()
+	prefix := "bench" + strconv.FormatInt(seq.Add(1), 10) + "_"
+	_, err := db.Exec(ctx, `
+INSERT INTO order_numbers ("number", user_id, "status", uploaded_at, next_sync_at)
+SELECT
+    $1 || g,
+    $2,
+    (ARRAY['NEW', 'PROCESSING', 'PROCESSED', 'INVALID'])[1 + g % 4],
+    now() - g * interval '1 second',
+    CASE WHEN g % 2 = 0 THEN now() + interval '1 hour' END
+FROM generate_series(1, $3::int) AS g`, prefix, userID, n)
+	if err != nil {
+		b.Fatalf("seed orders: %v", err)
+	}
+	if _, err := db.Exec(ctx, "ANALYZE order_numbers"); err != nil {
+		b.Fatalf("analyze: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/accrualmock"
+	"github.com/IvanOplesnin/gofermart.git/pkg/client"
+)
+
+func TestFlow(t *testing.T) {
+	s := newStack(t)
+	ctx := context.Background()
+	alice := s.newUser(t)
+	bob := s.newUser(t)
+
+	processed := nextOrderNumber()
+	s.accrual.Set(processed,
+		accrualmock.Response{Status: accrualmock.StatusRegistered},
+		accrualmock.Response{Status: accrualmock.StatusProcessing},
+		accrualmock.Response{Status: accrualmock.StatusProcessed, Accrual: 729.98},
+	)
+	invalid := nextOrderNumber()
+	s.accrual.Set(invalid, accrualmock.Response{Status: accrualmock.StatusInvalid})
+
+	for _, number := range []string{processed, invalid} {
+		if again, err := alice.AddOrder(ctx, number); err != nil || again {
+			t.Fatalf("AddOrder %s = %v, %v", number, again, err)
+		}
+	}
+	if again, err := alice.AddOrder(ctx, processed); err != nil || !again {
+		t.Fatalf("AddOrder again = %v, %v", again, err)
+	}
+	if _, err := bob.AddOrder(ctx, processed); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("AddOrder of another user: err = %v", err)
+	}
+	if _, err := alice.AddOrder(ctx, "12345678900"); !errors.Is(err, client.ErrInvalidOrderNumber) {
+		t.Fatalf("AddOrder with bad checksum: err = %v", err)
+	}
+
+	waitFor(t, "orders to reach final statuses", func() bool {
+		orders, err := alice.Orders(ctx)
+		if err != nil || len(orders) != 2 {
+			return false
+		}
+		final := 0
+		for _, o := range orders {
+			if o.Status == client.StatusProcessed || o.Status == client.StatusInvalid {
+				final++
+			}
+		}
+		return final == 2
+	})
+	orders, err := alice.Orders(ctx)
+	if err != nil {
+		t.Fatalf("Orders: %v", err)
+	}
+	for _, o := range orders {
+		switch o.Number {
+		case processed:
+			if o.Status != client.StatusProcessed || o.Accrual == nil || *o.Accrual != 729.98 {
+				t.Errorf("processed order = %+v", o)
+			}
+		case invalid:
+			if o.Status != client.StatusInvalid || o.Accrual != nil {
+				t.Errorf("invalid order = %+v", o)
+			}
+		default:
+			t.Errorf("unexpected order %+v", o)
+		}
+	}
+	if n := s.accrual.Calls(processed); n < 3 {
+		t.Errorf("accrual calls for %s = %d, want at least 3", processed, n)
+	}
+
+	b, err := alice.Balance(ctx)
+	if err != nil || b != (client.Balance{Current: 729.98}) {
+		t.Fatalf("Balance = %+v, %v", b, err)
+	}
+
+	withdrawOrder := nextOrderNumber()
+	if err := alice.Withdraw(ctx, withdrawOrder, 229.98); err != nil {
+		t.Fatalf("Withdraw: %v", err)
+	}
+	if err := alice.Withdraw(ctx, nextOrderNumber(), 500.01); !errors.Is(err, client.ErrPaymentRequired) {
+		t.Fatalf("Withdraw over balance: err = %v", err)
+	}
+	if err := alice.Withdraw(ctx, withdrawOrder, 1); !errors.Is(err, client.ErrInvalidOrderNumber) {
+		t.Fatalf("Withdraw twice for one order: err = %v", err)
+	}
+
+	b, err = alice.Balance(ctx)
+	if err != nil || b != (client.Balance{Current: 500, Withdrawn: 229.98}) {
+		t.Fatalf("Balance after withdraw = %+v, %v", b, err)
+	}
+	list, err := alice.Withdrawals(ctx)
+	if err != nil || len(list) != 1 || list[0].Order != withdrawOrder || list[0].Sum != 229.98 {
+		t.Fatalf("Withdrawals = %+v, %v", list, err)
+	}
+
+	// Данные Алисы не видны Бобу.
+	if orders, err := bob.Orders(ctx); err != nil || len(orders) != 0 {
+		t.Fatalf("bob Orders = %+v, %v", orders, err)
+	}
+	if b, err := bob.Balance(ctx); err != nil || b != (client.Balance{}) {
+		t.Fatalf("bob Balance = %+v, %v", b, err)
+	}
+}
+
+func TestFlow_Login(t *testing.T) {
+	s := newStack(t)
+	ctx := context.Background()
+	login := uniqueLogin()
+
+	first, _ := client.New(s.url)
+	if err := first.Register(ctx, login, "password"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := first.Register(ctx, login, "password"); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("Register twice: err = %v", err)
+	}
+
+	second, _ := client.New(s.url)
+	if err := second.Login(ctx, login, "wrong"); !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("Login with wrong password: err = %v", err)
+	}
+	if err := second.Login(ctx, login, "password"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	number := s.earn(t, second, 10)
+	orders, err := first.Orders(ctx)
+	if err != nil || len(orders) != 1 || orders[0].Number != number {
+		t.Fatalf("Orders of the same user via the first session = %+v, %v", orders, err)
+	}
+}
+
+// Пустые списки отдаются как 204 через настоящий роутер и базу, а не как 500.
+func TestFlow_EmptyListsNoContent(t *testing.T) {
+	s := newStack(t)
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient := &http.Client{Jar: jar}
+
+	body := fmt.Sprintf(`{"login":%q,"password":"password"}`, uniqueLogin())
+	resp, err := httpClient.Post(s.url+"/api/user/register", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("register status = %d", resp.StatusCode)
+	}
+
+	for _, path := range []string{"/api/user/withdrawals", "/api/user/orders"} {
+		resp, err := httpClient.Get(s.url + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("GET %s for a fresh user: status = %d, want 204", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	migrate "github.com/IvanOplesnin/gofermart.git/migrations"
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const dsnEnv = "GOPHERMART_TEST_DATABASE_DSN"
+
+// testDSN — строка подключения к временной базе; пустая, если Postgres не задан.
+var testDSN string
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	adminDSN := os.Getenv(dsnEnv)
+	if adminDSN == "" {
+		return m.Run()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	admin, err := pgx.Connect(ctx, adminDSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "connect %s: %v\n", dsnEnv, err)
+		return 1
+	}
+	defer admin.Close(context.Background())
+
+	name := fmt.Sprintf("gophermart_it_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		fmt.Fprintf(os.Stderr, "create database: %v\n", err)
+		return 1
+	}
+	defer func() {
+		// FORCE закрывает соединения, которые тесты могли не вернуть в пул.
+		if _, err := admin.Exec(context.Background(), "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)"); err != nil {
+			fmt.Fprintf(os.Stderr, "drop database %s: %v\n", name, err)
+		}
+	}()
+
+	testDSN, err = withDatabase(adminDSN, name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := migrateUp(ctx, testDSN); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return m.Run()
+}
+
+// withDatabase подставляет имя базы в DSN: URL или строку вида "key=value".
+func withDatabase(dsn string, name string) (string, error) {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		// В keyword-формате побеждает последнее значение ключа.
+		return dsn + " dbname=" + name, nil
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", dsnEnv, err)
+	}
+	u.Path = "/" + name
+	return u.String(), nil
+}
+
+func migrateUp(ctx context.Context, dsn string) error {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	m, err := migrate.New(db)
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+	defer m.Close()
+	if _, err := m.Up(ctx); err != nil {
+		return err
+	}
+	return m.Check(ctx)
+}
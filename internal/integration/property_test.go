@@ -0,0 +1,214 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+// propertySeed берёт зерно из GOPHERMART_TEST_SEED, чтобы повторить упавший прогон.
+func propertySeed(t *testing.T) uint64 {
+	t.Helper()
+	seed := uint64(time.Now().UnixNano())
+	if v := os.Getenv("GOPHERMART_TEST_SEED"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			t.Fatalf("GOPHERMART_TEST_SEED: %v", err)
+		}
+		seed = parsed
+	}
+	t.Logf("seed %d (GOPHERMART_TEST_SEED)", seed)
+	return seed
+}
+
+// moneyModel — ожидаемое состояние счёта одного пользователя в копейках.
+type moneyModel struct {
+	accrued, balance, withdrawn int64
+	pending, processed          []string
+	withdrawOrders              []string
+}
+
+// Случайные последовательности начислений и списаний сверяются с моделью
+// после каждой операции: сумма списаний никогда не превышает начислений.
+func TestRepo_MoneyModel(t *testing.T) {
+	repo := psql.NewRepo(connect(t))
+	seed := propertySeed(t)
+
+	const (
+		users = 8
+		steps = 150
+	)
+	var wg sync.WaitGroup
+	for u := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runMoneyModel(t, repo, rand.New(rand.NewPCG(seed, uint64(u))), steps)
+		}()
+	}
+	wg.Wait()
+}
+
+func runMoneyModel(t *testing.T, repo *psql.Repo, rnd *rand.Rand, steps int) {
+	ctx := context.Background()
+	userID, err := repo.AddUser(ctx, uniqueLogin(), "hash")
+	if err != nil {
+		t.Errorf("AddUser: %v", err)
+		return
+	}
+	var m moneyModel
+	pick := func(list []string) (string, int) {
+		i := rnd.IntN(len(list))
+		return list[i], i
+	}
+
+	for step := range steps {
+		switch op := rnd.IntN(5); {
+		case op == 0 || len(m.pending) == 0:
+			number := nextOrderNumber()
+			if created, _, err := repo.CreateOrder(ctx, userID, number); err != nil || !created {
+				t.Errorf("step %d: CreateOrder = %v, %v", step, created, err)
+				return
+			}
+			m.pending = append(m.pending, number)
+		case op == 1:
+			number, i := pick(m.pending)
+			cents := 1 + rnd.Int64N(50_000)
+			if err := repo.ApplyAccrual(ctx, number, cents, userID); err != nil {
+				t.Errorf("step %d: ApplyAccrual: %v", step, err)
+				return
+			}
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			m.processed = append(m.processed, number)
+			m.accrued += cents
+			m.balance += cents
+		case op == 2 && len(m.processed) > 0:
+			// Повторное начисление по обработанному заказу ничего не меняет.
+			number, _ := pick(m.processed)
+			if err := repo.ApplyAccrual(ctx, number, 1+rnd.Int64N(50_000), userID); err != nil {
+				t.Errorf("step %d: repeated ApplyAccrual: %v", step, err)
+				return
+			}
+		case op == 3 && len(m.withdrawOrders) > 0:
+			number, _ := pick(m.withdrawOrders)
+			if err := repo.Withdraw(ctx, userID, 1, number); !errors.Is(err, gophermart.ErrWithdrawAlreadyProcessed) {
+				t.Errorf("step %d: Withdraw by used order: err = %v", step, err)
+				return
+			}
+		default:
+			// Суммы и в пределах баланса, и сверх него.
+			sum := 1 + rnd.Int64N(m.balance+m.balance/2+100)
+			number := nextOrderNumber()
+			err := repo.Withdraw(ctx, userID, int32(sum), number)
+			switch {
+			case sum <= m.balance && err == nil:
+				m.balance -= sum
+				m.withdrawn += sum
+				m.withdrawOrders = append(m.withdrawOrders, number)
+			case sum > m.balance && errors.Is(err, gophermart.ErrNotEnoughBalance):
+			default:
+				t.Errorf("step %d: Withdraw(%d) with balance %d: err = %v", step, sum, m.balance, err)
+				return
+			}
+		}
+
+		b, err := repo.Balance(ctx, userID)
+		if err != nil {
+			t.Errorf("step %d: Balance: %v", step, err)
+			return
+		}
+		if int64(b.Balance) != m.balance || int64(b.Withdraw) != m.withdrawn || m.withdrawn > m.accrued {
+			t.Errorf("step %d: balance %d/%d, model %d/%d, accrued %d",
+				step, b.Balance, b.Withdraw, m.balance, m.withdrawn, m.accrued)
+			return
+		}
+	}
+
+	withdraws, err := repo.ListWithdraws(ctx, userID)
+	if err != nil {
+		t.Errorf("ListWithdraws: %v", err)
+		return
+	}
+	var sum int64
+	for _, w := range withdraws {
+		sum += int64(w.Summa)
+	}
+	if sum != m.withdrawn || len(withdraws) != len(m.withdrawOrders) {
+		t.Errorf("withdrawals sum %d in %d rows, model %d in %d", sum, len(withdraws), m.withdrawn, len(m.withdrawOrders))
+	}
+}
+
+// Без модели, но со всей конкуренцией: несколько горутин одного пользователя
+// в случайном порядке начисляют и списывают, а в конце списано не больше,
+// чем начислено, и баланс сходится до копейки.
+func TestRepo_MoneyInvariantConcurrent(t *testing.T) {
+	repo := psql.NewRepo(connect(t))
+	seed := propertySeed(t)
+	ctx := context.Background()
+
+	userID, err := repo.AddUser(ctx, uniqueLogin(), "hash")
+	if err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		accrued atomic.Int64
+	)
+	for g := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rnd := rand.New(rand.NewPCG(seed, uint64(g)))
+			for range 60 {
+				if rnd.IntN(3) == 0 {
+					number := nextOrderNumber()
+					cents := 1 + rnd.Int64N(5_000)
+					if _, _, err := repo.CreateOrder(ctx, userID, number); err != nil {
+						t.Errorf("CreateOrder: %v", err)
+						return
+					}
+					if err := repo.ApplyAccrual(ctx, number, cents, userID); err != nil {
+						t.Errorf("ApplyAccrual: %v", err)
+						return
+					}
+					accrued.Add(cents)
+					continue
+				}
+				err := repo.Withdraw(ctx, userID, int32(1+rnd.Int64N(3_000)), nextOrderNumber())
+				if err != nil && !errors.Is(err, gophermart.ErrNotEnoughBalance) {
+					t.Errorf("Withdraw: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	b, err := repo.Balance(ctx, userID)
+	if err != nil {
+		t.Fatalf("Balance: %v", err)
+	}
+	withdraws, err := repo.ListWithdraws(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListWithdraws: %v", err)
+	}
+	var withdrawn int64
+	for _, w := range withdraws {
+		withdrawn += int64(w.Summa)
+	}
+	if withdrawn > accrued.Load() || withdrawn != int64(b.Withdraw) || int64(b.Balance) != accrued.Load()-withdrawn {
+		t.Fatalf("accrued %d, withdrawals %d, balance %d/%d", accrued.Load(), withdrawn, b.Balance, b.Withdraw)
+	}
+}
@@ -0,0 +1,165 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	accrualclient "github.com/IvanOplesnin/gofermart.git/internal/accrual_client"
+	"github.com/IvanOplesnin/gofermart.git/internal/accrualmock"
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/events"
+	"github.com/IvanOplesnin/gofermart.git/internal/handler"
+	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/hasher"
+	"github.com/IvanOplesnin/gofermart.git/pkg/client"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// stack — gophermart, собранный как в cmd/gophermart, поверх временной базы
+// и заглушки системы начислений.
+type stack struct {
+	repo    *psql.Repo
+	accrual *accrualmock.Server
+	url     string
+}
+
+// connect открывает пул к временной базе и закрывает его по окончании теста.
+func connect(tb testing.TB) *pgxpool.Pool {
+	tb.Helper()
+	if testDSN == "" {
+		tb.Skipf("%s is not set", dsnEnv)
+	}
+	cfg := config.Default().Database
+	cfg.DSN = testDSN
+	cfg.ConnectRetries = 0
+	db, err := psql.Connect(context.Background(), cfg)
+	if err != nil {
+		tb.Fatalf("connect: %v", err)
+	}
+	tb.Cleanup(db.Close)
+	return db
+}
+
+func newStack(t *testing.T) *stack {
+	t.Helper()
+	db := connect(t)
+
+	accrual, accrualSrv := accrualmock.NewTestServer(accrualmock.Config{})
+	t.Cleanup(accrualSrv.Close)
+
+	cfg := config.Default()
+	cfg.Auth.Secret = "integration-secret"
+	cfg.Accrual.Address = accrualSrv.URL
+	cfg.Accrual.Timeout = 2 * time.Second
+	cfg.Worker.PollInterval = 20 * time.Millisecond
+	cfg.Worker.SyncInterval = 20 * time.Millisecond
+
+	repo := psql.NewRepo(db)
+
+	accrualClient := accrualclient.NewFromConfig(cfg.Accrual)
+	svc, err := gophermart.New(cfg, gophermart.ServiceDeps{
+		Hasher:        hasher.NewSHA256(),
+		UserCRUD:      repo,
+		WorkerDB:      repo,
+		Ordered:       repo,
+		AccrualClient: accrualClient,
+		AccrualHealth: accrualClient.Breaker(),
+		BalanceDB:     repo,
+		WithdrawerDB:  repo,
+		WebhookDB:     repo,
+		EventsDB:      repo,
+		Events:        events.NewBroker(repo),
+	})
+	if err != nil {
+		t.Fatalf("gophermart.New: %v", err)
+	}
+	svc.Start()
+	t.Cleanup(svc.Stop)
+
+	api := httptest.NewServer(handler.InitHandler(handler.HandlerDeps{
+		Reqistrar:    svc,
+		Auther:       svc,
+		TokenChecker: svc,
+		Ordered:      svc,
+		Balancer:     svc,
+		Withdrawer:   svc,
+		Webhooks:     svc,
+		Events:       svc,
+
+		ValidateRequests: true,
+		Cookies:          handler.CookieOptions{CSRF: true},
+	}))
+	t.Cleanup(api.Close)
+
+	return &stack{repo: repo, accrual: accrual, url: api.URL}
+}
+
+// newUser регистрирует пользователя с уникальным логином и возвращает клиент.
+func (s *stack) newUser(t *testing.T) *client.Client {
+	t.Helper()
+	c, err := client.New(s.url)
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+	if err := c.Register(context.Background(), uniqueLogin(), "password"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return c
+}
+
+// earn загружает заказ с начислением points и ждёт, пока баллы дойдут до баланса.
+func (s *stack) earn(t *testing.T, c *client.Client, points float64) string {
+	t.Helper()
+	ctx := context.Background()
+	before, err := c.Balance(ctx)
+	if err != nil {
+		t.Fatalf("Balance: %v", err)
+	}
+	number := nextOrderNumber()
+	s.accrual.Set(number, accrualmock.Response{Status: accrualmock.StatusProcessed, Accrual: points})
+	if _, err := c.AddOrder(ctx, number); err != nil {
+		t.Fatalf("AddOrder %s: %v", number, err)
+	}
+	waitFor(t, "accrual for "+number, func() bool {
+		b, err := c.Balance(ctx)
+		return err == nil && b.Current == before.Current+points
+	})
+	return number
+}
+
+// waitFor опрашивает cond, пока она не станет истинной; воркер начислений асинхронный.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+var seq atomic.Int64
+
+func init() {
+	// База общая для всех тестов запуска, а -count=N повторяет их в ней же:
+	// начинаем последовательность со времени, чтобы номера не пересекались.
+	seq.Store(time.Now().UnixMilli() % 1e9 * 1000)
+}
+
+func uniqueLogin() string {
+	return fmt.Sprintf("user_%d", seq.Add(1))
+}
+
+// nextOrderNumber возвращает ещё не использованный номер, проходящий проверку Луна.
+func nextOrderNumber() string {
+	return gophermart.WithCheckDigit(strconv.FormatInt(seq.Add(1), 10))
+}
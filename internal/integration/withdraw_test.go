@@ -0,0 +1,195 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/IvanOplesnin/gofermart.git/pkg/client"
+)
+
+// Конкурентные списания с разными номерами заказов не уводят баланс в минус:
+// проходит ровно столько, на сколько хватает баллов.
+func TestWithdraw_ConcurrentNoOverdraw(t *testing.T) {
+	s := newStack(t)
+	ctx := context.Background()
+	c := s.newUser(t)
+	s.earn(t, c, 100)
+
+	const (
+		workers = 30
+		sum     = 7
+	)
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.Withdraw(ctx, nextOrderNumber(), sum)
+			if err != nil && !errors.Is(err, client.ErrPaymentRequired) {
+				t.Errorf("Withdraw: unexpected error %v", err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if want := 100 / sum; succeeded != want {
+		t.Fatalf("succeeded = %d, want %d", succeeded, want)
+	}
+	b, err := c.Balance(ctx)
+	if err != nil {
+		t.Fatalf("Balance: %v", err)
+	}
+	if want := (client.Balance{Current: 100 - float64(succeeded*sum), Withdrawn: float64(succeeded * sum)}); b != want {
+		t.Fatalf("Balance = %+v, want %+v", b, want)
+	}
+	list, err := c.Withdrawals(ctx)
+	if err != nil || len(list) != succeeded {
+		t.Fatalf("Withdrawals: %d items, %v; want %d", len(list), err, succeeded)
+	}
+}
+
+// Повторное списание по одному заказу откатывает транзакцию целиком:
+// баллы за проигравшие запросы не пропадают с баланса.
+func TestWithdraw_ConcurrentSameOrder(t *testing.T) {
+	s := newStack(t)
+	ctx := context.Background()
+	c := s.newUser(t)
+	s.earn(t, c, 50)
+
+	order := nextOrderNumber()
+	errs := make([]error, 20)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.Withdraw(ctx, order, 5)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, client.ErrInvalidOrderNumber):
+			t.Errorf("Withdraw: unexpected error %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("succeeded = %d, want 1", succeeded)
+	}
+	b, err := c.Balance(ctx)
+	if err != nil || b != (client.Balance{Current: 45, Withdrawn: 5}) {
+		t.Fatalf("Balance = %+v, %v", b, err)
+	}
+}
+
+// Повторная доставка одного начисления (воркер и callback одновременно)
+// зачисляет баллы один раз.
+func TestRepo_ApplyAccrualOnce(t *testing.T) {
+	s := newStack(t)
+	ctx := context.Background()
+
+	userID, err := s.repo.AddUser(ctx, uniqueLogin(), "hash")
+	if err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+	// Номер без сценария: заглушка отвечает 204, и воркер заказ не трогает.
+	number := nextOrderNumber()
+	if created, _, err := s.repo.CreateOrder(ctx, userID, number); err != nil || !created {
+		t.Fatalf("CreateOrder = %v, %v", created, err)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.repo.ApplyAccrual(ctx, number, 1250, userID); err != nil {
+				t.Errorf("ApplyAccrual: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	b, err := s.repo.Balance(ctx, userID)
+	if err != nil || b.Balance != 1250 || b.Withdraw != 0 {
+		t.Fatalf("Balance = %+v, %v", b, err)
+	}
+	order, err := s.repo.OrderByNumber(ctx, number)
+	if err != nil || order.OrderStatus != gophermart.StatusProcessed {
+		t.Fatalf("OrderByNumber = %+v, %v", order, err)
+	}
+}
+
+// Один номер, загружаемый несколькими пользователями одновременно,
+// достаётся ровно одному из них.
+func TestRepo_CreateOrderRace(t *testing.T) {
+	s := newStack(t)
+	ctx := context.Background()
+
+	users := make([]int32, 8)
+	for i := range users {
+		id, err := s.repo.AddUser(ctx, uniqueLogin(), "hash")
+		if err != nil {
+			t.Fatalf("AddUser: %v", err)
+		}
+		users[i] = id
+	}
+	number := nextOrderNumber()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		creators []int32
+		seen     = map[int32]bool{}
+	)
+	for _, id := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			created, owner, err := s.repo.CreateOrder(ctx, id, number)
+			if err != nil {
+				t.Errorf("CreateOrder: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if created {
+				creators = append(creators, id)
+			} else {
+				seen[owner] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(creators) != 1 {
+		t.Fatalf("creators = %v, want exactly one", creators)
+	}
+	delete(seen, creators[0])
+	if len(seen) != 0 {
+		t.Fatalf("losers saw other owners %v, want %d", seen, creators[0])
+	}
+	order, err := s.repo.OrderByNumber(ctx, number)
+	if err != nil || order.UserID != creators[0] {
+		t.Fatalf("OrderByNumber = %+v, %v; want owner %d", order, err, creators[0])
+	}
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ctxKey struct{}
+
+type accessKey struct{}
+
+// WithEntry кладёт в контекст логгер, привязанный к запросу или батчу воркера.
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// FromContext возвращает логгер из контекста, а если его нет — глобальный Log.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(ctxKey{}).(*logrus.Entry); ok && entry != nil {
+			return entry
+		}
+	}
+	return logrus.NewEntry(Log)
+}
+
+// WithField добавляет поле к логгеру из контекста.
+func WithField(ctx context.Context, key string, value any) context.Context {
+	return WithEntry(ctx, FromContext(ctx).WithField(key, value))
+}
+
+// AccessFields — поля итоговой строки журнала запроса. Middleware, которое
+// пишет эту строку, создаёт набор до вызова обработчиков, а обработчики ниже
+// по цепочке дописывают в него то, что узнали (например, user_id).
+type AccessFields struct {
+	mu     sync.Mutex
+	fields logrus.Fields
+}
+
+// WithAccessFields кладёт в контекст пустой набор полей строки журнала запроса.
+func WithAccessFields(ctx context.Context) (context.Context, *AccessFields) {
+	f := &AccessFields{fields: logrus.Fields{}}
+	return context.WithValue(ctx, accessKey{}, f), f
+}
+
+// SetAccessField добавляет поле в строку журнала запроса. Без WithAccessFields
+// выше по цепочке ничего не делает.
+func SetAccessField(ctx context.Context, key string, value any) {
+	f, ok := ctx.Value(accessKey{}).(*AccessFields)
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	f.fields[key] = value
+	f.mu.Unlock()
+}
+
+// Fields возвращает копию накопленных полей.
+func (f *AccessFields) Fields() logrus.Fields {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := make(logrus.Fields, len(f.fields))
+	for k, v := range f.fields {
+		res[k] = v
+	}
+	return res
+}
+
+// NewID генерирует случайный идентификатор для корреляции логов.
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,127 @@
+// Package metrics — минимальный реестр метрик в текстовом формате Prometheus.
+// Метрики снимаются коллекторами в момент запроса, поэтому значения всегда актуальны.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentTypeValue = "text/plain; version=0.0.4; charset=utf-8"
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Collector пишет свои метрики в Writer при каждом снятии.
+type Collector func(w *Writer)
+
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default — реестр процесса, его отдаёт служебный маршрут /internal/metrics.
+var Default = NewRegistry()
+
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+func (r *Registry) WriteTo(out io.Writer) (int64, error) {
+	r.mu.RLock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.RUnlock()
+
+	w := &Writer{seen: make(map[string]bool)}
+	for _, c := range collectors {
+		c(w)
+	}
+	n, err := io.WriteString(out, w.b.String())
+	return int64(n), err
+}
+
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentTypeValue)
+		_, _ = r.WriteTo(w)
+	}
+}
+
+type Writer struct {
+	b    strings.Builder
+	seen map[string]bool
+}
+
+func (w *Writer) Gauge(name string, help string, value float64, labels ...Label) {
+	w.write(name, "gauge", help, value, labels)
+}
+
+func (w *Writer) Counter(name string, help string, value float64, labels ...Label) {
+	w.write(name, "counter", help, value, labels)
+}
+
+func (w *Writer) write(name string, kind string, help string, value float64, labels []Label) {
+	if !w.seen[name] {
+		w.seen[name] = true
+		fmt.Fprintf(&w.b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	w.b.WriteString(name)
+	if len(labels) > 0 {
+		sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+		w.b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			fmt.Fprintf(&w.b, "%s=%q", l.Name, l.Value)
+		}
+		w.b.WriteByte('}')
+	}
+	w.b.WriteByte(' ')
+	w.b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	w.b.WriteByte('\n')
+}
+
+// Counter — потокобезопасный монотонный счётчик для событий, которые нельзя снять коллектором.
+type Counter struct {
+	mu    sync.Mutex
+	value map[string]float64
+}
+
+func NewCounter() *Counter {
+	return &Counter{value: make(map[string]float64)}
+}
+
+func (c *Counter) Inc(key string) {
+	c.Add(key, 1)
+}
+
+func (c *Counter) Add(key string, delta float64) {
+	c.mu.Lock()
+	c.value[key] += delta
+	c.mu.Unlock()
+}
+
+// Snapshot возвращает копию значений по ключам.
+func (c *Counter) Snapshot() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]float64, len(c.value))
+	for k, v := range c.value {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	hits := NewCounter()
+	hits.Inc("ok")
+	hits.Add("ok", 2)
+	hits.Inc("fail")
+
+	r.Register(func(w *Writer) {
+		w.Gauge("test_pool_conns", "Open connections.", 3, Label{Name: "state", Value: "idle"})
+		w.Gauge("test_pool_conns", "Open connections.", 1, Label{Name: "state", Value: "acquired"})
+	})
+	r.Register(func(w *Writer) {
+		for k, v := range hits.Snapshot() {
+			w.Counter("test_hits_total", "Hits.", v, Label{Name: "result", Value: k})
+		}
+	})
+
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/internal/metrics", nil))
+	body := rr.Body.String()
+
+	for _, want := range []string{
+		"# TYPE test_pool_conns gauge\n",
+		`test_pool_conns{state="idle"} 3`,
+		`test_pool_conns{state="acquired"} 1`,
+		"# TYPE test_hits_total counter\n",
+		`test_hits_total{result="ok"} 3`,
+		`test_hits_total{result="fail"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in:\n%s", want, body)
+		}
+	}
+	if strings.Count(body, "# HELP test_pool_conns") != 1 {
+		t.Fatalf("HELP must be written once per metric:\n%s", body)
+	}
+}
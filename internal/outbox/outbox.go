@@ -0,0 +1,73 @@
+// Package outbox доставляет доменные события, записанные в таблицу outbox
+// в одной транзакции с изменением данных. Relay читает неотправленные события
+// и публикует их в Sink; доставка «хотя бы один раз», получатель должен
+// отбрасывать повторы по Event.ID.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Типы событий. Значения — часть контракта с получателями и не должны меняться.
+const (
+	TypeOrderProcessing   = "order.processing"
+	TypeOrderProcessed    = "order.processed"
+	TypeOrderInvalid      = "order.invalid"
+	TypeBalanceCredited   = "balance.credited"
+	TypeWithdrawalCreated = "withdrawal.created"
+)
+
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	UserID    int32           `json:"user_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	// Attempts — число неудачных попыток доставки до текущей.
+	Attempts int32 `json:"-"`
+}
+
+// OrderPayload — тело order.processed и order.invalid. Суммы в баллах.
+type OrderPayload struct {
+	Order   string  `json:"order"`
+	Accrual float64 `json:"accrual,omitempty"`
+}
+
+// BalancePayload — тело balance.credited.
+type BalancePayload struct {
+	Order  string  `json:"order"`
+	Amount float64 `json:"amount"`
+}
+
+// WithdrawalPayload — тело withdrawal.created.
+type WithdrawalPayload struct {
+	Order string  `json:"order"`
+	Sum   float64 `json:"sum"`
+}
+
+// Sink публикует событие. Ошибка означает, что событие будет отправлено повторно.
+type Sink interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Delivery — результат публикации одного события. Нулевой Err — событие доставлено,
+// иначе следующая попытка не раньше RetryAt.
+type Delivery struct {
+	ID      int64
+	Err     error
+	RetryAt time.Time
+}
+
+// Store — хранилище событий.
+type Store interface {
+	// LeaseOutbox выдаёт до limit событий, готовых к отправке на момент now, и
+	// откладывает их до leaseUntil. Выданные события другие экземпляры relay не
+	// получат, а если экземпляр упадёт, их отправит другой после leaseUntil.
+	LeaseOutbox(ctx context.Context, limit int32, now time.Time, leaseUntil time.Time) ([]Event, error)
+	// SaveOutboxDeliveries сохраняет результаты публикации одной транзакцией.
+	SaveOutboxDeliveries(ctx context.Context, deliveries []Delivery) error
+	// PurgeOutbox удаляет доставленные события старше before.
+	PurgeOutbox(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,211 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/metrics"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultRetryBackoff = time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultLease        = time.Minute
+	saveTimeout         = 5 * time.Second
+	purgeInterval       = time.Hour
+)
+
+type Options struct {
+	PollInterval time.Duration
+	BatchSize    int32
+	// RetryBackoff удваивается с каждой неудачной попыткой, но не превышает MaxBackoff.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	// Lease — на сколько выданная пачка скрыта от других экземпляров relay.
+	// Должна покрывать публикацию всей пачки, иначе события уйдут повторно.
+	Lease time.Duration
+	// Retention — сколько хранить доставленные события; 0 — не удалять.
+	Retention time.Duration
+}
+
+// Relay периодически забирает события из Store и публикует их в Sink.
+type Relay struct {
+	store Store
+	sink  Sink
+	opts  Options
+
+	published *metrics.Counter
+	failed    *metrics.Counter
+
+	cancel   func()
+	wg       sync.WaitGroup
+	runOnce  sync.Once
+	stopOnce sync.Once
+
+	now func() time.Time
+}
+
+func NewRelay(store Store, sink Sink, opts Options) *Relay {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = defaultLease
+	}
+	return &Relay{
+		store:     store,
+		sink:      sink,
+		opts:      opts,
+		published: metrics.NewCounter(),
+		failed:    metrics.NewCounter(),
+		now:       time.Now,
+	}
+}
+
+func (r *Relay) Run() {
+	r.runOnce.Do(func() {
+		ctx, cancel := context.WithCancel(logger.WithField(context.Background(), "component", "outbox.relay"))
+		r.cancel = cancel
+		r.wg.Add(1)
+		go r.loop(ctx)
+	})
+	logger.Log.Info("run outbox.relay")
+}
+
+// Stop дожидается окончания текущей пачки. Недоставленные события останутся
+// в outbox и будут отправлены после перезапуска.
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		if r.cancel != nil {
+			r.cancel()
+		}
+	})
+	r.wg.Wait()
+	logger.Log.Info("stop outbox.relay")
+}
+
+func (r *Relay) loop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	lastPurge := r.now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.drain(ctx)
+		if r.opts.Retention > 0 && r.now().Sub(lastPurge) >= purgeInterval {
+			lastPurge = r.now()
+			r.purge(ctx)
+		}
+	}
+}
+
+// drain отправляет пачки, пока они приходят полными. Транзакции короткие:
+// пачка выдаётся в аренду, публикуется без открытой транзакции, а результаты
+// сохраняются отдельно.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := r.now()
+		events, err := r.store.LeaseOutbox(ctx, r.opts.BatchSize, now, now.Add(r.opts.Lease))
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.FromContext(ctx).Errorf("outbox.relay: %s", err.Error())
+			}
+			return
+		}
+		if err := r.save(ctx, r.publish(ctx, events)); err != nil {
+			logger.FromContext(ctx).Errorf("outbox.relay: %s", err.Error())
+			return
+		}
+		if len(events) < int(r.opts.BatchSize) {
+			return
+		}
+	}
+}
+
+// save сохраняет результаты и после отмены ctx, иначе доставленные события
+// ушли бы повторно. Неотправленные события дождутся конца аренды.
+func (r *Relay) save(ctx context.Context, deliveries []Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+	defer cancel()
+	return r.store.SaveOutboxDeliveries(ctx, deliveries)
+}
+
+// publish отправляет события по порядку. После отмены ctx оставшиеся события
+// не отправляются и не считаются неудачными.
+func (r *Relay) publish(ctx context.Context, events []Event) []Delivery {
+	res := make([]Delivery, 0, len(events))
+	for _, e := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		err := r.sink.Publish(ctx, e)
+		if err == nil {
+			r.published.Inc(e.Type)
+			res = append(res, Delivery{ID: e.ID})
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		r.failed.Inc(e.Type)
+		logger.FromContext(ctx).WithField("event_id", e.ID).WithField("event_type", e.Type).
+			Warnf("outbox.relay: publish failed (attempt %d): %s", e.Attempts+1, err.Error())
+		res = append(res, Delivery{ID: e.ID, Err: err, RetryAt: r.now().Add(r.backoff(e.Attempts))})
+	}
+	return res
+}
+
+func (r *Relay) backoff(attempts int32) time.Duration {
+	d := r.opts.RetryBackoff
+	for i := int32(0); i < attempts && d < r.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.opts.MaxBackoff)
+}
+
+func (r *Relay) purge(ctx context.Context) {
+	n, err := r.store.PurgeOutbox(ctx, r.now().Add(-r.opts.Retention))
+	if err != nil {
+		logger.FromContext(ctx).Errorf("outbox.relay purge: %s", err.Error())
+		return
+	}
+	if n > 0 {
+		logger.FromContext(ctx).Debugf("outbox.relay: purged %d events", n)
+	}
+}
+
+// Collector отдаёт счётчики доставленных и неудачных публикаций по типу события.
+func (r *Relay) Collector() metrics.Collector {
+	return func(w *metrics.Writer) {
+		for typ, v := range r.published.Snapshot() {
+			w.Counter("outbox_published_total", "Outbox events delivered to the sink.", v,
+				metrics.Label{Name: "type", Value: typ})
+		}
+		for typ, v := range r.failed.Snapshot() {
+			w.Counter("outbox_failed_total", "Failed outbox publish attempts.", v,
+				metrics.Label{Name: "type", Value: typ})
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type memStore struct {
+	events []Event
+	done   map[int64]bool
+	retry  map[int64]time.Time
+	claims int
+	saves  int
+}
+
+func (s *memStore) LeaseOutbox(_ context.Context, limit int32, now time.Time, leaseUntil time.Time) ([]Event, error) {
+	s.claims++
+	var batch []Event
+	for _, e := range s.events {
+		if len(batch) == int(limit) {
+			break
+		}
+		if s.done[e.ID] || s.retry[e.ID].After(now) {
+			continue
+		}
+		s.retry[e.ID] = leaseUntil
+		batch = append(batch, e)
+	}
+	return batch, nil
+}
+
+func (s *memStore) SaveOutboxDeliveries(ctx context.Context, deliveries []Delivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.saves++
+	for _, d := range deliveries {
+		if d.Err == nil {
+			s.done[d.ID] = true
+		} else {
+			s.retry[d.ID] = d.RetryAt
+		}
+	}
+	return nil
+}
+
+func (s *memStore) PurgeOutbox(context.Context, time.Time) (int64, error) { return 0, nil }
+
+type sinkFunc func(ctx context.Context, e Event) error
+
+func (f sinkFunc) Publish(ctx context.Context, e Event) error { return f(ctx, e) }
+
+func newStore(n int) *memStore {
+	s := &memStore{done: map[int64]bool{}, retry: map[int64]time.Time{}}
+	for i := 1; i <= n; i++ {
+		s.events = append(s.events, Event{ID: int64(i), Type: TypeOrderProcessed})
+	}
+	return s
+}
+
+func TestRelay_DrainPublishesAllBatches(t *testing.T) {
+	store := newStore(5)
+	var got []int64
+	r := NewRelay(store, sinkFunc(func(_ context.Context, e Event) error {
+		got = append(got, e.ID)
+		return nil
+	}), Options{BatchSize: 2})
+
+	r.drain(context.Background())
+
+	if len(got) != 5 {
+		t.Fatalf("published %v, want 5 events", got)
+	}
+	for i, id := range got {
+		if id != int64(i+1) {
+			t.Fatalf("published out of order: %v", got)
+		}
+	}
+	// 2 + 2 + 1: неполная пачка завершает проход.
+	if store.claims != 3 {
+		t.Errorf("claims = %d, want 3", store.claims)
+	}
+}
+
+func TestRelay_FailedEventIsRetriedLater(t *testing.T) {
+	store := newStore(2)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	fail := true
+	r := NewRelay(store, sinkFunc(func(_ context.Context, e Event) error {
+		if e.ID == 1 && fail {
+			return errors.New("sink down")
+		}
+		return nil
+	}), Options{BatchSize: 10, RetryBackoff: time.Second})
+	r.now = func() time.Time { return now }
+
+	r.drain(context.Background())
+	if store.done[1] || !store.done[2] {
+		t.Fatalf("done = %v, want only event 2", store.done)
+	}
+	if want := now.Add(time.Second); !store.retry[1].Equal(want) {
+		t.Fatalf("retry at %s, want %s", store.retry[1], want)
+	}
+
+	fail = false
+	r.drain(context.Background())
+	if store.done[1] {
+		t.Fatal("event 1 retried before its backoff expired")
+	}
+
+	now = now.Add(time.Second)
+	r.drain(context.Background())
+	if !store.done[1] {
+		t.Fatal("event 1 not retried after backoff")
+	}
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	r := NewRelay(newStore(0), sinkFunc(nil), Options{RetryBackoff: time.Second, MaxBackoff: 10 * time.Second})
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := r.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRelay_CancelledPublishIsNotAFailure(t *testing.T) {
+	store := newStore(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewRelay(store, sinkFunc(func(ctx context.Context, e Event) error {
+		if e.ID == 2 {
+			cancel()
+			return ctx.Err()
+		}
+		return nil
+	}), Options{BatchSize: 10})
+
+	res := r.publish(ctx, store.events)
+
+	if len(res) != 1 || res[0].ID != 1 || res[0].Err != nil {
+		t.Fatalf("deliveries = %+v, want only event 1 delivered", res)
+	}
+}
+
+func TestRelay_LeasedEventsAreHiddenUntilLeaseExpires(t *testing.T) {
+	store := newStore(2)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []int64
+	r := NewRelay(store, sinkFunc(func(ctx context.Context, e Event) error {
+		got = append(got, e.ID)
+		if e.ID == 1 {
+			// Остановка relay посреди пачки: событие 2 остаётся в аренде.
+			cancel()
+		}
+		return nil
+	}), Options{BatchSize: 10, Lease: time.Minute})
+	r.now = func() time.Time { return now }
+
+	r.drain(ctx)
+	if !store.done[1] {
+		t.Fatal("event published before cancellation must be saved")
+	}
+
+	r.drain(context.Background())
+	if len(got) != 1 {
+		t.Fatalf("published %v: leased event 2 must not be sent before the lease expires", got)
+	}
+
+	now = now.Add(time.Minute)
+	r.drain(context.Background())
+	if len(got) != 2 || got[1] != 2 || !store.done[2] {
+		t.Fatalf("published %v, done %v: event 2 must be sent after the lease", got, store.done)
+	}
+}
+
+func TestRelay_EmptyBatchIsNotSaved(t *testing.T) {
+	store := newStore(0)
+	r := NewRelay(store, sinkFunc(nil), Options{BatchSize: 10})
+
+	r.drain(context.Background())
+	if store.saves != 0 {
+		t.Fatalf("saves = %d, want 0", store.saves)
+	}
+}
@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/signature"
+)
+
+const (
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+
+	defaultWebhookTimeout = 10 * time.Second
+
+	EventIDHeader   = "X-Event-ID"
+	EventTypeHeader = "X-Event-Type"
+)
+
+// WriterSink пишет события построчно в JSON. Подходит для локального запуска.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Publish(_ context.Context, e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("outbox.WriterSink: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("outbox.WriterSink: %w", err)
+	}
+	return nil
+}
+
+// WebhookSink отправляет событие POST-запросом с телом Event. Тело подписано
+// HMAC-SHA256 в заголовке X-Signature; любой ответ кроме 2xx считается неудачей.
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhookSink(url string, secret []byte, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	return &WebhookSink{url: url, secret: secret, client: client}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("outbox.WebhookSink: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("outbox.WebhookSink: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, strconv.FormatInt(e.ID, 10))
+	req.Header.Set(EventTypeHeader, e.Type)
+	if len(s.secret) > 0 {
+		req.Header.Set(signature.Header, signature.Sign(s.secret, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("outbox.WebhookSink: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("outbox.WebhookSink: status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NewSink собирает sink по конфигу. Пустой config.Outbox.Sink возвращает nil:
+// события копятся в outbox, пока sink не настроен. close освобождает файл.
+func NewSink(cfg config.Outbox) (sink Sink, close func() error, err error) {
+	noop := func() error { return nil }
+	switch cfg.Sink {
+	case "":
+		return nil, noop, nil
+	case SinkStdout:
+		return NewWriterSink(os.Stdout), noop, nil
+	case SinkFile:
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, noop, fmt.Errorf("outbox.NewSink: %w", err)
+		}
+		return NewWriterSink(f), f.Close, nil
+	case SinkWebhook:
+		return NewWebhookSink(cfg.WebhookURL, []byte(cfg.WebhookSecret), nil), noop, nil
+	default:
+		return nil, noop, fmt.Errorf("outbox.NewSink: unknown sink %q", cfg.Sink)
+	}
+}
+
+// OptionsFromConfig переносит параметры relay из конфига.
+func OptionsFromConfig(cfg config.Outbox) Options {
+	return Options{
+		PollInterval: cfg.PollInterval,
+		BatchSize:    cfg.BatchSize,
+		RetryBackoff: cfg.RetryBackoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		Lease:        cfg.Lease,
+		Retention:    cfg.Retention,
+	}
+}
+
+// Fanout публикует событие во все sink по очереди. Если хотя бы один вернул
+// ошибку, relay повторит событие во всех, поэтому каждый sink должен переносить повторы.
+type Fanout []Sink
+
+func (f Fanout) Publish(ctx context.Context, e Event) error {
+	var errs []error
+	for _, s := range f {
+		if err := s.Publish(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/signature"
+)
+
+func TestWebhookSink(t *testing.T) {
+	secret := []byte("shared")
+	e := Event{ID: 42, Type: TypeBalanceCredited, UserID: 7, Payload: json.RawMessage(`{"order":"1","amount":5}`)}
+
+	tests := []struct {
+		name    string
+		code    int
+		wantErr bool
+	}{
+		{name: "accepted", code: http.StatusNoContent},
+		{name: "rejected", code: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if !signature.Valid(secret, body, r.Header.Get(signature.Header)) {
+					t.Errorf("invalid signature %q", r.Header.Get(signature.Header))
+				}
+				if r.Header.Get(EventIDHeader) != "42" || r.Header.Get(EventTypeHeader) != TypeBalanceCredited {
+					t.Errorf("unexpected event headers: %v", r.Header)
+				}
+				var got Event
+				if err := json.Unmarshal(body, &got); err != nil || got.ID != e.ID || got.UserID != e.UserID {
+					t.Errorf("unexpected body %s", body)
+				}
+				w.WriteHeader(tt.code)
+			}))
+			defer srv.Close()
+
+			err := NewWebhookSink(srv.URL, secret, srv.Client()).Publish(context.Background(), e)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriterSink(&buf)
+	for _, id := range []int64{1, 2} {
+		if err := s.Publish(context.Background(), Event{ID: id, Type: TypeOrderInvalid, Payload: json.RawMessage(`{}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"type":"order.invalid"`) {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -2,24 +2,159 @@ package psql
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/metrics"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-func Connect(dsn string) (*pgxpool.Pool, error) {
-	db, err := pgxpool.New(context.Background(), dsn)
+const (
+	maxConnectBackoff = 10 * time.Second
+	// saturationRatio — доля занятых соединений, начиная с которой пул считается насыщенным.
+	saturationRatio = 0.9
+)
+
+// PoolConfig переводит настройки из конфига в конфигурацию pgxpool.
+// Нулевые значения оставляют умолчания pgxpool и строки подключения.
+func PoolConfig(cfg config.Database) (*pgxpool.Config, error) {
+	pcfg, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		pcfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		pcfg.MinConns = cfg.MinConns
+	}
+	if cfg.MinIdleConns > 0 {
+		pcfg.MinIdleConns = cfg.MinIdleConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		pcfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		pcfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		pcfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.ConnectTimeout > 0 {
+		pcfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+	if cfg.StatementTimeout > 0 {
+		// Значение в миллисекундах, Postgres применит его к каждому запросу сессии.
+		pcfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	return pcfg, nil
+}
+
+// Connect открывает пул и ждёт доступности базы, повторяя попытки
+// с экспоненциальной задержкой до cfg.ConnectRetries раз.
+func Connect(ctx context.Context, cfg config.Database) (*pgxpool.Pool, error) {
+	pcfg, err := PoolConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	backoff := cfg.ConnectBackoff
+	for attempt := int32(0); ; attempt++ {
+		db, err := connectOnce(ctx, pcfg, cfg.ConnectTimeout)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return nil, fmt.Errorf("connect after %d attempts: %w", attempt+1, err)
+		}
+
+		logger.FromContext(ctx).Warnf("db connect attempt %d failed: %v; retry in %s", attempt+1, err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// Open создаёт пул, не дожидаясь доступности базы: соединения откроются
+// при первом запросе. Используется для реплики, которая может подняться позже.
+func Open(ctx context.Context, cfg config.Database) (*pgxpool.Pool, error) {
+	pcfg, err := PoolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.NewWithConfig(ctx, pcfg)
+}
+
+func connectOnce(ctx context.Context, pcfg *pgxpool.Config, timeout time.Duration) (*pgxpool.Pool, error) {
+	db, err := pgxpool.NewWithConfig(ctx, pcfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	if err := db.Ping(ctx); err != nil {
+	if err := db.Ping(pingCtx); err != nil {
 		db.Close()
 		return nil, err
 	}
-
 	return db, nil
 }
+
+// PoolCollector отдаёт статистику пула в реестр метрик.
+func PoolCollector(db *pgxpool.Pool, name string) metrics.Collector {
+	pool := metrics.Label{Name: "pool", Value: name}
+	return func(w *metrics.Writer) {
+		s := db.Stat()
+		w.Gauge("db_pool_max_conns", "Maximum size of the pool.", float64(s.MaxConns()), pool)
+		w.Gauge("db_pool_total_conns", "Connections currently open.", float64(s.TotalConns()), pool)
+		w.Gauge("db_pool_idle_conns", "Idle connections in the pool.", float64(s.IdleConns()), pool)
+		w.Gauge("db_pool_acquired_conns", "Connections currently in use.", float64(s.AcquiredConns()), pool)
+		w.Counter("db_pool_acquire_total", "Successful acquires from the pool.", float64(s.AcquireCount()), pool)
+		w.Counter("db_pool_empty_acquire_total", "Acquires that had to wait for a free connection.", float64(s.EmptyAcquireCount()), pool)
+		w.Counter("db_pool_acquire_wait_seconds_total", "Total time spent waiting for a connection.", s.AcquireDuration().Seconds(), pool)
+	}
+}
+
+// MonitorPool периодически проверяет пул и пишет предупреждение,
+// когда он почти исчерпан или запросы начали ждать соединений.
+// Завершается вместе с ctx.
+func MonitorPool(ctx context.Context, db *pgxpool.Pool, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastEmpty int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s := db.Stat()
+			empty := s.EmptyAcquireCount()
+			if saturated(s.AcquiredConns(), s.MaxConns()) || empty > lastEmpty {
+				logger.FromContext(ctx).
+					WithField("acquired", s.AcquiredConns()).
+					WithField("max", s.MaxConns()).
+					WithField("waited", empty-lastEmpty).
+					Warn("db pool is saturated")
+			}
+			lastEmpty = empty
+		}
+	}
+}
+
+func saturated(acquired, max int32) bool {
+	if max <= 0 {
+		return false
+	}
+	return float64(acquired)/float64(max) >= saturationRatio
+}
@@ -0,0 +1,72 @@
+package psql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+)
+
+func TestPoolConfig(t *testing.T) {
+	cfg := config.Database{
+		DSN:              "postgres://u:p@localhost:5432/db",
+		MaxConns:         20,
+		MinConns:         2,
+		MaxConnLifetime:  time.Hour,
+		StatementTimeout: 1500 * time.Millisecond,
+		ConnectTimeout:   3 * time.Second,
+	}
+
+	pcfg, err := PoolConfig(cfg)
+	if err != nil {
+		t.Fatalf("PoolConfig: %v", err)
+	}
+	if pcfg.MaxConns != 20 || pcfg.MinConns != 2 {
+		t.Errorf("conns = %d/%d, want 2/20", pcfg.MinConns, pcfg.MaxConns)
+	}
+	if pcfg.MaxConnLifetime != time.Hour {
+		t.Errorf("MaxConnLifetime = %s", pcfg.MaxConnLifetime)
+	}
+	if pcfg.ConnConfig.ConnectTimeout != 3*time.Second {
+		t.Errorf("ConnectTimeout = %s", pcfg.ConnConfig.ConnectTimeout)
+	}
+	if got := pcfg.ConnConfig.RuntimeParams["statement_timeout"]; got != "1500" {
+		t.Errorf("statement_timeout = %q, want 1500", got)
+	}
+}
+
+func TestPoolConfig_KeepsDSNDefaults(t *testing.T) {
+	pcfg, err := PoolConfig(config.Database{DSN: "postgres://u:p@localhost:5432/db?pool_max_conns=7"})
+	if err != nil {
+		t.Fatalf("PoolConfig: %v", err)
+	}
+	if pcfg.MaxConns != 7 {
+		t.Errorf("MaxConns = %d, want 7 from dsn", pcfg.MaxConns)
+	}
+	if _, ok := pcfg.ConnConfig.RuntimeParams["statement_timeout"]; ok {
+		t.Error("statement_timeout must not be set when disabled")
+	}
+}
+
+func TestPoolConfig_InvalidDSN(t *testing.T) {
+	if _, err := PoolConfig(config.Database{DSN: "postgres://%zz"}); err == nil {
+		t.Fatal("expected error for invalid dsn")
+	}
+}
+
+func TestSaturated(t *testing.T) {
+	tests := []struct {
+		acquired, max int32
+		want          bool
+	}{
+		{9, 10, true},
+		{10, 10, true},
+		{8, 10, false},
+		{0, 0, false},
+	}
+	for _, tt := range tests {
+		if got := saturated(tt.acquired, tt.max); got != tt.want {
+			t.Errorf("saturated(%d, %d) = %v, want %v", tt.acquired, tt.max, got, tt.want)
+		}
+	}
+}
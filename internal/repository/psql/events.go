@@ -0,0 +1,66 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql/query"
+)
+
+// eventsChannel — канал NOTIFY, в который пишет триггер на таблице outbox.
+const eventsChannel = "outbox_events"
+
+// ListenEvents занимает отдельное соединение и не возвращает его в пул:
+// после LISTEN оно продолжало бы получать уведомления.
+func (r *Repo) ListenEvents(ctx context.Context, ready func(), handle func(outbox.Event)) error {
+	const msg = "repo.ListenEvents"
+
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+eventsChannel); err != nil {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	ready()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", msg, err)
+		}
+		var e outbox.Event
+		if err := json.Unmarshal([]byte(n.Payload), &e); err != nil {
+			logger.FromContext(ctx).Errorf("%s: bad payload: %s", msg, err.Error())
+			continue
+		}
+		handle(e)
+	}
+}
+
+func (r *Repo) UserEventsAfter(ctx context.Context, userID int32, afterID int64, limit int32) ([]outbox.Event, error) {
+	rows, err := r.queries.ListUserEvents(ctx, query.ListUserEventsParams{
+		UserID: userID,
+		ID:     afterID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repo.UserEventsAfter: %w", err)
+	}
+	events := make([]outbox.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, outbox.Event{
+			ID:        row.ID,
+			Type:      row.EventType,
+			UserID:    userID,
+			Payload:   row.Payload,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	return events, nil
+}
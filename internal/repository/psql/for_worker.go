@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
 	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql/query"
 	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	"github.com/jackc/pgx/v5"
@@ -38,17 +39,65 @@ func (r *Repo) ListPending(ctx context.Context, limit int32, statuses []string,
 	return nil, fmt.Errorf("repo.ListPending error: %w", err)
 }
 
+// OrderByNumber читает заказ с primary: по нему принимается решение о смене статуса.
+func (r *Repo) OrderByNumber(ctx context.Context, number string) (gophermart.Order, error) {
+	row, err := r.queries.GetOrderByNumber(ctx, number)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return gophermart.Order{}, gophermart.ErrNoRow
+	}
+	if err != nil {
+		return gophermart.Order{}, fmt.Errorf("repo.OrderByNumber error: %w", err)
+	}
+	return gophermart.Order{
+		UserID:      row.UserID,
+		Number:      row.Number,
+		OrderStatus: row.Status,
+		UploadedAt:  row.UploadedAt.Time,
+	}, nil
+}
+
+// UpdateFromAccrual меняет промежуточный статус заказа и пишет событие
+// order.processing. Если статус уже такой, ничего не меняется.
 func (r *Repo) UpdateFromAccrual(ctx context.Context, number string, status string, nextSync time.Time) error {
-	if err := r.queries.UpdateFromAccrual(ctx, query.UpdateFromAccrualParams{
-		Number:     number,
-		Status:     status,
-		NextSyncAt: pgtype.Timestamptz{Valid: true, Time: nextSync},
-	}); err != nil {
+	err := r.InTx(ctx, func(rTx *Repo) error {
+		userID, err := rTx.queries.UpdateFromAccrual(ctx, query.UpdateFromAccrualParams{
+			Number:     number,
+			Status:     status,
+			NextSyncAt: pgtype.Timestamptz{Valid: true, Time: nextSync},
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return rTx.addEvent(ctx, outbox.TypeOrderProcessing, userID, outbox.OrderPayload{Order: number})
+	})
+	if err != nil {
 		return fmt.Errorf("repo.UpdateFromAccrual error: %w", err)
 	}
 	return nil
 }
 
+// MarkInvalid переводит заказ в терминальный INVALID и пишет событие order.invalid.
+// Заказ в терминальном статусе не меняется.
+func (r *Repo) MarkInvalid(ctx context.Context, number string) error {
+	err := r.InTx(ctx, func(rTx *Repo) error {
+		userID, err := rTx.queries.MarkOrderInvalid(ctx, number)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return rTx.addEvent(ctx, outbox.TypeOrderInvalid, userID, outbox.OrderPayload{Order: number})
+	})
+	if err != nil {
+		return fmt.Errorf("repo.MarkInvalid error: %w", err)
+	}
+	return nil
+}
+
 func (r *Repo) UpdateSyncTime(ctx context.Context, number string, nextSync time.Time) error {
 	if err := r.queries.UpdateSyncTime(ctx, query.UpdateSyncTimeParams{
 		Number:     number,
@@ -68,7 +117,7 @@ func (r *Repo) ApplyAccrual(ctx context.Context, number string, accrual int64, u
 		}
 		markRow, err := rTx.queries.MarkOrderProcessed(ctx, paramsMark)
 		if errors.Is(err, pgx.ErrNoRows) {
-			logger.Log.Warn("MarkOrderProcessed: no rows")
+			logger.FromContext(ctx).Warn("MarkOrderProcessed: no rows")
 			return nil
 		}
 		if err != nil {
@@ -81,10 +130,17 @@ func (r *Repo) ApplyAccrual(ctx context.Context, number string, accrual int64, u
 		if err := rTx.queries.AddToUserBalanceUpsert(ctx, addBalanceParams); err != nil {
 			return fmt.Errorf("repo.ApplyAccrual: %w", err)
 		}
-		return nil
+		points := float64(markRow.Accrual.Int32) / 100
+		if err := rTx.addEvent(ctx, outbox.TypeOrderProcessed, markRow.UserID,
+			outbox.OrderPayload{Order: number, Accrual: points}); err != nil {
+			return err
+		}
+		return rTx.addEvent(ctx, outbox.TypeBalanceCredited, markRow.UserID,
+			outbox.BalancePayload{Order: number, Amount: points})
 	})
 	if err != nil {
 		return fmt.Errorf("repo.ApplyAccrual: %w", err)
 	}
+	r.markWrite(userID)
 	return nil
 }
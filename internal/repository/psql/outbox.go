@@ -0,0 +1,92 @@
+package psql
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql/query"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// addEvent записывает доменное событие. Вызывается внутри транзакции,
+// которая меняет данные, поэтому событие появляется только вместе с изменением.
+func (r *Repo) addEvent(ctx context.Context, eventType string, userID int32, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("repo.addEvent %s: %w", eventType, err)
+	}
+	if err := r.queries.InsertOutboxEvent(ctx, query.InsertOutboxEventParams{
+		EventType: eventType,
+		UserID:    userID,
+		Payload:   body,
+	}); err != nil {
+		return fmt.Errorf("repo.addEvent %s: %w", eventType, err)
+	}
+	return nil
+}
+
+func (r *Repo) LeaseOutbox(ctx context.Context, limit int32, now time.Time, leaseUntil time.Time) ([]outbox.Event, error) {
+	rows, err := r.queries.LeaseOutboxEvents(ctx, query.LeaseOutboxEventsParams{
+		LeaseUntil: pgtype.Timestamptz{Valid: true, Time: leaseUntil},
+		Now:        pgtype.Timestamptz{Valid: true, Time: now},
+		MaxItems:   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repo.LeaseOutbox: %w", err)
+	}
+	events := make([]outbox.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, outbox.Event{
+			ID:        row.ID,
+			Type:      row.EventType,
+			UserID:    row.UserID,
+			Payload:   row.Payload,
+			CreatedAt: row.CreatedAt.Time,
+			Attempts:  row.Attempts,
+		})
+	}
+	// RETURNING не сохраняет порядок подзапроса.
+	slices.SortFunc(events, func(a, b outbox.Event) int { return cmp.Compare(a.ID, b.ID) })
+	return events, nil
+}
+
+func (r *Repo) SaveOutboxDeliveries(ctx context.Context, deliveries []outbox.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := r.InTx(ctx, func(rTx *Repo) error {
+		for _, d := range deliveries {
+			var err error
+			if d.Err == nil {
+				err = rTx.queries.MarkOutboxPublished(ctx, d.ID)
+			} else {
+				err = rTx.queries.MarkOutboxFailed(ctx, query.MarkOutboxFailedParams{
+					ID:            d.ID,
+					NextAttemptAt: pgtype.Timestamptz{Valid: true, Time: d.RetryAt},
+					LastError:     pgtype.Text{Valid: true, String: d.Err.Error()},
+				})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("repo.SaveOutboxDeliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *Repo) PurgeOutbox(ctx context.Context, before time.Time) (int64, error) {
+	n, err := r.queries.DeletePublishedOutbox(ctx, pgtype.Timestamptz{Valid: true, Time: before})
+	if err != nil {
+		return 0, fmt.Errorf("repo.PurgeOutbox: %w", err)
+	}
+	return n, nil
+}
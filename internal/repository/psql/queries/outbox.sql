@@ -0,0 +1,51 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (event_type, user_id, payload)
+VALUES ($1, $2, $3);
+
+
+-- name: LeaseOutboxEvents :many
+UPDATE outbox
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT o.id
+    FROM outbox o
+    WHERE o.published_at IS NULL AND o.next_attempt_at <= sqlc.arg(now)
+    ORDER BY o.id
+    LIMIT sqlc.arg(max_items)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_type, user_id, payload, created_at, attempts;
+
+
+-- name: ListUserEvents :many
+SELECT id, event_type, payload, created_at
+FROM outbox
+WHERE
+    user_id = $1
+    AND id > $2
+ORDER BY id
+LIMIT $3;
+
+
+-- name: MarkOutboxPublished :exec
+UPDATE outbox
+SET
+    published_at = now(),
+    last_error = NULL
+WHERE id = $1;
+
+
+-- name: MarkOutboxFailed :exec
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    next_attempt_at = $2,
+    last_error = $3
+WHERE id = $1;
+
+
+-- name: DeletePublishedOutbox :execrows
+DELETE FROM outbox
+WHERE
+    published_at IS NOT NULL
+    AND published_at < $1;
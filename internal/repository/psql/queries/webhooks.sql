@@ -0,0 +1,79 @@
+-- name: CreateWebhook :one
+INSERT INTO user_webhooks (user_id, url, secret)
+VALUES ($1, $2, $3)
+RETURNING id, created_at;
+
+
+-- name: ListWebhooks :many
+SELECT id, url, created_at
+FROM user_webhooks
+WHERE user_id = $1
+ORDER BY id;
+
+
+-- name: GetWebhookID :one
+SELECT id
+FROM user_webhooks
+WHERE id = $1 AND user_id = $2;
+
+
+-- name: DeleteWebhook :execrows
+DELETE FROM user_webhooks
+WHERE id = $1 AND user_id = $2;
+
+
+-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (webhook_id, user_id, event_id, event_type, payload)
+SELECT id, user_id, sqlc.arg(event_id)::bigint, sqlc.arg(event_type)::varchar, sqlc.arg(payload)::jsonb
+FROM user_webhooks
+WHERE user_id = sqlc.arg(user_id)
+ON CONFLICT (webhook_id, event_id) DO NOTHING;
+
+
+-- name: LeaseWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = sqlc.arg(lease_until)
+FROM user_webhooks w
+WHERE
+    w.id = d.webhook_id
+    AND d.id IN (
+        SELECT p.id
+        FROM webhook_deliveries p
+        WHERE p."status" = 'PENDING' AND p.next_attempt_at <= sqlc.arg(now)
+        ORDER BY p.id
+        LIMIT sqlc.arg(max_items)
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts, d.created_at, w.url, w.secret;
+
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET
+    "status" = 'DELIVERED',
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = now()
+WHERE id = $1;
+
+
+-- name: MarkWebhookAttemptFailed :exec
+UPDATE webhook_deliveries
+SET
+    "status" = $2,
+    attempts = attempts + 1,
+    next_attempt_at = $3,
+    last_status_code = $4,
+    last_error = $5
+WHERE id = $1;
+
+
+-- name: ListWebhookDeliveries :many
+SELECT d.id, d.event_id, d.event_type, d."status", d.attempts, d.last_status_code, d.last_error,
+       d.created_at, d.delivered_at, d.next_attempt_at
+FROM webhook_deliveries d
+JOIN user_webhooks w ON w.id = d.webhook_id
+WHERE d.webhook_id = $1 AND w.user_id = $2
+ORDER BY d.id DESC
+LIMIT $3;
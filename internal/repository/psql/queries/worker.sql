@@ -15,13 +15,27 @@ LIMIT $1;
 
 
 
--- name: UpdateFromAccrual :exec
+-- name: UpdateFromAccrual :one
 UPDATE order_numbers
 SET
     "status" = $2,
     next_sync_at = $3
 WHERE
-    "number" = $1;
+    "number" = $1
+    AND "status" IN ('NEW', 'PROCESSING')
+    AND "status" <> $2
+RETURNING user_id;
+
+
+-- name: MarkOrderInvalid :one
+UPDATE order_numbers
+SET
+    "status" = 'INVALID',
+    next_sync_at = NULL
+WHERE
+    "number" = $1
+    AND "status" IN ('NEW', 'PROCESSING')
+RETURNING user_id;
 
 
 -- name: UpdateSyncTime :exec
@@ -40,7 +54,7 @@ SET
 WHERE
     "number" = $1
     AND user_id = $3
-    AND "status" <> 'PROCESSED'
+    AND "status" IN ('NEW', 'PROCESSING')
 RETURNING user_id, accrual;
 
 
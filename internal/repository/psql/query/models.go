@@ -18,6 +18,18 @@ type OrderNumber struct {
 	NextSyncAt pgtype.Timestamptz
 }
 
+type Outbox struct {
+	ID            int64
+	EventType     string
+	UserID        int32
+	Payload       []byte
+	CreatedAt     pgtype.Timestamptz
+	PublishedAt   pgtype.Timestamptz
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	LastError     pgtype.Text
+}
+
 type User struct {
 	ID           int32
 	Login        string
@@ -31,6 +43,30 @@ type UserBalance struct {
 	Withdrawn int32
 }
 
+type UserWebhook struct {
+	ID        int32
+	UserID    int32
+	Url       string
+	Secret    string
+	CreatedAt pgtype.Timestamptz
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int32
+	UserID         int32
+	EventID        int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	LastStatusCode pgtype.Int4
+	LastError      pgtype.Text
+	CreatedAt      pgtype.Timestamptz
+	DeliveredAt    pgtype.Timestamptz
+}
+
 type Withdraw struct {
 	ID          int32
 	UserID      int32
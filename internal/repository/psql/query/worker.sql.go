@@ -82,6 +82,24 @@ func (q *Queries) ListPending(ctx context.Context, arg ListPendingParams) ([]Lis
 	return items, nil
 }
 
+const markOrderInvalid = `-- name: MarkOrderInvalid :one
+UPDATE order_numbers
+SET
+    "status" = 'INVALID',
+    next_sync_at = NULL
+WHERE
+    "number" = $1
+    AND "status" IN ('NEW', 'PROCESSING')
+RETURNING user_id
+`
+
+func (q *Queries) MarkOrderInvalid(ctx context.Context, number string) (int32, error) {
+	row := q.db.QueryRow(ctx, markOrderInvalid, number)
+	var user_id int32
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const markOrderProcessed = `-- name: MarkOrderProcessed :one
 UPDATE order_numbers
 SET
@@ -91,7 +109,7 @@ SET
 WHERE
     "number" = $1
     AND user_id = $3
-    AND "status" <> 'PROCESSED'
+    AND "status" IN ('NEW', 'PROCESSING')
 RETURNING user_id, accrual
 `
 
@@ -113,13 +131,16 @@ func (q *Queries) MarkOrderProcessed(ctx context.Context, arg MarkOrderProcessed
 	return i, err
 }
 
-const updateFromAccrual = `-- name: UpdateFromAccrual :exec
+const updateFromAccrual = `-- name: UpdateFromAccrual :one
 UPDATE order_numbers
 SET
     "status" = $2,
     next_sync_at = $3
 WHERE
     "number" = $1
+    AND "status" IN ('NEW', 'PROCESSING')
+    AND "status" <> $2
+RETURNING user_id
 `
 
 type UpdateFromAccrualParams struct {
@@ -128,9 +149,11 @@ type UpdateFromAccrualParams struct {
 	NextSyncAt pgtype.Timestamptz
 }
 
-func (q *Queries) UpdateFromAccrual(ctx context.Context, arg UpdateFromAccrualParams) error {
-	_, err := q.db.Exec(ctx, updateFromAccrual, arg.Number, arg.Status, arg.NextSyncAt)
-	return err
+func (q *Queries) UpdateFromAccrual(ctx context.Context, arg UpdateFromAccrualParams) (int32, error) {
+	row := q.db.QueryRow(ctx, updateFromAccrual, arg.Number, arg.Status, arg.NextSyncAt)
+	var user_id int32
+	err := row.Scan(&user_id)
+	return user_id, err
 }
 
 const updateSyncTime = `-- name: UpdateSyncTime :exec
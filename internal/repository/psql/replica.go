@@ -0,0 +1,152 @@
+package psql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql/query"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaLagQuery возвращает отставание реплики в секундах. Если всё полученное
+// уже применено, отставание нулевое, даже когда на primary давно не было записей.
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+
+// replica — пул реплики и её текущее состояние. Пока первая проверка
+// не прошла, реплика считается недоступной.
+type replica struct {
+	db      *pgxpool.Pool
+	queries *query.Queries
+	cfg     config.Replica
+	healthy atomic.Bool
+	pins    *writePins
+}
+
+// NewRepoWithReplica создаёт репозиторий, читающий списки и баланс с реплики.
+// Проверку реплики запускает MonitorReplica.
+func NewRepoWithReplica(primary *pgxpool.Pool, replicaDB *pgxpool.Pool, cfg config.Replica) *Repo {
+	r := NewRepo(primary)
+	r.replica = &replica{
+		db:      replicaDB,
+		queries: query.New(replicaDB),
+		cfg:     cfg,
+		pins:    newWritePins(cfg.PinWindow),
+	}
+	return r
+}
+
+// MonitorReplica периодически замеряет отставание реплики и включает или
+// выключает чтение с неё. Завершается вместе с ctx.
+func (r *Repo) MonitorReplica(ctx context.Context) {
+	if r.replica == nil {
+		return
+	}
+	r.replica.check(ctx)
+
+	ticker := time.NewTicker(r.replica.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.replica.check(ctx)
+			r.replica.pins.cleanup()
+		}
+	}
+}
+
+func (rp *replica) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, rp.cfg.CheckInterval)
+	defer cancel()
+
+	var lagSeconds float64
+	err := rp.db.QueryRow(ctx, replicaLagQuery).Scan(&lagSeconds)
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	ok := err == nil && lag <= rp.cfg.MaxLag
+
+	if was := rp.healthy.Swap(ok); was != ok {
+		log := logger.FromContext(ctx).WithField("lag", lag)
+		if ok {
+			log.Info("replica reads enabled")
+		} else if err != nil {
+			log.Warnf("replica reads disabled: %v", err)
+		} else {
+			log.Warn("replica reads disabled: lag exceeds threshold")
+		}
+	}
+}
+
+// readQueries выбирает, откуда читать данные пользователя: реплика используется,
+// только если она доступна и пользователь недавно ничего не записывал.
+func (r *Repo) readQueries(userID int32) *query.Queries {
+	rp := r.replica
+	if rp == nil || !rp.healthy.Load() || rp.pins.pinned(userID) {
+		return r.queries
+	}
+	return rp.queries
+}
+
+// onReplica сообщает, что чтение было направлено на реплику.
+func (r *Repo) onReplica(q *query.Queries) bool {
+	return r.replica != nil && q == r.replica.queries
+}
+
+// markWrite закрепляет чтения пользователя за primary на PinWindow.
+func (r *Repo) markWrite(userID int32) {
+	if r.replica != nil {
+		r.replica.pins.pin(userID)
+	}
+}
+
+// writePins хранит время последней записи пользователей (read-your-writes).
+// Состояние локально для процесса, другие экземпляры о записи не знают.
+type writePins struct {
+	mu     sync.Mutex
+	window time.Duration
+	until  map[int32]time.Time
+	now    func() time.Time
+}
+
+func newWritePins(window time.Duration) *writePins {
+	return &writePins{
+		window: window,
+		until:  make(map[int32]time.Time),
+		now:    time.Now,
+	}
+}
+
+func (p *writePins) pin(userID int32) {
+	if p.window <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.until[userID] = p.now().Add(p.window)
+}
+
+func (p *writePins) pinned(userID int32) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	until, ok := p.until[userID]
+	return ok && p.now().Before(until)
+}
+
+func (p *writePins) cleanup() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for id, until := range p.until {
+		if !now.Before(until) {
+			delete(p.until, id)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package psql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+)
+
+func TestWritePins(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := newWritePins(10 * time.Second)
+	p.now = func() time.Time { return now }
+
+	p.pin(1)
+	if !p.pinned(1) {
+		t.Fatal("user 1 must be pinned right after write")
+	}
+	if p.pinned(2) {
+		t.Fatal("user 2 never wrote and must not be pinned")
+	}
+
+	now = now.Add(10 * time.Second)
+	if p.pinned(1) {
+		t.Fatal("pin must expire after window")
+	}
+	p.cleanup()
+	if len(p.until) != 0 {
+		t.Fatalf("cleanup left %d entries", len(p.until))
+	}
+}
+
+func TestWritePins_ZeroWindow(t *testing.T) {
+	p := newWritePins(0)
+	p.pin(1)
+	if p.pinned(1) {
+		t.Fatal("zero window disables pinning")
+	}
+}
+
+func TestRepo_ReadQueries(t *testing.T) {
+	plain := NewRepo(nil)
+	if q := plain.readQueries(1); plain.onReplica(q) {
+		t.Fatal("repo without replica must read from primary")
+	}
+
+	r := NewRepoWithReplica(nil, nil, config.Replica{PinWindow: time.Minute})
+	if q := r.readQueries(1); r.onReplica(q) {
+		t.Fatal("unchecked replica must not be used")
+	}
+
+	r.replica.healthy.Store(true)
+	if q := r.readQueries(1); !r.onReplica(q) {
+		t.Fatal("healthy replica must serve reads")
+	}
+
+	r.markWrite(1)
+	if q := r.readQueries(1); r.onReplica(q) {
+		t.Fatal("reads after own write must go to primary")
+	}
+	if q := r.readQueries(2); !r.onReplica(q) {
+		t.Fatal("other users keep reading from replica")
+	}
+
+	r.replica.healthy.Store(false)
+	if q := r.readQueries(2); r.onReplica(q) {
+		t.Fatal("lagging replica must not be used")
+	}
+}
+
+// Пустой список списаний — не ошибка, иначе GET /api/user/withdrawals отвечал 500 вместо 204.
+func TestToWithdraws_Empty(t *testing.T) {
+	got := toWithdraws(nil)
+	if got == nil || len(got) != 0 {
+		t.Fatalf("toWithdraws(nil) = %#v, want empty slice", got)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
 	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql/query"
 	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
 	"github.com/jackc/pgx/v5"
@@ -19,6 +21,7 @@ const pgUniqueViolation = "23505"
 type Repo struct {
 	db      *pgxpool.Pool
 	queries *query.Queries
+	replica *replica
 }
 
 func NewRepo(db *pgxpool.Pool) *Repo {
@@ -79,6 +82,7 @@ func (r *Repo) CreateOrder(ctx context.Context, userID int32, number string) (bo
 	}
 	err := r.queries.AddOrder(ctx, argCreateOrder)
 	if err == nil {
+		r.markWrite(userID)
 		return true, 0, nil
 	}
 	var pgerr *pgconn.PgError
@@ -93,7 +97,12 @@ func (r *Repo) CreateOrder(ctx context.Context, userID int32, number string) (bo
 }
 
 func (r *Repo) GetOrders(ctx context.Context, userID int32) ([]gophermart.Order, error) {
-	rows, err := r.queries.GetOrdersByUserID(ctx, userID)
+	q := r.readQueries(userID)
+	rows, err := q.GetOrdersByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) && r.onReplica(q) {
+		logger.FromContext(ctx).Warnf("replica read failed, falling back to primary: %v", err)
+		rows, err = r.queries.GetOrdersByUserID(ctx, userID)
+	}
 	if errors.Is(err, pgx.ErrNoRows) {
 		return []gophermart.Order{}, nil
 	}
@@ -114,32 +123,34 @@ func (r *Repo) GetOrders(ctx context.Context, userID int32) ([]gophermart.Order,
 }
 
 func (r *Repo) ListWithdraws(ctx context.Context, userID int32) ([]gophermart.Withdraw, error) {
-	var result []gophermart.Withdraw
-	err := r.InTx(ctx, func(rTx *Repo) error {
-		withdraws, err := rTx.queries.ListWithdraws(ctx, userID)
-		if err != nil {
-			return err
-		}
-		if len(withdraws) == 0 {
-			result = []gophermart.Withdraw{}
-			return gophermart.ErrNoRow
-		}
-		result = make([]gophermart.Withdraw, 0, len(withdraws))
-		for _, w := range withdraws {
-			result = append(result, gophermart.Withdraw{
-				ID:          w.ID,
-				UserID:      w.UserID,
-				OrderNumber: w.OrderNumber,
-				Summa:       w.Summa,
-				ProcessedAt: w.ProcessedAt.Time,
-			})
+	if q := r.readQueries(userID); r.onReplica(q) {
+		withdraws, err := q.ListWithdraws(ctx, userID)
+		if err == nil {
+			return toWithdraws(withdraws), nil
 		}
-		return nil
-	})
+		logger.FromContext(ctx).Warnf("replica read failed, falling back to primary: %v", err)
+	}
+
+	withdraws, err := r.queries.ListWithdraws(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("repo.ListWithdraws: %w", err)
 	}
-	return result, nil
+	return toWithdraws(withdraws), nil
+}
+
+// toWithdraws возвращает пустой список, если списаний нет: это не ошибка.
+func toWithdraws(withdraws []query.Withdraw) []gophermart.Withdraw {
+	result := make([]gophermart.Withdraw, 0, len(withdraws))
+	for _, w := range withdraws {
+		result = append(result, gophermart.Withdraw{
+			ID:          w.ID,
+			UserID:      w.UserID,
+			OrderNumber: w.OrderNumber,
+			Summa:       w.Summa,
+			ProcessedAt: w.ProcessedAt.Time,
+		})
+	}
+	return result
 }
 
 func (r *Repo) Withdraw(ctx context.Context, userID int32, summa int32, order string) error {
@@ -170,15 +181,33 @@ func (r *Repo) Withdraw(ctx context.Context, userID int32, summa int32, order st
 			}
 			return err
 		}
-		return nil
+		return rTx.addEvent(ctx, outbox.TypeWithdrawalCreated, userID,
+			outbox.WithdrawalPayload{Order: order, Sum: float64(summa) / 100})
 	})
 	if err != nil {
 		return fmt.Errorf("repo.Withdraw: %w", err)
 	}
+	r.markWrite(userID)
 	return nil
 }
 
 func (r *Repo) Balance(ctx context.Context, userID int32) (gophermart.Balance, error) {
+	// Строку баланса реплика создать не может: если её ещё нет, идём на primary.
+	if q := r.readQueries(userID); r.onReplica(q) {
+		balanceRow, err := q.BalnceByUserID(ctx, userID)
+		if err == nil {
+			return gophermart.Balance{
+				ID:       balanceRow.ID,
+				UserID:   balanceRow.UserID,
+				Balance:  balanceRow.Balance,
+				Withdraw: balanceRow.Withdrawn,
+			}, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.FromContext(ctx).Warnf("replica read failed, falling back to primary: %v", err)
+		}
+	}
+
 	var balance gophermart.Balance
 	err := r.InTx(ctx, func(rTx *Repo) error {
 		err := rTx.queries.EnsureBalanceRow(ctx, userID)
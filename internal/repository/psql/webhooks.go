@@ -0,0 +1,153 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+	"github.com/IvanOplesnin/gofermart.git/internal/repository/psql/query"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+	"github.com/IvanOplesnin/gofermart.git/internal/webhooks"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (r *Repo) CreateWebhook(ctx context.Context, userID int32, url string, secret string) (gophermart.Webhook, error) {
+	row, err := r.queries.CreateWebhook(ctx, query.CreateWebhookParams{UserID: userID, Url: url, Secret: secret})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return gophermart.Webhook{}, gophermart.ErrWebhookAlreadyExists
+	}
+	if err != nil {
+		return gophermart.Webhook{}, fmt.Errorf("repo.CreateWebhook: %w", err)
+	}
+	return gophermart.Webhook{ID: row.ID, UserID: userID, URL: url, CreatedAt: row.CreatedAt.Time}, nil
+}
+
+func (r *Repo) ListWebhooks(ctx context.Context, userID int32) ([]gophermart.Webhook, error) {
+	rows, err := r.queries.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListWebhooks: %w", err)
+	}
+	result := make([]gophermart.Webhook, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, gophermart.Webhook{ID: row.ID, UserID: userID, URL: row.Url, CreatedAt: row.CreatedAt.Time})
+	}
+	return result, nil
+}
+
+// DeleteWebhook удаляет вебхук вместе с журналом доставок. Чужой или
+// несуществующий вебхук даёт gophermart.ErrNoRow.
+func (r *Repo) DeleteWebhook(ctx context.Context, userID int32, id int32) error {
+	n, err := r.queries.DeleteWebhook(ctx, query.DeleteWebhookParams{ID: id, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("repo.DeleteWebhook: %w", err)
+	}
+	if n == 0 {
+		return gophermart.ErrNoRow
+	}
+	return nil
+}
+
+func (r *Repo) ListWebhookDeliveries(ctx context.Context, userID int32, webhookID int32, limit int32) ([]gophermart.WebhookDelivery, error) {
+	_, err := r.queries.GetWebhookID(ctx, query.GetWebhookIDParams{ID: webhookID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, gophermart.ErrNoRow
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListWebhookDeliveries: %w", err)
+	}
+	rows, err := r.queries.ListWebhookDeliveries(ctx, query.ListWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		UserID:    userID,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListWebhookDeliveries: %w", err)
+	}
+	result := make([]gophermart.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		d := gophermart.WebhookDelivery{
+			ID:        row.ID,
+			EventID:   row.EventID,
+			EventType: row.EventType,
+			Status:    row.Status,
+			Attempts:  row.Attempts,
+			LastError: row.LastError.String,
+			CreatedAt: row.CreatedAt.Time,
+		}
+		if row.LastStatusCode.Valid {
+			d.LastStatusCode = row.LastStatusCode.Int32
+		}
+		if row.DeliveredAt.Valid {
+			d.DeliveredAt = row.DeliveredAt.Time
+		}
+		if row.Status == webhooks.StatusPending {
+			d.NextAttemptAt = row.NextAttemptAt.Time
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (r *Repo) EnqueueWebhookDeliveries(ctx context.Context, e outbox.Event) (int64, error) {
+	n, err := r.queries.EnqueueWebhookDeliveries(ctx, query.EnqueueWebhookDeliveriesParams{
+		EventID:   e.ID,
+		EventType: e.Type,
+		Payload:   e.Payload,
+		UserID:    e.UserID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("repo.EnqueueWebhookDeliveries: %w", err)
+	}
+	return n, nil
+}
+
+func (r *Repo) LeaseWebhookDeliveries(ctx context.Context, limit int32, now time.Time, leaseUntil time.Time) ([]webhooks.Delivery, error) {
+	rows, err := r.queries.LeaseWebhookDeliveries(ctx, query.LeaseWebhookDeliveriesParams{
+		LeaseUntil: pgtype.Timestamptz{Valid: true, Time: leaseUntil},
+		Now:        pgtype.Timestamptz{Valid: true, Time: now},
+		MaxItems:   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repo.LeaseWebhookDeliveries: %w", err)
+	}
+	result := make([]webhooks.Delivery, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, webhooks.Delivery{
+			ID:        row.ID,
+			WebhookID: row.WebhookID,
+			URL:       row.Url,
+			Secret:    row.Secret,
+			EventID:   row.EventID,
+			EventType: row.EventType,
+			Payload:   row.Payload,
+			CreatedAt: row.CreatedAt.Time,
+			Attempts:  row.Attempts,
+		})
+	}
+	return result, nil
+}
+
+func (r *Repo) SaveWebhookResult(ctx context.Context, res webhooks.Result) error {
+	code := pgtype.Int4{Valid: res.StatusCode != 0, Int32: int32(res.StatusCode)}
+	var err error
+	if res.Status == webhooks.StatusDelivered {
+		err = r.queries.MarkWebhookDelivered(ctx, query.MarkWebhookDeliveredParams{ID: res.ID, LastStatusCode: code})
+	} else {
+		err = r.queries.MarkWebhookAttemptFailed(ctx, query.MarkWebhookAttemptFailedParams{
+			ID:             res.ID,
+			Status:         res.Status,
+			NextAttemptAt:  pgtype.Timestamptz{Valid: true, Time: res.RetryAt},
+			LastStatusCode: code,
+			LastError:      pgtype.Text{Valid: res.Err != nil, String: webhooks.ErrorText(res.Err)},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("repo.SaveWebhookResult: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+)
+
+// CertReloader держит текущую пару сертификат/ключ и перечитывает её с диска,
+// когда меняется время модификации одного из файлов.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	cert    atomic.Pointer[tls.Certificate]
+	modTime time.Time
+}
+
+func NewCertReloader(certFile string, keyFile string, interval time.Duration) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate подходит для tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Run проверяет файлы раз в interval, пока не отменён ctx.
+func (r *CertReloader) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil {
+				logger.Log.Errorf("server.CertReloader: %s", err.Error())
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				// Оставляем предыдущий сертификат: файлы могли быть записаны не до конца.
+				logger.Log.Errorf("server.CertReloader: %s", err.Error())
+				continue
+			}
+			logger.Log.Infof("server.CertReloader: certificate %s reloaded", r.certFile)
+		}
+	}
+}
+
+func (r *CertReloader) reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+	r.cert.Store(&cert)
+	r.modTime = modTime
+	return nil
+}
+
+func (r *CertReloader) changed() (bool, error) {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return false, err
+	}
+	return !modTime.Equal(r.modTime), nil
+}
+
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		st, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("stat %s: %w", f, err)
+		}
+		if st.ModTime().After(latest) {
+			latest = st.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// GRPC запускает gRPC-сервер с теми же правилами TLS и остановки, что и Server.
+type GRPC struct {
+	name  string
+	addr  string
+	srv   *grpc.Server
+	certs *CertReloader
+
+	cancelReload context.CancelFunc
+}
+
+// NewGRPC передаёт в build опции транспорта; build регистрирует сервисы.
+func NewGRPC(name string, addr string, tlsCfg config.TLS, build func(opts ...grpc.ServerOption) *grpc.Server) (*GRPC, error) {
+	s := &GRPC{name: name, addr: addr}
+	var opts []grpc.ServerOption
+	if tlsCfg.Enabled() {
+		certs, err := NewCertReloader(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ReloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("server.NewGRPC(%s): %w", name, err)
+		}
+		s.certs = certs
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		})))
+	}
+	s.srv = build(opts...)
+	return s, nil
+}
+
+func (s *GRPC) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("server.%s listen: %w", s.name, err)
+	}
+	return s.Serve(ln)
+}
+
+func (s *GRPC) Serve(ln net.Listener) error {
+	if s.certs != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancelReload = cancel
+		go s.certs.Run(ctx)
+	}
+	logger.Log.Infof("%s: listen on grpc://%s", s.name, ln.Addr())
+	if err := s.srv.Serve(ln); err != nil {
+		return fmt.Errorf("server.%s: %w", s.name, err)
+	}
+	return nil
+}
+
+// Shutdown дожидается текущих вызовов, а по истечении ctx обрывает их.
+func (s *GRPC) Shutdown(ctx context.Context) error {
+	if s.cancelReload != nil {
+		s.cancelReload()
+	}
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,126 @@
+// Package server запускает HTTP(S)-серверы приложения.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+type Server struct {
+	name  string
+	http  *http.Server
+	certs *CertReloader
+
+	cancelReload context.CancelFunc
+}
+
+// New создаёт сервер. При включённом TLS сервер говорит HTTP/2 и HTTP/1.1,
+// а при заданном clientCAFile требует клиентский сертификат (mTLS).
+func New(name string, addr string, h http.Handler, tlsCfg config.TLS, clientCAFile string) (*Server, error) {
+	s := &Server{
+		name: name,
+		http: &http.Server{
+			Addr:              addr,
+			Handler:           h,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+	}
+	if !tlsCfg.Enabled() {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("server.New(%s): client CA requires cert and key files", name)
+		}
+		return s, nil
+	}
+
+	certs, err := NewCertReloader(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ReloadInterval)
+	if err != nil {
+		return nil, fmt.Errorf("server.New(%s): %w", name, err)
+	}
+	s.certs = certs
+
+	tc := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("server.New(%s): %w", name, err)
+		}
+		tc.ClientCAs = pool
+		tc.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	s.http.TLSConfig = tc
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	s.http.Protocols = &protocols
+
+	return s, nil
+}
+
+// ListenAndServe блокируется до остановки сервера. После Shutdown возвращает nil.
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("server.%s listen: %w", s.name, err)
+	}
+	return s.Serve(ln)
+}
+
+func (s *Server) Serve(ln net.Listener) error {
+	var err error
+	if s.certs != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancelReload = cancel
+		go s.certs.Run(ctx)
+
+		logger.Log.Infof("%s: listen on https://%s", s.name, ln.Addr())
+		err = s.http.ServeTLS(ln, "", "")
+	} else {
+		logger.Log.Infof("%s: listen on http://%s", s.name, ln.Addr())
+		err = s.http.Serve(ln)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return fmt.Errorf("server.%s: %w", s.name, err)
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.cancelReload != nil {
+		s.cancelReload()
+	}
+	return s.http.Shutdown(ctx)
+}
+
+// RegisterOnShutdown регистрирует f, вызываемую в начале Shutdown. Shutdown
+// не прерывает активные запросы, поэтому долгие потоки (SSE) нужно закрывать так.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.http.RegisterOnShutdown(f)
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("no certificates in %s", file)
+	}
+	return pool, nil
+}
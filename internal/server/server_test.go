@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+)
+
+func writeSelfSigned(t *testing.T, dir string, cn string) (certFile string, keyFile string, certPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certFile, keyFile, certPEM
+}
+
+func TestCertReloader_ReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeSelfSigned(t, dir, "first")
+
+	r, err := NewCertReloader(certFile, keyFile, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("new reloader: %v", err)
+	}
+	first, _ := r.GetCertificate(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	writeSelfSigned(t, dir, "second")
+	future := time.Now().Add(time.Minute)
+	_ = os.Chtimes(certFile, future, future)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		cur, _ := r.GetCertificate(nil)
+		if cur != first {
+			leaf, err := x509.ParseCertificate(cur.Certificate[0])
+			if err != nil {
+				t.Fatalf("parse leaf: %v", err)
+			}
+			if leaf.Subject.CommonName != "second" {
+				t.Fatalf("expected reloaded cert CN=second, got %q", leaf.Subject.CommonName)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("certificate was not reloaded")
+}
+
+func TestServer_ServesHTTP2OverTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, certPEM := writeSelfSigned(t, dir, "127.0.0.1")
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	srv, err := New("test", "127.0.0.1:0", h, config.TLS{CertFile: certFile, KeyFile: keyFile}, "")
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Shutdown(context.Background()) }()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	var protocols http.Protocols
+	protocols.SetHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+		Protocols:       &protocols,
+	}}
+
+	resp, err := client.Get("https://" + ln.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("expected HTTP/2, got %s", resp.Proto)
+	}
+}
+
+func TestServer_ClientCARequiresTLS(t *testing.T) {
+	_, err := New("admin", "127.0.0.1:0", http.NotFoundHandler(), config.TLS{}, "ca.pem")
+	if err == nil {
+		t.Fatalf("expected error for client CA without TLS")
+	}
+}
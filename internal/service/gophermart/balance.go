@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 
-	"github.com/IvanOplesnin/gofermart.git/internal/handler"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -20,25 +20,32 @@ type Balance struct {
 	Withdraw int32
 }
 
-func (s *Service) Balance(ctx context.Context) (handler.BalanceResponse, error) {
-	const msg = "service.Orders"
-	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
+// UserBalance — баланс пользователя в баллах.
+type UserBalance struct {
+	Current   float64
+	Withdrawn float64
+}
 
-	userID, err := handler.UserIDFromCtx(ctx)
-	if err != nil {
-		return handler.BalanceResponse{}, wrapError(err)
-	}
+func (s *Service) Balance(ctx context.Context, userID int32) (UserBalance, error) {
+	const msg = "service.Balance"
+	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
 
 	balance, err := s.balanceDB.Balance(ctx, userID)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return handler.BalanceResponse{}, nil
+		return UserBalance{}, nil
 	}
 	if err != nil {
-		return handler.BalanceResponse{}, wrapError(err)
+		return UserBalance{}, wrapError(err)
 	}
 
-	return handler.BalanceResponse{
+	return UserBalance{
 		Current:   float64(balance.Balance) / 100,
 		Withdrawn: float64(balance.Withdraw) / 100,
 	}, nil
 }
+
+// toCents переводит баллы в копейки. Без округления int64(0.29*100) дал бы 28:
+// в float64 это 28.999999999999996.
+func toCents(points float64) int64 {
+	return int64(math.Round(points * 100))
+}
@@ -0,0 +1,94 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"testing/quick"
+)
+
+func TestToCents(t *testing.T) {
+	tests := []struct {
+		points float64
+		want   int64
+	}{
+		{0.29, 29},
+		{0.57, 57},
+		{1.15, 115},
+		{4.35, 435},
+		{729.98, 72998},
+		{0.004, 0},
+		{0.005, 1},
+	}
+	for _, tt := range tests {
+		if got := toCents(tt.points); got != tt.want {
+			t.Errorf("toCents(%v) = %d, want %d", tt.points, got, tt.want)
+		}
+	}
+}
+
+// Баллы, показанные пользователю, переводятся обратно в те же копейки.
+func TestToCents_RoundTrip(t *testing.T) {
+	property := func(cents int64) bool {
+		cents %= 1 << 50 // дальше float64 перестаёт различать копейки
+		return toCents(float64(cents)/100) == cents
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 100000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// withdrawRecorder запоминает суммы, дошедшие до базы.
+type withdrawRecorder struct {
+	cents []int32
+}
+
+func (r *withdrawRecorder) Withdraw(_ context.Context, _ int32, summa int32, _ string) error {
+	r.cents = append(r.cents, summa)
+	return nil
+}
+
+func (r *withdrawRecorder) ListWithdraws(context.Context, int32) ([]Withdraw, error) {
+	return nil, nil
+}
+
+func FuzzService_Withdraw(f *testing.F) {
+	for _, seed := range []float64{0.29, 0.001, 0.005, 21474836.47, 21474836.48, 1e300, -1, math.Inf(1), math.NaN()} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, summa float64) {
+		db := &withdrawRecorder{}
+		s := &Service{withdrawDB: db}
+		err := s.Withdraw(context.Background(), 1, "12345678903", summa)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidSum) || len(db.cents) != 0 {
+				t.Fatalf("Withdraw(%v): err = %v, calls = %v", summa, err, db.cents)
+			}
+			return
+		}
+		if len(db.cents) != 1 || db.cents[0] <= 0 {
+			t.Fatalf("Withdraw(%v) reached db with %v", summa, db.cents)
+		}
+		// Округление до ближайшей копейки.
+		if diff := math.Abs(float64(db.cents[0]) - summa*100); diff > 0.5 {
+			t.Fatalf("Withdraw(%v) stored %d cents", summa, db.cents[0])
+		}
+	})
+}
+
+// Суммы, которые не помещаются в int32 копеек или меньше копейки, до базы не доходят.
+func TestService_Withdraw_InvalidSum(t *testing.T) {
+	for _, summa := range []float64{0, -1, 0.004, 21474836.48, 42949673.00, 1e300, math.Inf(1), math.NaN()} {
+		db := &withdrawRecorder{}
+		s := &Service{withdrawDB: db}
+		if err := s.Withdraw(context.Background(), 1, "12345678903", summa); !errors.Is(err, ErrInvalidSum) || len(db.cents) != 0 {
+			t.Errorf("Withdraw(%v): err = %v, calls = %v", summa, err, db.cents)
+		}
+	}
+	db := &withdrawRecorder{}
+	s := &Service{withdrawDB: db}
+	if err := s.Withdraw(context.Background(), 1, "12345678903", 21474836.47); err != nil || len(db.cents) != 1 || db.cents[0] != math.MaxInt32 {
+		t.Fatalf("Withdraw(MaxInt32 cents): err = %v, calls = %v", err, db.cents)
+	}
+}
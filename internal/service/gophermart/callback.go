@@ -0,0 +1,31 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ApplyAccrualCallback применяет статус заказа, присланный системой начислений.
+// Статус передаётся в формате системы начислений (REGISTERED, PROCESSING, ...).
+func (s *Service) ApplyAccrualCallback(ctx context.Context, number string, status string, accrual float64) error {
+	const msg = "service.ApplyAccrualCallback"
+	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
+
+	orderStatus, ok := StatusFromAccrual(status)
+	if !ok {
+		return ErrInvalidAccrualStatus
+	}
+	if accrual < 0 || (accrual > 0 && orderStatus != StatusProcessed) {
+		return ErrInvalidAccrualStatus
+	}
+	resp := &AccrualResponse{OrderNumber: number, Status: orderStatus, Accrual: accrual}
+	err := s.worker.applyCallback(ctx, resp)
+	if errors.Is(err, ErrNoRow) {
+		return ErrOrderNotFound
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package gophermart
+
+import "errors"
+
+// Ошибки сервиса. Транспорт (HTTP, gRPC) сам решает, каким статусом их отдать.
+var (
+	ErrUserAlreadyExists = errors.New("user already exist")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidPassword   = errors.New("invalid password")
+	ErrInvalidToken      = errors.New("invalid token")
+	ErrNoToken           = errors.New("unauthorized")
+	ErrEmptyField        = errors.New("empty field login or password")
+
+	ErrInvalidOrderNumber      = errors.New("invalid order number")
+	ErrOrderOwnedByAnotherUser = errors.New("order uploaded by another user")
+	ErrOrderNotFound           = errors.New("order not found")
+	ErrInvalidAccrualStatus    = errors.New("invalid accrual status")
+
+	ErrInvalidSum               = errors.New("invalid sum")
+	ErrNotEnoughBalance         = errors.New("not enough balance")
+	ErrWithdrawAlreadyProcessed = errors.New("withdraw already processed")
+
+	ErrInvalidWebhookURL    = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookSecret = errors.New("webhook secret must be at least 16 characters")
+	ErrWebhookAlreadyExists = errors.New("webhook already exists")
+	ErrWebhookNotFound      = errors.New("webhook not found")
+)
@@ -0,0 +1,77 @@
+package gophermart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+)
+
+// userEventsReplayLimit — сколько пропущенных событий отдаётся за одно подключение.
+// Остальное клиент дочитает, переподключившись с последним полученным ID.
+const userEventsReplayLimit = 1000
+
+// SubscribeEvents отдаёт события пользователя: сначала записанные
+// после lastEventID, затем новые. Канал закрывается при отмене ctx или когда
+// клиенту нужно переподключиться.
+func (s *Service) SubscribeEvents(ctx context.Context, userID int32, lastEventID int64) (<-chan outbox.Event, error) {
+	const msg = "service.SubscribeEvents"
+	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
+
+	// Подписка раньше чтения пропущенного: событие, записанное между ними,
+	// придёт дважды и будет отброшено, но не потеряется.
+	live, unsubscribe := s.events.Subscribe(userID)
+	var (
+		replay []outbox.Event
+		err    error
+	)
+	if lastEventID > 0 {
+		replay, err = s.eventsDB.UserEventsAfter(ctx, userID, lastEventID, userEventsReplayLimit)
+		if err != nil {
+			unsubscribe()
+			return nil, wrapError(err)
+		}
+	}
+
+	out := make(chan outbox.Event)
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+
+		send := func(e outbox.Event) bool {
+			select {
+			case out <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		seen := make(map[int64]struct{}, len(replay))
+		for _, e := range replay {
+			seen[e.ID] = struct{}{}
+			if !send(e) {
+				return
+			}
+		}
+		if len(replay) == userEventsReplayLimit {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-live:
+				if !ok {
+					return
+				}
+				if _, dup := seen[e.ID]; dup {
+					continue
+				}
+				if !send(e) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,71 @@
+package gophermart
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_SubscribeEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID int64
+		replay      []outbox.Event
+		live        []outbox.Event
+		want        []int64
+	}{
+		{
+			name: "live only",
+			live: []outbox.Event{{ID: 3}, {ID: 4}},
+			want: []int64{3, 4},
+		},
+		{
+			name:        "replay then live without duplicates",
+			lastEventID: 1,
+			replay:      []outbox.Event{{ID: 2}, {ID: 3}},
+			live:        []outbox.Event{{ID: 3}, {ID: 4}},
+			want:        []int64{2, 3, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			broker := NewMockEventBroker(ctrl)
+			db := NewMockEventsDB(ctrl)
+
+			live := make(chan outbox.Event, len(tt.live))
+			for _, e := range tt.live {
+				live <- e
+			}
+			close(live)
+			unsubscribed := false
+			broker.EXPECT().Subscribe(int32(1)).Return(live, func() { unsubscribed = true })
+			if tt.lastEventID > 0 {
+				db.EXPECT().UserEventsAfter(gomock.Any(), int32(1), tt.lastEventID, int32(userEventsReplayLimit)).Return(tt.replay, nil)
+			}
+
+			s := &Service{events: broker, eventsDB: db}
+			ch, err := s.SubscribeEvents(context.Background(), 1, tt.lastEventID)
+			if err != nil {
+				t.Fatalf("SubscribeEvents: %v", err)
+			}
+			var got []int64
+			for e := range ch {
+				got = append(got, e.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+			if !unsubscribed {
+				t.Fatal("subscription must be released")
+			}
+		})
+	}
+}
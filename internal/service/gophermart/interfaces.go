@@ -2,13 +2,11 @@ package gophermart
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
-)
 
-var (
-	ErrUserAlreadyExists = errors.New("user already exist")
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
 )
 
 type UserCRUD interface {
@@ -18,7 +16,7 @@ type UserCRUD interface {
 }
 
 type User struct {
-	ID           int32 `json:"id"`
+	ID           int32  `json:"id"`
 	Login        string `json:"login"`
 	HashPassword string `json:"hash_password"`
 }
@@ -35,10 +33,25 @@ type GetAPIOrdered interface {
 	GetOrder(ctx context.Context, number string) (response *AccrualResponse, err error)
 }
 
+// AccrualHealth — circuit breaker клиента начислений, по которому воркер решает,
+// стоит ли сейчас опрашивать сервис.
+type AccrualHealth interface {
+	State() breaker.State
+	Subscribe(f func(from breaker.State, to breaker.State))
+}
+
+// Runner — фоновая задача, которая запускается и останавливается вместе с сервисом.
+type Runner interface {
+	Run()
+	Stop()
+}
+
 type AccrualResponse struct {
 	OrderNumber string  `json:"order"`
 	Status      string  `json:"status"`
 	Accrual     float64 `json:"accrual"`
+	// Unregistered — система начислений не знает заказ (ответ 204).
+	Unregistered bool `json:"-"`
 }
 
 func (a AccrualResponse) String() string {
@@ -52,8 +65,10 @@ type Ordered interface {
 
 type ListUpdateApplyAccrual interface {
 	ListPending(ctx context.Context, limit int32, statuses []string, timeSync time.Time) ([]Order, error)
+	OrderByNumber(ctx context.Context, number string) (Order, error)
 	UpdateFromAccrual(ctx context.Context, number string, status string, nextSync time.Time) error
 	UpdateSyncTime(ctx context.Context, number string, nextSync time.Time) error
+	MarkInvalid(ctx context.Context, number string) error
 	ApplyAccrual(ctx context.Context, number string, accrual int64, userID int32) error
 }
 
@@ -64,3 +79,42 @@ type Order struct {
 	Accrual     int32
 	UploadedAt  time.Time
 }
+
+// EventsDB читает события пользователя, пропущенные клиентом потока.
+type EventsDB interface {
+	UserEventsAfter(ctx context.Context, userID int32, afterID int64, limit int32) ([]outbox.Event, error)
+}
+
+// EventBroker доставляет новые события пользователя. Канал закрывается, когда
+// непрерывность потока нарушена и клиенту нужно переподключиться.
+type EventBroker interface {
+	Runner
+	Subscribe(userID int32) (events <-chan outbox.Event, unsubscribe func())
+}
+
+type WebhookDB interface {
+	CreateWebhook(ctx context.Context, userID int32, url string, secret string) (Webhook, error)
+	ListWebhooks(ctx context.Context, userID int32) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int32, id int32) error
+	ListWebhookDeliveries(ctx context.Context, userID int32, webhookID int32, limit int32) ([]WebhookDelivery, error)
+}
+
+type Webhook struct {
+	ID        int32
+	UserID    int32
+	URL       string
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int32
+	LastStatusCode int32
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    time.Time
+	NextAttemptAt  time.Time
+}
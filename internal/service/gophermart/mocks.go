@@ -14,6 +14,8 @@ import (
 	reflect "reflect"
 	time "time"
 
+	breaker "github.com/IvanOplesnin/gofermart.git/internal/breaker"
+	outbox "github.com/IvanOplesnin/gofermart.git/internal/outbox"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -203,6 +205,104 @@ func (mr *MockGetAPIOrderedMockRecorder) GetOrder(ctx, number any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockGetAPIOrdered)(nil).GetOrder), ctx, number)
 }
 
+// MockAccrualHealth is a mock of AccrualHealth interface.
+type MockAccrualHealth struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualHealthMockRecorder
+	isgomock struct{}
+}
+
+// MockAccrualHealthMockRecorder is the mock recorder for MockAccrualHealth.
+type MockAccrualHealthMockRecorder struct {
+	mock *MockAccrualHealth
+}
+
+// NewMockAccrualHealth creates a new mock instance.
+func NewMockAccrualHealth(ctrl *gomock.Controller) *MockAccrualHealth {
+	mock := &MockAccrualHealth{ctrl: ctrl}
+	mock.recorder = &MockAccrualHealthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualHealth) EXPECT() *MockAccrualHealthMockRecorder {
+	return m.recorder
+}
+
+// State mocks base method.
+func (m *MockAccrualHealth) State() breaker.State {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(breaker.State)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockAccrualHealthMockRecorder) State() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockAccrualHealth)(nil).State))
+}
+
+// Subscribe mocks base method.
+func (m *MockAccrualHealth) Subscribe(f func(breaker.State, breaker.State)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Subscribe", f)
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockAccrualHealthMockRecorder) Subscribe(f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockAccrualHealth)(nil).Subscribe), f)
+}
+
+// MockRunner is a mock of Runner interface.
+type MockRunner struct {
+	ctrl     *gomock.Controller
+	recorder *MockRunnerMockRecorder
+	isgomock struct{}
+}
+
+// MockRunnerMockRecorder is the mock recorder for MockRunner.
+type MockRunnerMockRecorder struct {
+	mock *MockRunner
+}
+
+// NewMockRunner creates a new mock instance.
+func NewMockRunner(ctrl *gomock.Controller) *MockRunner {
+	mock := &MockRunner{ctrl: ctrl}
+	mock.recorder = &MockRunnerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRunner) EXPECT() *MockRunnerMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockRunner) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockRunnerMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockRunner)(nil).Run))
+}
+
+// Stop mocks base method.
+func (m *MockRunner) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockRunnerMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockRunner)(nil).Stop))
+}
+
 // MockOrdered is a mock of Ordered interface.
 type MockOrdered struct {
 	ctrl     *gomock.Controller
@@ -311,6 +411,35 @@ func (mr *MockListUpdateApplyAccrualMockRecorder) ListPending(ctx, limit, status
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockListUpdateApplyAccrual)(nil).ListPending), ctx, limit, statuses, timeSync)
 }
 
+// MarkInvalid mocks base method.
+func (m *MockListUpdateApplyAccrual) MarkInvalid(ctx context.Context, number string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInvalid", ctx, number)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkInvalid indicates an expected call of MarkInvalid.
+func (mr *MockListUpdateApplyAccrualMockRecorder) MarkInvalid(ctx, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInvalid", reflect.TypeOf((*MockListUpdateApplyAccrual)(nil).MarkInvalid), ctx, number)
+}
+
+// OrderByNumber mocks base method.
+func (m *MockListUpdateApplyAccrual) OrderByNumber(ctx context.Context, number string) (Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrderByNumber", ctx, number)
+	ret0, _ := ret[0].(Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrderByNumber indicates an expected call of OrderByNumber.
+func (mr *MockListUpdateApplyAccrualMockRecorder) OrderByNumber(ctx, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderByNumber", reflect.TypeOf((*MockListUpdateApplyAccrual)(nil).OrderByNumber), ctx, number)
+}
+
 // UpdateFromAccrual mocks base method.
 func (m *MockListUpdateApplyAccrual) UpdateFromAccrual(ctx context.Context, number, status string, nextSync time.Time) error {
 	m.ctrl.T.Helper()
@@ -338,3 +467,188 @@ func (mr *MockListUpdateApplyAccrualMockRecorder) UpdateSyncTime(ctx, number, ne
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSyncTime", reflect.TypeOf((*MockListUpdateApplyAccrual)(nil).UpdateSyncTime), ctx, number, nextSync)
 }
+
+// MockEventsDB is a mock of EventsDB interface.
+type MockEventsDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventsDBMockRecorder
+	isgomock struct{}
+}
+
+// MockEventsDBMockRecorder is the mock recorder for MockEventsDB.
+type MockEventsDBMockRecorder struct {
+	mock *MockEventsDB
+}
+
+// NewMockEventsDB creates a new mock instance.
+func NewMockEventsDB(ctrl *gomock.Controller) *MockEventsDB {
+	mock := &MockEventsDB{ctrl: ctrl}
+	mock.recorder = &MockEventsDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventsDB) EXPECT() *MockEventsDBMockRecorder {
+	return m.recorder
+}
+
+// UserEventsAfter mocks base method.
+func (m *MockEventsDB) UserEventsAfter(ctx context.Context, userID int32, afterID int64, limit int32) ([]outbox.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserEventsAfter", ctx, userID, afterID, limit)
+	ret0, _ := ret[0].([]outbox.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserEventsAfter indicates an expected call of UserEventsAfter.
+func (mr *MockEventsDBMockRecorder) UserEventsAfter(ctx, userID, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserEventsAfter", reflect.TypeOf((*MockEventsDB)(nil).UserEventsAfter), ctx, userID, afterID, limit)
+}
+
+// MockEventBroker is a mock of EventBroker interface.
+type MockEventBroker struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventBrokerMockRecorder
+	isgomock struct{}
+}
+
+// MockEventBrokerMockRecorder is the mock recorder for MockEventBroker.
+type MockEventBrokerMockRecorder struct {
+	mock *MockEventBroker
+}
+
+// NewMockEventBroker creates a new mock instance.
+func NewMockEventBroker(ctrl *gomock.Controller) *MockEventBroker {
+	mock := &MockEventBroker{ctrl: ctrl}
+	mock.recorder = &MockEventBrokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventBroker) EXPECT() *MockEventBrokerMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockEventBroker) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockEventBrokerMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockEventBroker)(nil).Run))
+}
+
+// Stop mocks base method.
+func (m *MockEventBroker) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockEventBrokerMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockEventBroker)(nil).Stop))
+}
+
+// Subscribe mocks base method.
+func (m *MockEventBroker) Subscribe(userID int32) (<-chan outbox.Event, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userID)
+	ret0, _ := ret[0].(<-chan outbox.Event)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventBrokerMockRecorder) Subscribe(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventBroker)(nil).Subscribe), userID)
+}
+
+// MockWebhookDB is a mock of WebhookDB interface.
+type MockWebhookDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookDBMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookDBMockRecorder is the mock recorder for MockWebhookDB.
+type MockWebhookDBMockRecorder struct {
+	mock *MockWebhookDB
+}
+
+// NewMockWebhookDB creates a new mock instance.
+func NewMockWebhookDB(ctrl *gomock.Controller) *MockWebhookDB {
+	mock := &MockWebhookDB{ctrl: ctrl}
+	mock.recorder = &MockWebhookDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookDB) EXPECT() *MockWebhookDBMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookDB) CreateWebhook(ctx context.Context, userID int32, url, secret string) (Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, userID, url, secret)
+	ret0, _ := ret[0].(Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookDBMockRecorder) CreateWebhook(ctx, userID, url, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookDB)(nil).CreateWebhook), ctx, userID, url, secret)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookDB) DeleteWebhook(ctx context.Context, userID, id int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookDBMockRecorder) DeleteWebhook(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookDB)(nil).DeleteWebhook), ctx, userID, id)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockWebhookDB) ListWebhookDeliveries(ctx context.Context, userID, webhookID, limit int32) ([]WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, userID, webhookID, limit)
+	ret0, _ := ret[0].([]WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockWebhookDBMockRecorder) ListWebhookDeliveries(ctx, userID, webhookID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockWebhookDB)(nil).ListWebhookDeliveries), ctx, userID, webhookID, limit)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWebhookDB) ListWebhooks(ctx context.Context, userID int32) ([]Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, userID)
+	ret0, _ := ret[0].([]Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWebhookDBMockRecorder) ListWebhooks(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWebhookDB)(nil).ListWebhooks), ctx, userID)
+}
@@ -4,30 +4,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
-
-	"github.com/IvanOplesnin/gofermart.git/internal/handler"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func (s *Service) AddOrder(ctx context.Context, orderID string) (bool, error) {
+// UserOrder — заказ в том виде, в каком его видит пользователь.
+// Accrual в баллах, nil — начисления нет.
+type UserOrder struct {
+	Number     string
+	Status     string
+	Accrual    *float64
+	UploadedAt time.Time
+}
+
+// AddOrder загружает заказ пользователя. exist — заказ уже был загружен им же.
+func (s *Service) AddOrder(ctx context.Context, userID int32, orderID string) (exist bool, err error) {
 	const msg = "service.AddOrder"
 	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
 
 	if !validateLuna(orderID) {
-		return false, handler.ErrInvalidOrderID
+		return false, ErrInvalidOrderNumber
 	}
-	userIDFromContext, err := handler.UserIDFromCtx(ctx)
-	if err != nil {
-		return false, wrapError(err)
-	}
-	created, owner, err := s.Ordered.CreateOrder(ctx, userIDFromContext, orderID)
+	// Храним номер без пробелов, иначе "1234 5678 903" и "12345678903"
+	// стали бы разными заказами.
+	orderID = normalizeOrderNumber(orderID)
+	created, owner, err := s.Ordered.CreateOrder(ctx, userID, orderID)
 	if err != nil {
 		return false, wrapError(err)
 	}
 	if created {
 		return false, nil
 	}
-	if owner != userIDFromContext {
-		return false, handler.ErrAnotherUserOrder
+	if owner != userID {
+		return false, ErrOrderOwnedByAnotherUser
 	}
 	return true, nil
 }
@@ -35,11 +45,10 @@ func (s *Service) AddOrder(ctx context.Context, orderID string) (bool, error) {
 // validateLuna checks a numeric string with the Luhn algorithm.
 // Returns false for empty strings, non-digits, or if the check fails.
 func validateLuna(number string) bool {
-	// Optional: ignore spaces (sometimes numbers come formatted)
-	// If you don't want that, remove this block.
+	// Номер может прийти отформатированным, пробелы не учитываем.
 	buf := make([]rune, 0, len(number))
 	for _, r := range number {
-		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+		if isOrderSpace(r) {
 			continue
 		}
 		buf = append(buf, r)
@@ -69,31 +78,60 @@ func validateLuna(number string) bool {
 	return sum%10 == 0
 }
 
-func (s *Service) Orders(ctx context.Context) ([]handler.Order, error) {
+func isOrderSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// normalizeOrderNumber убирает из номера пробелы, которые пропускает validateLuna.
+func normalizeOrderNumber(number string) string {
+	return strings.Map(func(r rune) rune {
+		if isOrderSpace(r) {
+			return -1
+		}
+		return r
+	}, number)
+}
+
+// WithCheckDigit дописывает к body из цифр контрольную цифру Луна,
+// так что результат проходит validateLuna.
+func WithCheckDigit(body string) string {
+	sum := 0
+	double := true // контрольная цифра встанет справа, body сдвигается на разряд
+	for i := len(body) - 1; i >= 0; i-- {
+		d := int(body[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return body + strconv.Itoa((10-sum%10)%10)
+}
+
+func (s *Service) Orders(ctx context.Context, userID int32) ([]UserOrder, error) {
 	const msg = "service.Orders"
 	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
 
-	userID, err := handler.UserIDFromCtx(ctx)
-	if err != nil {
-		return nil, wrapError(err)
-	}
-	orders, err := s.Ordered.GetOrders(ctx, int32(userID))
+	orders, err := s.Ordered.GetOrders(ctx, userID)
 	if errors.Is(err, ErrNoRow) {
-		return []handler.Order{}, nil
+		return []UserOrder{}, nil
 	}
 	if err != nil {
 		return nil, wrapError(err)
 	}
-	respOrders := make([]handler.Order, 0, len(orders))
+	result := make([]UserOrder, 0, len(orders))
 	for _, o := range orders {
-		respOrders = append(respOrders, handler.Order{
+		result = append(result, UserOrder{
 			Number:     o.Number,
 			Status:     o.OrderStatus,
 			Accrual:    AccrualToFloatPtr(o.Accrual),
-			UploadedAt: handler.RFC3339Time(o.UploadedAt),
+			UploadedAt: o.UploadedAt,
 		})
 	}
-	return respOrders, nil
+	return result, nil
 }
 
 func AccrualToFloatPtr(accrual int32) *float64 {
@@ -0,0 +1,69 @@
+package gophermart
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Статусы заказа. PROCESSED и INVALID терминальные: после них заказ
+// больше не опрашивается и не меняется.
+const (
+	StatusNew        = "NEW"
+	StatusProcessing = "PROCESSING"
+	StatusInvalid    = "INVALID"
+	StatusProcessed  = "PROCESSED"
+)
+
+var ErrIllegalTransition = errors.New("illegal order status transition")
+
+// orderTransitions — допустимые переходы статусов. Статус без исходящих
+// переходов терминальный.
+var orderTransitions = map[string][]string{
+	StatusNew:        {StatusProcessing, StatusInvalid, StatusProcessed},
+	StatusProcessing: {StatusInvalid, StatusProcessed},
+	StatusInvalid:    {},
+	StatusProcessed:  {},
+}
+
+// accrualStatuses — статусы системы начислений и соответствующие им статусы заказа.
+var accrualStatuses = map[string]string{
+	"REGISTERED": StatusNew,
+	"INVALID":    StatusInvalid,
+	"PROCESSING": StatusProcessing,
+	"PROCESSED":  StatusProcessed,
+}
+
+// StatusFromAccrual переводит статус системы начислений в статус заказа.
+func StatusFromAccrual(status string) (string, bool) {
+	s, ok := accrualStatuses[status]
+	return s, ok
+}
+
+// pendingStatuses — статусы, которые воркер опрашивает в системе начислений.
+var pendingStatuses = []string{StatusNew, StatusProcessing}
+
+func IsTerminal(status string) bool {
+	next, ok := orderTransitions[status]
+	return ok && len(next) == 0
+}
+
+// CheckTransition проверяет переход from → to. Переход в тот же статус
+// допустим только для нетерминальных статусов и ничего не меняет.
+func CheckTransition(from string, to string) error {
+	next, ok := orderTransitions[from]
+	if !ok {
+		return fmt.Errorf("%w: unknown status %q", ErrIllegalTransition, from)
+	}
+	if _, ok := orderTransitions[to]; !ok {
+		return fmt.Errorf("%w: unknown status %q", ErrIllegalTransition, to)
+	}
+	if from == to && !IsTerminal(from) {
+		return nil
+	}
+	for _, s := range next {
+		if s == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+}
@@ -0,0 +1,50 @@
+package gophermart
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{StatusNew, StatusNew, true},
+		{StatusNew, StatusProcessing, true},
+		{StatusNew, StatusInvalid, true},
+		{StatusNew, StatusProcessed, true},
+		{StatusProcessing, StatusProcessing, true},
+		{StatusProcessing, StatusProcessed, true},
+		{StatusProcessing, StatusInvalid, true},
+		{StatusProcessing, StatusNew, false},
+		{StatusProcessed, StatusProcessing, false},
+		{StatusProcessed, StatusProcessed, false},
+		{StatusInvalid, StatusProcessed, false},
+		{StatusNew, "DONE", false},
+		{"DONE", StatusNew, false},
+	}
+	for _, tt := range tests {
+		err := CheckTransition(tt.from, tt.to)
+		if tt.ok && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tt.from, tt.to, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrIllegalTransition) {
+			t.Errorf("%s -> %s: expected ErrIllegalTransition, got %v", tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	for status, want := range map[string]bool{
+		StatusNew:        false,
+		StatusProcessing: false,
+		StatusInvalid:    true,
+		StatusProcessed:  true,
+		"DONE":           false,
+	} {
+		if got := IsTerminal(status); got != want {
+			t.Errorf("IsTerminal(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
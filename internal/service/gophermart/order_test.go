@@ -0,0 +1,133 @@
+package gophermart
+
+import (
+	"context"
+	"testing"
+	"testing/quick"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestValidateLuna(t *testing.T) {
+	tests := []struct {
+		number string
+		ok     bool
+	}{
+		{"79927398713", true},
+		{"12345678903", true},
+		{"4561 2612 1234 5467", true},
+		{"12345678900", false},
+		{"1234567890a", false},
+		{"", false},
+		{" ", false},
+	}
+	for _, tt := range tests {
+		if got := validateLuna(tt.number); got != tt.ok {
+			t.Errorf("validateLuna(%q) = %v, want %v", tt.number, got, tt.ok)
+		}
+	}
+}
+
+func BenchmarkValidateLuna(b *testing.B) {
+	benchmarks := []struct {
+		name   string
+		number string
+	}{
+		{"short", "79927398713"},
+		{"long", "4561261212345467456126121234546745612612"},
+		{"spaces", "4561 2612 1234 5467"},
+		{"invalid", "1234567890a"},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				validateLuna(bm.number)
+			}
+		})
+	}
+}
+
+// luhnReference — независимая проверка Луна для строки из одних цифр.
+func luhnReference(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum := 0
+	for i := range len(number) {
+		c := number[len(number)-1-i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			d = d * 2 % 9
+			if d == 0 && c == '9' {
+				d = 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func FuzzValidateLuna(f *testing.F) {
+	for _, seed := range []string{"79927398713", "1234 5678 903", "12345678903\n", "\t\r", "0", "٣", "12345678900", "+12345678903"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, number string) {
+		ok := validateLuna(number)
+		normalized := normalizeOrderNumber(number)
+		if ok != luhnReference(normalized) {
+			t.Fatalf("validateLuna(%q) = %v, reference on %q disagrees", number, ok, normalized)
+		}
+		if ok != validateLuna(normalized) {
+			t.Fatalf("normalization changed the result for %q", number)
+		}
+		if !ok {
+			return
+		}
+		// Луна ловит любую ошибку в одной цифре.
+		last := normalized[len(normalized)-1]
+		for d := byte('0'); d <= '9'; d++ {
+			if d != last && validateLuna(normalized[:len(normalized)-1]+string(d)) {
+				t.Fatalf("%q with last digit %c must be invalid", normalized, d)
+			}
+		}
+	})
+}
+
+func TestWithCheckDigit_RoundTrip(t *testing.T) {
+	property := func(digits []byte) bool {
+		body := make([]byte, len(digits))
+		for i, d := range digits {
+			body[i] = '0' + d%10
+		}
+		number := WithCheckDigit(string(body))
+		if len(number) != len(body)+1 || number[:len(body)] != string(body) || !validateLuna(number) {
+			return false
+		}
+		for d := byte('0'); d <= '9'; d++ {
+			if d != number[len(body)] && validateLuna(string(body)+string(d)) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_AddOrder_NormalizesNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := NewMockOrdered(ctrl)
+	db.EXPECT().CreateOrder(gomock.Any(), int32(7), "12345678903").Return(true, int32(7), nil).Times(2)
+	s := &Service{Ordered: db}
+
+	for _, number := range []string{"1234 5678 903", "12345678903\r\n"} {
+		if exist, err := s.AddOrder(context.Background(), 7, number); err != nil || exist {
+			t.Fatalf("AddOrder(%q) = %v, %v", number, exist, err)
+		}
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"strconv"
 
 	"github.com/IvanOplesnin/gofermart.git/internal/config"
-	"github.com/IvanOplesnin/gofermart.git/internal/handler"
-	mw "github.com/IvanOplesnin/gofermart.git/internal/handler/middleware"
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -23,10 +21,16 @@ type Service struct {
 	worker        *worker
 	workerDB      ListUpdateApplyAccrual
 	clientAccrual GetAPIOrdered
+	background    []Runner
 
 	withdrawDB WithdrawerDB
 
 	balanceDB BalanceDB
+
+	webhookDB WebhookDB
+
+	eventsDB EventsDB
+	events   EventBroker
 }
 
 var ErrNoRow = errors.New("no row")
@@ -38,9 +42,19 @@ type ServiceDeps struct {
 
 	WorkerDB      ListUpdateApplyAccrual
 	AccrualClient GetAPIOrdered
+	// AccrualHealth необязателен: без него воркер опрашивает сервис на каждом тике.
+	AccrualHealth AccrualHealth
 
 	WithdrawerDB WithdrawerDB
 	BalanceDB    BalanceDB
+	WebhookDB    WebhookDB
+	EventsDB     EventsDB
+	Events       EventBroker
+
+	// Relay и WebhookDispatcher необязательны: без relay события остаются
+	// в outbox, без dispatcher доставки вебхуков копятся в очереди.
+	Relay             Runner
+	WebhookDispatcher Runner
 }
 
 func New(cfg *config.Config, deps ServiceDeps) (*Service, error) {
@@ -68,27 +82,55 @@ func New(cfg *config.Config, deps ServiceDeps) (*Service, error) {
 	if deps.BalanceDB == nil {
 		return nil, fmt.Errorf("gophermart.New: balanceDb is nil")
 	}
+	if deps.WebhookDB == nil {
+		return nil, fmt.Errorf("gophermart.New: WebhookDB is nil")
+	}
+	if deps.EventsDB == nil || deps.Events == nil {
+		return nil, fmt.Errorf("gophermart.New: EventsDB or Events is nil")
+	}
 
 	svc := &Service{
 		hash:       deps.Hasher,
-		secret:     []byte(cfg.Secret),
+		secret:     []byte(cfg.Auth.Secret),
 		userCRUD:   deps.UserCRUD,
 		Ordered:    deps.Ordered,
 		withdrawDB: deps.WithdrawerDB,
 		balanceDB:  deps.BalanceDB,
+		webhookDB:  deps.WebhookDB,
+		eventsDB:   deps.EventsDB,
+		events:     deps.Events,
+	}
+	for _, r := range []Runner{deps.Relay, deps.WebhookDispatcher, deps.Events} {
+		if r != nil {
+			svc.background = append(svc.background, r)
+		}
 	}
 
 	svc.worker = newWorker(deps.AccrualClient, deps.WorkerDB)
+	svc.worker.applyConfig(cfg.Worker)
+	if deps.AccrualHealth != nil {
+		svc.worker.setAccrualHealth(deps.AccrualHealth)
+	}
+	if cfg.Accrual.CallbackSecret != "" {
+		svc.worker.enableCallbacks(cfg.Worker.FallbackSyncInterval)
+	}
 
 	return svc, nil
 }
 
 func (s *Service) Start() {
 	s.worker.Run()
+	for _, r := range s.background {
+		r.Run()
+	}
 }
 
+// Stop сначала останавливает воркер, чтобы relay успел забрать его последние события.
 func (s *Service) Stop() {
 	s.worker.Stop()
+	for _, r := range s.background {
+		r.Stop()
+	}
 }
 
 func (s *Service) Register(ctx context.Context, login string, password string) (string, error) {
@@ -100,11 +142,11 @@ func (s *Service) Register(ctx context.Context, login string, password string) (
 		return "", wrapError(err)
 	}
 
-	logger.Log.Debugf("login: %s, hash: %s", login, hashPass)
+	logger.FromContext(ctx).Debugf("login: %s, hash: %s", login, hashPass)
 	userID, err := s.userCRUD.AddUser(ctx, login, hashPass)
 	if err != nil {
 		if errors.Is(err, ErrUserAlreadyExists) {
-			return "", handler.ErrUserAlreadyExists
+			return "", ErrUserAlreadyExists
 		}
 		return "", wrapError(err)
 	}
@@ -121,7 +163,7 @@ func (s *Service) Auth(ctx context.Context, login string, password string) (stri
 
 	user, err := s.userCRUD.GetUserByLogin(ctx, login)
 	if errors.Is(err, ErrNoRow) {
-		return "", handler.ErrUserNotFound
+		return "", ErrUserNotFound
 	}
 	if err != nil {
 		return "", wrapError(err)
@@ -131,7 +173,7 @@ func (s *Service) Auth(ctx context.Context, login string, password string) (stri
 		return "", wrapError(err)
 	}
 	if !ok {
-		return "", handler.ErrInvalidPassword
+		return "", ErrInvalidPassword
 	}
 
 	tokenString, err := JwtToken(user.ID, s.secret)
@@ -141,43 +183,38 @@ func (s *Service) Auth(ctx context.Context, login string, password string) (stri
 	return tokenString, nil
 }
 
-func (s *Service) CheckToken(ctx context.Context, token string) (cl mw.Claims, err error) {
+// CheckToken возвращает ID пользователя из токена. Токен удалённого
+// пользователя считается недействительным.
+func (s *Service) CheckToken(ctx context.Context, token string) (userID int32, err error) {
 	const msg = "service.CheckToken"
 	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
 
 	defer func() {
 		if err != nil {
-			logger.Log.Debugf("%s: %s", msg, err)
+			logger.FromContext(ctx).Debugf("%s: %s", msg, err)
 		}
 	}()
 
 	claims, err := ParseJwtToken(token, s.secret)
 	if err != nil {
-		return mw.Claims{}, err
+		return 0, err
 	}
 
-	logger.Log.Debugf("claims.UserID: %v", claims.UserID)
+	logger.FromContext(ctx).Debugf("claims.UserID: %v", claims.UserID)
 
 	_, err = s.userCRUD.GetUserByID(ctx, claims.UserID)
 	if errors.Is(err, ErrNoRow) {
-		return mw.Claims{}, handler.ErrUserNotFound
+		return 0, ErrInvalidToken
 	}
 	if err != nil {
-		return mw.Claims{}, wrapError(err)
+		return 0, wrapError(err)
 	}
 
-	return mw.Claims{UserID: claims.UserID}, nil
+	return claims.UserID, nil
 }
 
 func ParseJwtToken(token string, secret []byte) (Claims, error) {
 	var claims JwtClaims
-	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
-		return secret, nil
-	})
-	if err != nil {
-		return Claims{}, err
-	}
-
 	keyFunc := func(t *jwt.Token) (any, error) {
 		if t.Method == nil || t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
@@ -188,17 +225,15 @@ func ParseJwtToken(token string, secret []byte) (Claims, error) {
 
 	tok, err := parser.ParseWithClaims(token, &claims, keyFunc)
 	if err != nil {
-		logger.Log.Errorf("parseJwtError: %s", err.Error())
-		return Claims{}, mw.ErrInvalidToken
+		return Claims{}, fmt.Errorf("%w: %s", ErrInvalidToken, err.Error())
 	}
 
 	if tok == nil || !tok.Valid {
-		return Claims{}, mw.ErrInvalidToken
+		return Claims{}, ErrInvalidToken
 	}
 
-	logger.Log.Debugf("claims.UserID: %v", claims.UserID)
 	if claims.UserID == 0 {
-		return Claims{}, mw.ErrNotUserFound
+		return Claims{}, ErrInvalidToken
 	}
 
 	return Claims{UserID: claims.UserID}, nil
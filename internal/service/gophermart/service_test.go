@@ -0,0 +1,170 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var benchSecret = []byte("bench-secret")
+
+func TestParseJwtToken(t *testing.T) {
+	token, err := JwtToken(42, benchSecret)
+	if err != nil {
+		t.Fatalf("JwtToken: %v", err)
+	}
+	claims, err := ParseJwtToken(token, benchSecret)
+	if err != nil || claims.UserID != 42 {
+		t.Fatalf("ParseJwtToken = %+v, %v", claims, err)
+	}
+}
+
+// Поддельный токен — это ErrInvalidToken (401), а не внутренняя ошибка (500).
+func TestParseJwtToken_Forged(t *testing.T) {
+	claims := JwtClaims{Claims: Claims{UserID: 42}}
+	sign := func(method jwt.SigningMethod, key any) string {
+		t.Helper()
+		s, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return s
+	}
+	valid := sign(jwt.SigningMethodHS256, benchSecret)
+
+	tests := []struct {
+		name   string
+		token  string
+		secret []byte
+	}{
+		{name: "other secret", token: valid, secret: []byte("other")},
+		{name: "alg none", token: sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), secret: benchSecret},
+		{name: "alg HS512", token: sign(jwt.SigningMethodHS512, benchSecret), secret: benchSecret},
+		{name: "tampered payload", token: valid[:strings.LastIndex(valid, ".")-1] + "x" + valid[strings.LastIndex(valid, "."):], secret: benchSecret},
+		{name: "garbage", token: "not.a.token", secret: benchSecret},
+		{name: "empty", token: "", secret: benchSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseJwtToken(tt.token, tt.secret); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ParseJwtToken err = %v, want ErrInvalidToken", err)
+			}
+			s := &Service{secret: tt.secret}
+			if _, err := s.CheckToken(context.Background(), tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("CheckToken err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func BenchmarkJwtToken(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := JwtToken(42, benchSecret); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseJwtToken меряет проверку токена, которая выполняется на каждом запросе.
+func BenchmarkParseJwtToken(b *testing.B) {
+	token, err := JwtToken(42, benchSecret)
+	if err != nil {
+		b.Fatal(err)
+	}
+	// Отказы логируются на уровне error, вывод не должен мешать замеру.
+	out := logger.Log.Out
+	logger.Log.SetOutput(io.Discard)
+	b.Cleanup(func() { logger.Log.SetOutput(out) })
+
+	b.Run("valid", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := ParseJwtToken(token, benchSecret); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bad_signature", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := ParseJwtToken(token, []byte("other")); err == nil {
+				b.Fatal("expected error")
+			}
+		}
+	})
+}
+
+func FuzzParseJwtToken(f *testing.F) {
+	valid, err := JwtToken(42, benchSecret)
+	if err != nil {
+		f.Fatal(err)
+	}
+	zeroUser, _ := JwtToken(0, benchSecret)
+	for _, seed := range []string{
+		valid,
+		zeroUser,
+		valid[:len(valid)-2],
+		valid + "x",
+		// alg none
+		"eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJVc2VySUQiOjQyfQ.",
+		// HS512 с тем же секретом
+		"eyJhbGciOiJIUzUxMiIsInR5cCI6IkpXVCJ9.eyJVc2VySUQiOjQyfQ.signature",
+		"",
+		"a.b.c",
+	} {
+		f.Add(seed)
+	}
+	out := logger.Log.Out
+	logger.Log.SetOutput(io.Discard)
+	f.Cleanup(func() { logger.Log.SetOutput(out) })
+
+	f.Fuzz(func(t *testing.T, token string) {
+		claims, err := ParseJwtToken(token, benchSecret)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidToken) || claims != (Claims{}) {
+				t.Fatalf("ParseJwtToken(%q) = %+v, %v; want ErrInvalidToken", token, claims, err)
+			}
+			return
+		}
+		if claims.UserID == 0 {
+			t.Fatalf("ParseJwtToken(%q) accepted a token without user", token)
+		}
+		if _, err := ParseJwtToken(token, []byte("other-secret")); err == nil {
+			t.Fatalf("token %q is accepted with another secret", token)
+		}
+	})
+}
+
+func FuzzJwtToken_RoundTrip(f *testing.F) {
+	f.Add(int32(42), []byte("secret"))
+	f.Add(int32(-1), []byte{0})
+	f.Add(int32(math.MaxInt32), []byte("s"))
+	out := logger.Log.Out
+	logger.Log.SetOutput(io.Discard)
+	f.Cleanup(func() { logger.Log.SetOutput(out) })
+
+	f.Fuzz(func(t *testing.T, userID int32, secret []byte) {
+		if userID == 0 || len(secret) == 0 {
+			t.Skip()
+		}
+		token, err := JwtToken(userID, secret)
+		if err != nil {
+			t.Fatalf("JwtToken: %v", err)
+		}
+		claims, err := ParseJwtToken(token, secret)
+		if err != nil || claims.UserID != userID {
+			t.Fatalf("ParseJwtToken = %+v, %v; want user %d", claims, err, userID)
+		}
+		other := append([]byte{1}, secret...)
+		if _, err := ParseJwtToken(token, other); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("token accepted with another secret: err = %v", err)
+		}
+	})
+}
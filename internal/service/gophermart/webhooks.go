@@ -0,0 +1,74 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+const (
+	minWebhookSecretLength = 16
+	// webhookDeliveriesLimit — сколько последних доставок показывается в журнале.
+	webhookDeliveriesLimit = 100
+)
+
+func (s *Service) CreateWebhook(ctx context.Context, userID int32, rawURL string, secret string) (Webhook, error) {
+	const msg = "service.CreateWebhook"
+	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return Webhook{}, ErrInvalidWebhookURL
+	}
+	if len(secret) < minWebhookSecretLength {
+		return Webhook{}, ErrInvalidWebhookSecret
+	}
+	w, err := s.webhookDB.CreateWebhook(ctx, userID, rawURL, secret)
+	if errors.Is(err, ErrWebhookAlreadyExists) {
+		return Webhook{}, ErrWebhookAlreadyExists
+	}
+	if err != nil {
+		return Webhook{}, wrapError(err)
+	}
+	return w, nil
+}
+
+func (s *Service) Webhooks(ctx context.Context, userID int32) ([]Webhook, error) {
+	const msg = "service.Webhooks"
+	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
+
+	list, err := s.webhookDB.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return list, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, userID int32, id int32) error {
+	const msg = "service.DeleteWebhook"
+	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
+
+	err := s.webhookDB.DeleteWebhook(ctx, userID, id)
+	if errors.Is(err, ErrNoRow) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+func (s *Service) WebhookDeliveries(ctx context.Context, userID int32, id int32) ([]WebhookDelivery, error) {
+	const msg = "service.WebhookDeliveries"
+	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
+
+	list, err := s.webhookDB.ListWebhookDeliveries(ctx, userID, id, webhookDeliveriesLimit)
+	if errors.Is(err, ErrNoRow) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return list, nil
+}
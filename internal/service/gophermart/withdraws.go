@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"math"
 	"time"
-
-	"github.com/IvanOplesnin/gofermart.git/internal/handler"
 )
 
 type WithdrawerDB interface {
@@ -23,55 +21,48 @@ type Withdraw struct {
 	ProcessedAt time.Time
 }
 
-var ErrNotEnoughBalance = errors.New("not enough balance")
-var ErrWithdrawAlreadyProcessed = errors.New("withdraw already processed")
+// Withdrawal — списание в том виде, в каком его видит пользователь: сумма в баллах.
+type Withdrawal struct {
+	Order       string
+	Sum         float64
+	ProcessedAt time.Time
+}
 
-func (s *Service) Withdraw(ctx context.Context, orderNumber string, summa float64) error {
-	const msg = "service.Orders"
+func (s *Service) Withdraw(ctx context.Context, userID int32, orderNumber string, summa float64) error {
+	const msg = "service.Withdraw"
 	wrapError := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
 
-	if summa <= 0 {
-		return handler.ErrInvalidSumma
-	}
-	userID, err := handler.UserIDFromCtx(ctx)
-	if err != nil {
-		return wrapError(err)
+	// Сумма меньше копейки округляется до нуля, а больше MaxInt32 копеек
+	// переполнила бы int32 и превратила списание в начисление.
+	cents := toCents(summa)
+	if cents <= 0 || cents > math.MaxInt32 {
+		return ErrInvalidSum
 	}
-	err = s.withdrawDB.Withdraw(ctx, int32(userID), int32(math.Round(summa*100)), orderNumber)
+	err := s.withdrawDB.Withdraw(ctx, userID, int32(cents), orderNumber)
 	if err == nil {
 		return nil
 	}
-	if errors.Is(err, ErrNotEnoughBalance) {
-		return handler.ErrEnoughMoney
-	}
-	if errors.Is(err, ErrWithdrawAlreadyProcessed) {
-		return handler.ErrInvalidOrderNumber
+	if errors.Is(err, ErrNotEnoughBalance) || errors.Is(err, ErrWithdrawAlreadyProcessed) {
+		return err
 	}
 	return wrapError(err)
 }
 
-func (s *Service) ListWithdraws(ctx context.Context) ([]handler.Withdraw, error) {
+func (s *Service) ListWithdraws(ctx context.Context, userID int32) ([]Withdrawal, error) {
 	const msg = "service.ListWithdraws"
 	wrapErr := func(err error) error { return fmt.Errorf("%s: %w", msg, err) }
 
-	userID, err := handler.UserIDFromCtx(ctx)
+	withdraws, err := s.withdrawDB.ListWithdraws(ctx, userID)
 	if err != nil {
 		return nil, wrapErr(err)
 	}
-	withdraws, err := s.withdrawDB.ListWithdraws(ctx, int32(userID))
-	if err != nil {
-		return nil, wrapErr(err)
-	}
-	if len(withdraws) == 0 {
-		return []handler.Withdraw{}, nil
-	}
-	respWithdraws := make([]handler.Withdraw, 0, len(withdraws))
+	result := make([]Withdrawal, 0, len(withdraws))
 	for _, w := range withdraws {
-		respWithdraws = append(respWithdraws, handler.Withdraw{
-			OrderNumber: w.OrderNumber,
-			Summa:       float64(w.Summa) / 100,
-			ProcessedAt: handler.RFC3339Time(w.ProcessedAt),
+		result = append(result, Withdrawal{
+			Order:       w.OrderNumber,
+			Sum:         float64(w.Summa) / 100,
+			ProcessedAt: w.ProcessedAt,
 		})
 	}
-	return respWithdraws, nil
+	return result, nil
 }
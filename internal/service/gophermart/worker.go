@@ -3,11 +3,15 @@ package gophermart
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
 	"github.com/IvanOplesnin/gofermart.git/internal/logger"
 	"github.com/sirupsen/logrus"
 )
@@ -15,16 +19,31 @@ import (
 const (
 	pollingInterval = 10 * time.Second
 	limitRequest    = 10
+	syncInterval    = 120 * time.Second
+	rateLimitPause  = 60 * time.Second
 )
 
 var ErrToManyRequests = errors.New("too many requests")
 
+// ErrAccrualUnavailable — система начислений недоступна и запросы к ней временно не выполняются.
+var ErrAccrualUnavailable = errors.New("accrual service unavailable")
+
 type worker struct {
 	accrualClient GetAPIOrdered
 	checkerDB     ListUpdateApplyAccrual
+	accrualHealth AccrualHealth
+
+	pollInterval   time.Duration
+	batchSize      int32
+	syncInterval   time.Duration
+	rateLimitPause time.Duration
+	// maxOrderAge — через сколько неизвестный системе начислений заказ становится INVALID; 0 — никогда.
+	maxOrderAge time.Duration
 
 	rateLimitid atomic.Bool
 	cancelLoop  func()
+	// logCtx несёт логгер воркера; от него же порождается контекст цикла.
+	logCtx context.Context
 
 	startOnce sync.Once
 	stopOnce  sync.Once
@@ -37,7 +56,13 @@ func newWorker(client GetAPIOrdered, checker ListUpdateApplyAccrual) *worker {
 		accrualClient: client,
 		checkerDB:     checker,
 		chLimit:       make(chan struct{}, limitRequest),
-		rateLimitid:   atomic.Bool{},
+		logCtx:        logger.WithField(context.Background(), "component", "svc.worker"),
+
+		pollInterval:   pollingInterval,
+		batchSize:      limitRequest,
+		syncInterval:   syncInterval,
+		rateLimitPause: rateLimitPause,
+		rateLimitid:    atomic.Bool{},
 
 		startOnce: sync.Once{},
 		stopOnce:  sync.Once{},
@@ -45,14 +70,65 @@ func newWorker(client GetAPIOrdered, checker ListUpdateApplyAccrual) *worker {
 	}
 }
 
+// applyConfig переопределяет значения по умолчанию. Вызывается до Run.
+func (w *worker) applyConfig(cfg config.Worker) {
+	if cfg.PollInterval > 0 {
+		w.pollInterval = cfg.PollInterval
+	}
+	if cfg.BatchSize > 0 {
+		w.batchSize = cfg.BatchSize
+		w.chLimit = make(chan struct{}, cfg.BatchSize)
+	}
+	if cfg.SyncInterval > 0 {
+		w.syncInterval = cfg.SyncInterval
+	}
+	if cfg.RateLimitPause > 0 {
+		w.rateLimitPause = cfg.RateLimitPause
+	}
+	w.maxOrderAge = cfg.MaxOrderAge
+}
+
+// setAccrualHealth подключает circuit breaker клиента. Смена его состояния
+// логируется один раз, а не ошибкой на каждый заказ. Вызывается до Run.
+func (w *worker) setAccrualHealth(h AccrualHealth) {
+	w.accrualHealth = h
+	h.Subscribe(func(from breaker.State, to breaker.State) {
+		log := logger.FromContext(w.logCtx).WithField("from", from.String()).WithField("to", to.String())
+		switch to {
+		case breaker.Open:
+			log.Warn("accrual service unavailable, polling paused")
+		case breaker.HalfOpen:
+			log.Info("probing accrual service")
+		case breaker.Closed:
+			log.Info("accrual service recovered, polling resumed")
+		}
+	})
+}
+
+// enableCallbacks переводит опрос на редкий интервал: статусы приходят
+// уведомлениями, а опрос подбирает заказы, по которым уведомлений не было.
+// Вызывается до Run.
+func (w *worker) enableCallbacks(fallbackInterval time.Duration) {
+	if fallbackInterval > 0 {
+		w.syncInterval = fallbackInterval
+	}
+}
+
+func (w *worker) accrualState() breaker.State {
+	if w.accrualHealth == nil {
+		return breaker.Closed
+	}
+	return w.accrualHealth.State()
+}
+
 func (w *worker) Run() {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(w.logCtx)
 	w.cancelLoop = cancel
 	w.startOnce.Do(func() {
 		w.wg.Add(1)
 		go w.loop(ctx)
 	})
-	logger.Log.Info("run svc.worker")
+	logger.FromContext(w.logCtx).Info("run svc.worker")
 }
 
 func (w *worker) Stop() {
@@ -62,18 +138,18 @@ func (w *worker) Stop() {
 		})
 	}
 	w.wg.Wait()
-	logger.Log.Info("stop svc.worker")
+	logger.FromContext(w.logCtx).Info("stop svc.worker")
 }
 
 func (w *worker) loop(ctx context.Context) {
 	defer w.wg.Done()
 
-	ticker := time.NewTicker(pollingInterval)
+	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
 	for {
 		if w.rateLimitid.Load() {
-			sleepCh := time.NewTimer(60 * time.Second)
+			sleepCh := time.NewTimer(w.rateLimitPause)
 			select {
 			case <-ctx.Done():
 				sleepCh.Stop()
@@ -93,17 +169,30 @@ func (w *worker) loop(ctx context.Context) {
 }
 
 func (w *worker) checkAndUpdate(ctx context.Context) {
-	logger.Log.Debugf("svc.worker.CheckAndUpdate start")
+	ctx = logger.WithField(ctx, "batch_id", logger.NewID())
+	log := logger.FromContext(ctx)
+	log.Debugf("svc.worker.CheckAndUpdate start")
+
+	limit := w.batchSize
+	switch w.accrualState() {
+	case breaker.Open:
+		log.Debug("svc.worker.checkAndUpdate: accrual breaker is open, skip")
+		return
+	case breaker.HalfOpen:
+		// Пробуем сервис одним запросом, остальные заказы дождутся закрытия breaker.
+		limit = 1
+	}
+
 	now := time.Now()
-	orders, err := w.checkerDB.ListPending(ctx, limitRequest, []string{"NEW", "PROCESSING"}, now)
+	orders, err := w.checkerDB.ListPending(ctx, limit, pendingStatuses, now)
 	if err != nil {
-		logger.Log.Errorf("svc.worker.checkAndUpdate: %v", err.Error())
+		log.Errorf("svc.worker.checkAndUpdate: %v", err.Error())
 		return
 	}
 	if len(orders) == 0 {
 		return
 	}
-	logger.Log.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"count_orders": len(orders),
 		"orders":       listOrdersString(orders),
 	}).Infof("worker.checkAndUpdate run")
@@ -119,48 +208,99 @@ Loop:
 			break Loop
 		case w.chLimit <- struct{}{}:
 		}
+		// Слот мог освободиться уже после отмены пачки.
+		if ctxBatch.Err() != nil {
+			<-w.chLimit
+			break Loop
+		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer func() { <-w.chLimit }()
-			logger.Log.Debugf("Request order: %s", o.Number)
-			responseAccrual, err := w.accrualClient.GetOrder(ctxBatch, o.Number)
+			ctx := logger.WithField(ctx, "order", o.Number)
+			log := logger.FromContext(ctx)
+			log.Debugf("Request order: %s", o.Number)
+			responseAccrual, err := w.accrualClient.GetOrder(logger.WithEntry(ctxBatch, log), o.Number)
 			if errors.Is(err, ErrToManyRequests) {
-				logger.Log.Warnf("too many requests to accrual service: code 429")
+				log.Warnf("too many requests to accrual service: code 429")
 				w.rateLimitid.Store(true)
 				once.Do(cancel)
 				return
 			}
-			logger.Log.Debugf("Response order: %s - Status %s", o.Number, responseAccrual.Status)
+			if errors.Is(err, ErrAccrualUnavailable) {
+				// Недоступность уже залогирована при размыкании breaker.
+				log.Debugf("svc.worker.checkAndUpdate: %s", err.Error())
+				once.Do(cancel)
+				return
+			}
 			if err != nil {
-				logger.Log.Errorf("svc.worker.checkAndUpdate: %s", err.Error())
+				log.Errorf("svc.worker.checkAndUpdate: %s", err.Error())
 				return
 			}
 			if responseAccrual == nil {
-				logger.Log.Error("svc.worker.checkAndUpdate: responseAccrual == nil ")
+				log.Error("svc.worker.checkAndUpdate: responseAccrual == nil ")
 				return
 			}
-			if responseAccrual.Status != o.OrderStatus {
-				if responseAccrual.Status == "PROCESSED" {
-					if err := w.checkerDB.ApplyAccrual(ctx, o.Number, int64(responseAccrual.Accrual*100), o.UserID); err != nil {
-						logger.Log.Errorf("svc.worker.checkAndUpdate: %s", err.Error())
-						return
-					}
-				} else if err := w.checkerDB.UpdateFromAccrual(ctx, o.Number, responseAccrual.Status, now.Add(time.Second*120)); err != nil {
-					logger.Log.Errorf("svc.worker.checkAndUpdate: %s", err.Error())
-					return
-				}
-			} else {
-				if err := w.checkerDB.UpdateSyncTime(ctx, o.Number, now.Add(time.Second*120)); err != nil {
-					logger.Log.Errorf("svc.worker.checkAndUpdate: %s", err.Error())
-					return
-				}
+			log.Debugf("Response order: %s - Status %s", o.Number, responseAccrual.Status)
+			if err := w.applyAccrualStatus(ctx, o, responseAccrual, now); err != nil {
+				log.Errorf("svc.worker.checkAndUpdate: %s", err.Error())
 			}
 		}()
 	}
 	wg.Wait()
 }
 
+// applyAccrualStatus применяет ответ системы начислений к заказу по правилам
+// переходов статусов. Недопустимый переход не записывается, заказ опрашивается снова позже.
+func (w *worker) applyAccrualStatus(ctx context.Context, o Order, resp *AccrualResponse, now time.Time) error {
+	log := logger.FromContext(ctx)
+
+	if resp.Status == o.OrderStatus {
+		if resp.Unregistered && w.maxOrderAge > 0 && now.Sub(o.UploadedAt) > w.maxOrderAge {
+			log.Warnf("order is unknown to accrual service for more than %s, mark %s", w.maxOrderAge, StatusInvalid)
+			return w.checkerDB.MarkInvalid(ctx, o.Number)
+		}
+		return w.checkerDB.UpdateSyncTime(ctx, o.Number, now.Add(w.syncInterval))
+	}
+
+	if err := CheckTransition(o.OrderStatus, resp.Status); err != nil {
+		log.Warnf("svc.worker: accrual status rejected: %v", err)
+		return w.checkerDB.UpdateSyncTime(ctx, o.Number, now.Add(w.syncInterval))
+	}
+	switch resp.Status {
+	case StatusProcessed:
+		// Как и при списании: больше MaxInt32 копеек переполнило бы баланс в базе.
+		// Заказ не записывается и опрашивается снова позже.
+		cents := toCents(resp.Accrual)
+		if cents < 0 || cents > math.MaxInt32 {
+			if err := w.checkerDB.UpdateSyncTime(ctx, o.Number, now.Add(w.syncInterval)); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: accrual %v", ErrInvalidSum, resp.Accrual)
+		}
+		return w.checkerDB.ApplyAccrual(ctx, o.Number, cents, o.UserID)
+	case StatusInvalid:
+		return w.checkerDB.MarkInvalid(ctx, o.Number)
+	default:
+		return w.checkerDB.UpdateFromAccrual(ctx, o.Number, resp.Status, now.Add(w.syncInterval))
+	}
+}
+
+// applyCallback применяет статус, присланный системой начислений, тем же путём,
+// что и ответ на опрос. Повторная доставка уже применённого терминального статуса
+// ничего не меняет.
+func (w *worker) applyCallback(ctx context.Context, resp *AccrualResponse) error {
+	o, err := w.checkerDB.OrderByNumber(ctx, resp.OrderNumber)
+	if err != nil {
+		return err
+	}
+	if IsTerminal(o.OrderStatus) && o.OrderStatus == resp.Status {
+		logger.FromContext(ctx).Debugf("svc.worker.applyCallback: order already %s", o.OrderStatus)
+		return nil
+	}
+	return w.applyAccrualStatus(ctx, o, resp, time.Now())
+}
+
 func listOrdersString(orders []Order) string {
 	if len(orders) == 0 {
 		return "[]"
@@ -3,9 +3,11 @@ package gophermart
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/IvanOplesnin/gofermart.git/internal/breaker"
 	"go.uber.org/mock/gomock"
 )
 
@@ -91,6 +93,34 @@ func TestWorker_checkAndUpdate_StatusChanged_Processed_ApplyAccrual(t *testing.T
 	w.checkAndUpdate(context.Background())
 }
 
+// 0.29 в float64 — это 0.28999..., без округления начислилось бы 28 копеек.
+func TestWorker_checkAndUpdate_Processed_RoundsAccrualToCents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := NewMockGetAPIOrdered(ctrl)
+	db := NewMockListUpdateApplyAccrual(ctrl)
+
+	w := newWorker(client, db)
+
+	db.EXPECT().
+		ListPending(gomock.Any(), int32(limitRequest), []string{"NEW", "PROCESSING"}, gomock.Any()).
+		Return([]Order{{Number: "123", OrderStatus: "NEW", UserID: 7}}, nil).
+		Times(1)
+
+	client.EXPECT().
+		GetOrder(gomock.Any(), "123").
+		Return(&AccrualResponse{OrderNumber: "123", Status: "PROCESSED", Accrual: 0.29}, nil).
+		Times(1)
+
+	db.EXPECT().
+		ApplyAccrual(gomock.Any(), "123", int64(29), int32(7)).
+		Return(nil).
+		Times(1)
+
+	w.checkAndUpdate(context.Background())
+}
+
 func TestWorker_checkAndUpdate_StatusChanged_NotProcessed_UpdateFromAccrual(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -115,11 +145,11 @@ func TestWorker_checkAndUpdate_StatusChanged_NotProcessed_UpdateFromAccrual(t *t
 
 	client.EXPECT().
 		GetOrder(gomock.Any(), "555").
-		Return(&AccrualResponse{OrderNumber: "555", Status: "INVALID", Accrual: 0}, nil).
+		Return(&AccrualResponse{OrderNumber: "555", Status: "PROCESSING", Accrual: 0}, nil).
 		Times(1)
 
 	db.EXPECT().
-		UpdateFromAccrual(gomock.Any(), "555", "INVALID", gomock.Any()).
+		UpdateFromAccrual(gomock.Any(), "555", "PROCESSING", gomock.Any()).
 		DoAndReturn(func(_ context.Context, _ string, _ string, nextSync time.Time) error {
 			if time.Until(nextSync) < 90*time.Second {
 				t.Fatalf("expected nextSync about now+120s, got %v", nextSync)
@@ -130,6 +160,7 @@ func TestWorker_checkAndUpdate_StatusChanged_NotProcessed_UpdateFromAccrual(t *t
 
 	db.EXPECT().ApplyAccrual(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 	db.EXPECT().UpdateSyncTime(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	db.EXPECT().MarkInvalid(gomock.Any(), gomock.Any()).Times(0)
 
 	w.checkAndUpdate(context.Background())
 }
@@ -249,3 +280,239 @@ func TestWorker_checkAndUpdate_GetOrderError_NoDbUpdates(t *testing.T) {
 
 	w.checkAndUpdate(context.Background())
 }
+
+func TestWorker_checkAndUpdate_BreakerOpen_SkipsTick(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := NewMockGetAPIOrdered(ctrl)
+	db := NewMockListUpdateApplyAccrual(ctrl)
+	health := NewMockAccrualHealth(ctrl)
+
+	health.EXPECT().Subscribe(gomock.Any()).Times(1)
+	health.EXPECT().State().Return(breaker.Open).Times(1)
+
+	w := newWorker(client, db)
+	w.setAccrualHealth(health)
+
+	db.EXPECT().ListPending(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	client.EXPECT().GetOrder(gomock.Any(), gomock.Any()).Times(0)
+
+	w.checkAndUpdate(context.Background())
+}
+
+func TestWorker_checkAndUpdate_BreakerHalfOpen_SingleProbe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := NewMockGetAPIOrdered(ctrl)
+	db := NewMockListUpdateApplyAccrual(ctrl)
+	health := NewMockAccrualHealth(ctrl)
+
+	health.EXPECT().Subscribe(gomock.Any()).Times(1)
+	health.EXPECT().State().Return(breaker.HalfOpen).Times(1)
+
+	w := newWorker(client, db)
+	w.setAccrualHealth(health)
+
+	db.EXPECT().
+		ListPending(gomock.Any(), int32(1), []string{"NEW", "PROCESSING"}, gomock.Any()).
+		Return([]Order{{Number: "1001", OrderStatus: "NEW", UserID: 5}}, nil).
+		Times(1)
+	client.EXPECT().
+		GetOrder(gomock.Any(), "1001").
+		Return(&AccrualResponse{OrderNumber: "1001", Status: "NEW"}, nil).
+		Times(1)
+	db.EXPECT().UpdateSyncTime(gomock.Any(), "1001", gomock.Any()).Return(nil).Times(1)
+
+	w.checkAndUpdate(context.Background())
+}
+
+func TestWorker_checkAndUpdate_AccrualUnavailable_StopsBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := NewMockGetAPIOrdered(ctrl)
+	db := NewMockListUpdateApplyAccrual(ctrl)
+
+	w := newWorker(client, db)
+	w.chLimit = make(chan struct{}, 1)
+
+	db.EXPECT().
+		ListPending(gomock.Any(), int32(limitRequest), []string{"NEW", "PROCESSING"}, gomock.Any()).
+		Return([]Order{{Number: "1", OrderStatus: "NEW"}, {Number: "2", OrderStatus: "NEW"}}, nil).
+		Times(1)
+	client.EXPECT().
+		GetOrder(gomock.Any(), "1").
+		Return(nil, ErrAccrualUnavailable).
+		Times(1)
+	client.EXPECT().GetOrder(gomock.Any(), "2").Times(0)
+
+	db.EXPECT().ApplyAccrual(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	db.EXPECT().UpdateFromAccrual(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	db.EXPECT().UpdateSyncTime(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	w.checkAndUpdate(context.Background())
+}
+
+func TestWorker_applyAccrualStatus(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		order   Order
+		resp    AccrualResponse
+		expect  func(db *MockListUpdateApplyAccrualMockRecorder)
+		wantErr error
+	}{
+		{
+			name:  "invalid is terminal",
+			order: Order{Number: "1", OrderStatus: StatusProcessing, UploadedAt: now},
+			resp:  AccrualResponse{Status: StatusInvalid},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.MarkInvalid(gomock.Any(), "1").Return(nil)
+			},
+		},
+		{
+			name:  "illegal transition is not written",
+			order: Order{Number: "2", OrderStatus: StatusProcessing, UploadedAt: now},
+			resp:  AccrualResponse{Status: StatusNew},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.UpdateSyncTime(gomock.Any(), "2", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "unregistered order within max age keeps polling",
+			order: Order{Number: "3", OrderStatus: StatusNew, UploadedAt: now.Add(-time.Hour)},
+			resp:  AccrualResponse{Status: StatusNew, Unregistered: true},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.UpdateSyncTime(gomock.Any(), "3", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "unregistered order older than max age becomes invalid",
+			order: Order{Number: "4", OrderStatus: StatusNew, UploadedAt: now.Add(-25 * time.Hour)},
+			resp:  AccrualResponse{Status: StatusNew, Unregistered: true},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.MarkInvalid(gomock.Any(), "4").Return(nil)
+			},
+		},
+		{
+			name:  "registered old order keeps polling",
+			order: Order{Number: "5", OrderStatus: StatusNew, UploadedAt: now.Add(-25 * time.Hour)},
+			resp:  AccrualResponse{Status: StatusNew},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.UpdateSyncTime(gomock.Any(), "5", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "negative accrual is not written",
+			order: Order{Number: "6", OrderStatus: StatusProcessing, UploadedAt: now},
+			resp:  AccrualResponse{Status: StatusProcessed, Accrual: -1},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.UpdateSyncTime(gomock.Any(), "6", gomock.Any()).Return(nil)
+			},
+			wantErr: ErrInvalidSum,
+		},
+		{
+			name:  "accrual over int32 cents is not written",
+			order: Order{Number: "7", OrderStatus: StatusProcessing, UploadedAt: now},
+			resp:  AccrualResponse{Status: StatusProcessed, Accrual: float64(math.MaxInt32)/100 + 0.01},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.UpdateSyncTime(gomock.Any(), "7", gomock.Any()).Return(nil)
+			},
+			wantErr: ErrInvalidSum,
+		},
+		{
+			name:  "accrual of exactly int32 cents is applied",
+			order: Order{UserID: 7, Number: "8", OrderStatus: StatusProcessing, UploadedAt: now},
+			resp:  AccrualResponse{Status: StatusProcessed, Accrual: float64(math.MaxInt32) / 100},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.ApplyAccrual(gomock.Any(), "8", int64(math.MaxInt32), int32(7)).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := NewMockListUpdateApplyAccrual(ctrl)
+			w := newWorker(NewMockGetAPIOrdered(ctrl), db)
+			w.maxOrderAge = 24 * time.Hour
+			tt.expect(db.EXPECT())
+
+			err := w.applyAccrualStatus(context.Background(), tt.order, &tt.resp, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("applyAccrualStatus: err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyAccrualStatus: %v", err)
+			}
+		})
+	}
+}
+
+func TestWorker_applyCallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   Order
+		resp    AccrualResponse
+		expect  func(db *MockListUpdateApplyAccrualMockRecorder)
+		wantErr error
+	}{
+		{
+			name:  "processed applies accrual",
+			order: Order{UserID: 7, Number: "1", OrderStatus: StatusProcessing},
+			resp:  AccrualResponse{OrderNumber: "1", Status: StatusProcessed, Accrual: 12.5},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.ApplyAccrual(gomock.Any(), "1", int64(1250), int32(7)).Return(nil)
+			},
+		},
+		{
+			name:   "repeated terminal status is a no-op",
+			order:  Order{UserID: 7, Number: "2", OrderStatus: StatusProcessed},
+			resp:   AccrualResponse{OrderNumber: "2", Status: StatusProcessed, Accrual: 12.5},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {},
+		},
+		{
+			name:  "accrual over int32 cents is rejected",
+			order: Order{UserID: 7, Number: "4", OrderStatus: StatusProcessing},
+			resp:  AccrualResponse{OrderNumber: "4", Status: StatusProcessed, Accrual: 1e12},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.UpdateSyncTime(gomock.Any(), "4", gomock.Any()).Return(nil)
+			},
+			wantErr: ErrInvalidSum,
+		},
+		{
+			name:  "processing keeps fallback polling",
+			order: Order{UserID: 7, Number: "3", OrderStatus: StatusNew},
+			resp:  AccrualResponse{OrderNumber: "3", Status: StatusProcessing},
+			expect: func(db *MockListUpdateApplyAccrualMockRecorder) {
+				db.UpdateFromAccrual(gomock.Any(), "3", StatusProcessing, gomock.Any()).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := NewMockListUpdateApplyAccrual(ctrl)
+			w := newWorker(NewMockGetAPIOrdered(ctrl), db)
+			db.EXPECT().OrderByNumber(gomock.Any(), tt.order.Number).Return(tt.order, nil)
+			tt.expect(db.EXPECT())
+
+			if err := w.applyCallback(context.Background(), &tt.resp); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("applyCallback: err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWorker_enableCallbacks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	w := newWorker(NewMockGetAPIOrdered(ctrl), NewMockListUpdateApplyAccrual(ctrl))
+	w.enableCallbacks(30 * time.Minute)
+	if w.syncInterval != 30*time.Minute {
+		t.Fatalf("syncInterval = %s, want 30m", w.syncInterval)
+	}
+}
@@ -0,0 +1,62 @@
+// Package service описывает методы gophermart.Service, которыми пользуются
+// транспорты (HTTP и gRPC), и передачу пользователя запроса через контекст.
+package service
+
+import (
+	"context"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+type Registrar interface {
+	Register(ctx context.Context, login string, password string) (string, error)
+}
+
+type Auther interface {
+	Auth(ctx context.Context, login string, password string) (string, error)
+}
+
+// TokenChecker возвращает ID владельца токена или gophermart.ErrInvalidToken.
+type TokenChecker interface {
+	CheckToken(ctx context.Context, token string) (userID int32, err error)
+}
+
+type Ordered interface {
+	AddOrder(ctx context.Context, userID int32, orderID string) (exist bool, err error)
+	Orders(ctx context.Context, userID int32) ([]gophermart.UserOrder, error)
+}
+
+type Balancer interface {
+	Balance(ctx context.Context, userID int32) (gophermart.UserBalance, error)
+}
+
+type Withdrawer interface {
+	Withdraw(ctx context.Context, userID int32, orderNumber string, summa float64) error
+	ListWithdraws(ctx context.Context, userID int32) ([]gophermart.Withdrawal, error)
+}
+
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, userID int32, url string, secret string) (gophermart.Webhook, error)
+	Webhooks(ctx context.Context, userID int32) ([]gophermart.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int32, id int32) error
+	WebhookDeliveries(ctx context.Context, userID int32, id int32) ([]gophermart.WebhookDelivery, error)
+}
+
+// EventStreamer отдаёт события outbox пользователя; ID события служит Last-Event-ID.
+type EventStreamer interface {
+	SubscribeEvents(ctx context.Context, userID int32, lastEventID int64) (<-chan outbox.Event, error)
+}
+
+type userIDKey struct{}
+
+// WithUserID кладёт в контекст пользователя, чей токен проверил транспорт.
+func WithUserID(ctx context.Context, userID int32) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserID возвращает пользователя, положенного WithUserID.
+func UserID(ctx context.Context) (int32, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int32)
+	return userID, ok
+}
@@ -0,0 +1,27 @@
+// Package signature подписывает тела HTTP-запросов HMAC-SHA256 общим секретом.
+// Подпись передаётся в заголовке X-Signature в виде "sha256=<hex>".
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	Header = "X-Signature"
+	prefix = "sha256="
+)
+
+// Sign возвращает значение заголовка X-Signature для тела body.
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Valid сравнивает подпись с ожидаемой за постоянное время.
+func Valid(secret []byte, body []byte, sig string) bool {
+	return strings.HasPrefix(sig, prefix) && hmac.Equal([]byte(sig), []byte(Sign(secret, body)))
+}
@@ -0,0 +1,31 @@
+package signature
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	secret := []byte("shared")
+	body := []byte(`{"order":"79927398713"}`)
+	sig := Sign(secret, body)
+
+	tests := []struct {
+		name string
+		sig  string
+		want bool
+	}{
+		{name: "valid", sig: sig, want: true},
+		{name: "empty", sig: "", want: false},
+		{name: "no prefix", sig: strings.TrimPrefix(sig, prefix), want: false},
+		{name: "other secret", sig: Sign([]byte("other"), body), want: false},
+		{name: "other body", sig: Sign(secret, []byte("{}")), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(secret, body, tt.sig); got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
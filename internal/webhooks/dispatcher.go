@@ -0,0 +1,272 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/config"
+	"github.com/IvanOplesnin/gofermart.git/internal/logger"
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+	"github.com/IvanOplesnin/gofermart.git/internal/signature"
+)
+
+const maxErrorLength = 512
+
+var ErrForbiddenTarget = errors.New("webhook target address is not allowed")
+
+type Options struct {
+	PollInterval time.Duration
+	BatchSize    int32
+	Concurrency  int
+	Timeout      time.Duration
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	MaxAttempts  int32
+	// AllowPrivateTargets разрешает адреса loopback и частных сетей. Без него
+	// пользователь не может направить запросы сервиса во внутреннюю сеть.
+	AllowPrivateTargets bool
+}
+
+func OptionsFromConfig(cfg config.Webhooks) Options {
+	return Options{
+		PollInterval:        cfg.PollInterval,
+		BatchSize:           cfg.BatchSize,
+		Concurrency:         cfg.Concurrency,
+		Timeout:             cfg.Timeout,
+		RetryBackoff:        cfg.RetryBackoff,
+		MaxBackoff:          cfg.MaxBackoff,
+		MaxAttempts:         cfg.MaxAttempts,
+		AllowPrivateTargets: cfg.AllowPrivateTargets,
+	}
+}
+
+// Dispatcher периодически забирает доставки и отправляет их параллельно,
+// не более Concurrency запросов одновременно.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	opts   Options
+
+	cancel   func()
+	wg       sync.WaitGroup
+	runOnce  sync.Once
+	stopOnce sync.Once
+
+	now func() time.Time
+}
+
+func NewDispatcher(store Store, opts Options) *Dispatcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 5 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Hour
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	return &Dispatcher{
+		store:  store,
+		client: newClient(opts),
+		opts:   opts,
+		now:    time.Now,
+	}
+}
+
+// newClient не следует редиректам и, если не разрешено иное, не соединяется
+// с внутренними адресами. Проверяется уже разрешённый IP, поэтому подмена DNS
+// после регистрации вебхука не помогает.
+func newClient(opts Options) *http.Client {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivateTargets {
+		dialer.Control = func(_ string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast())
+}
+
+func (d *Dispatcher) Run() {
+	d.runOnce.Do(func() {
+		ctx, cancel := context.WithCancel(logger.WithField(context.Background(), "component", "webhooks.dispatcher"))
+		d.cancel = cancel
+		d.wg.Add(1)
+		go d.loop(ctx)
+	})
+	logger.Log.Info("run webhooks.dispatcher")
+}
+
+// Stop прерывает текущие запросы. Их доставки останутся в очереди и будут
+// повторены после истечения аренды.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		if d.cancel != nil {
+			d.cancel()
+		}
+	})
+	d.wg.Wait()
+	logger.Log.Info("stop webhooks.dispatcher")
+}
+
+func (d *Dispatcher) loop(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatch(ctx)
+		}
+	}
+}
+
+// dispatch отправляет одну пачку доставок.
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	now := d.now()
+	// Аренда покрывает запрос с таймаутом и сохранение результата.
+	deliveries, err := d.store.LeaseWebhookDeliveries(ctx, d.opts.BatchSize, now, now.Add(2*d.opts.Timeout))
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.FromContext(ctx).Errorf("webhooks.dispatch: %s", err.Error())
+		}
+		return
+	}
+
+	sem := make(chan struct{}, d.opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, dl := range deliveries {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := d.deliver(ctx, dl)
+			if ctx.Err() != nil {
+				return
+			}
+			if err := d.store.SaveWebhookResult(ctx, res); err != nil {
+				logger.FromContext(ctx).Errorf("webhooks.dispatch: %s", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver выполняет одну попытку и решает, повторять ли её.
+func (d *Dispatcher) deliver(ctx context.Context, dl Delivery) Result {
+	code, err := d.send(ctx, dl)
+	if err == nil {
+		return Result{ID: dl.ID, Status: StatusDelivered, StatusCode: code}
+	}
+	log := logger.FromContext(ctx).WithField("delivery_id", dl.ID).WithField("webhook_id", dl.WebhookID)
+	res := Result{ID: dl.ID, StatusCode: code, Err: err}
+	attempts := dl.Attempts + 1
+	if attempts >= d.opts.MaxAttempts || errors.Is(err, ErrForbiddenTarget) {
+		log.Warnf("webhooks: delivery failed permanently after %d attempts: %s", attempts, err.Error())
+		res.Status = StatusFailed
+		res.RetryAt = d.now()
+		return res
+	}
+	log.Debugf("webhooks: attempt %d failed: %s", attempts, err.Error())
+	res.Status = StatusPending
+	res.RetryAt = d.now().Add(d.backoff(dl.Attempts))
+	return res
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl Delivery) (int, error) {
+	body, err := json.Marshal(Payload{ID: dl.EventID, Type: dl.EventType, CreatedAt: dl.CreatedAt, Data: dl.Payload})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signature.Header, signature.Sign([]byte(dl.Secret), body))
+	req.Header.Set(outbox.EventIDHeader, strconv.FormatInt(dl.EventID, 10))
+	req.Header.Set(outbox.EventTypeHeader, dl.EventType)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoff(attempts int32) time.Duration {
+	b := d.opts.RetryBackoff
+	for i := int32(0); i < attempts && b < d.opts.MaxBackoff; i++ {
+		b *= 2
+	}
+	return min(b, d.opts.MaxBackoff)
+}
+
+// ErrorText обрезает текст ошибки для журнала доставок.
+func ErrorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	s := err.Error()
+	if len(s) > maxErrorLength {
+		s = s[:maxErrorLength]
+	}
+	return s
+}
@@ -0,0 +1,184 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+	"github.com/IvanOplesnin/gofermart.git/internal/signature"
+)
+
+type memStore struct {
+	mu       sync.Mutex
+	enqueued []outbox.Event
+	leased   []Delivery
+	results  []Result
+}
+
+func (s *memStore) EnqueueWebhookDeliveries(_ context.Context, e outbox.Event) (int64, error) {
+	s.enqueued = append(s.enqueued, e)
+	return 1, nil
+}
+
+func (s *memStore) LeaseWebhookDeliveries(_ context.Context, limit int32, _ time.Time, _ time.Time) ([]Delivery, error) {
+	n := min(int(limit), len(s.leased))
+	batch := s.leased[:n]
+	s.leased = s.leased[n:]
+	return batch, nil
+}
+
+func (s *memStore) SaveWebhookResult(_ context.Context, r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, r)
+	return nil
+}
+
+func TestFanoutSink_FiltersTypes(t *testing.T) {
+	store := &memStore{}
+	sink := NewFanoutSink(store)
+	for _, typ := range []string{outbox.TypeOrderProcessed, outbox.TypeBalanceCredited, outbox.TypeWithdrawalCreated} {
+		if err := sink.Publish(context.Background(), outbox.Event{ID: 1, Type: typ}); err != nil {
+			t.Fatalf("Publish(%s): %v", typ, err)
+		}
+	}
+	if len(store.enqueued) != 2 {
+		t.Fatalf("enqueued %d events, want 2", len(store.enqueued))
+	}
+	for _, e := range store.enqueued {
+		if e.Type == outbox.TypeBalanceCredited {
+			t.Fatalf("%s must not be sent to webhooks", e.Type)
+		}
+	}
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	const secret = "0123456789abcdef"
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int32
+		wantStatus string
+		wantRetry  time.Time
+	}{
+		{name: "2xx -> delivered", status: http.StatusNoContent, wantStatus: StatusDelivered},
+		{name: "5xx -> retry with backoff", status: http.StatusBadGateway, attempts: 2, wantStatus: StatusPending, wantRetry: now.Add(4 * time.Second)},
+		{name: "backoff is capped", status: http.StatusBadGateway, attempts: 8, wantStatus: StatusPending, wantRetry: now.Add(time.Minute)},
+		{name: "last attempt -> failed", status: http.StatusBadGateway, attempts: 9, wantStatus: StatusFailed, wantRetry: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if !signature.Valid([]byte(secret), body, r.Header.Get(signature.Header)) {
+					t.Errorf("invalid signature %q", r.Header.Get(signature.Header))
+				}
+				if got := r.Header.Get(outbox.EventIDHeader); got != "42" {
+					t.Errorf("%s = %q, want 42", outbox.EventIDHeader, got)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			d := NewDispatcher(&memStore{}, Options{
+				RetryBackoff:        time.Second,
+				MaxBackoff:          time.Minute,
+				MaxAttempts:         10,
+				AllowPrivateTargets: true,
+			})
+			d.now = func() time.Time { return now }
+
+			res := d.deliver(context.Background(), Delivery{
+				ID:        7,
+				URL:       srv.URL,
+				Secret:    secret,
+				EventID:   42,
+				EventType: outbox.TypeOrderProcessed,
+				Payload:   []byte(`{"order":"1"}`),
+				Attempts:  tt.attempts,
+			})
+			if res.ID != 7 || res.Status != tt.wantStatus || res.StatusCode != tt.status {
+				t.Fatalf("result = %+v, want status %s code %d", res, tt.wantStatus, tt.status)
+			}
+			if !res.RetryAt.Equal(tt.wantRetry) {
+				t.Fatalf("RetryAt = %v, want %v", res.RetryAt, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestDispatcher_ForbidsPrivateTargets(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(&memStore{}, Options{})
+	res := d.deliver(context.Background(), Delivery{ID: 1, URL: srv.URL, Secret: "s"})
+	if called {
+		t.Fatal("request reached a loopback target")
+	}
+	if res.Status != StatusFailed || !errors.Is(res.Err, ErrForbiddenTarget) {
+		t.Fatalf("result = %+v, want failed with ErrForbiddenTarget", res)
+	}
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("redirect was followed")
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(&memStore{}, Options{AllowPrivateTargets: true})
+	res := d.deliver(context.Background(), Delivery{ID: 1, URL: srv.URL, Secret: "s"})
+	if res.Status != StatusPending || res.StatusCode != http.StatusFound {
+		t.Fatalf("result = %+v, want pending with 302", res)
+	}
+}
+
+func TestDispatcher_DispatchSavesResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	store := &memStore{}
+	for i := int64(1); i <= 5; i++ {
+		store.leased = append(store.leased, Delivery{ID: i, URL: srv.URL, Secret: "s"})
+	}
+	d := NewDispatcher(store, Options{BatchSize: 3, Concurrency: 2, AllowPrivateTargets: true})
+	d.dispatch(context.Background())
+
+	if len(store.results) != 3 {
+		t.Fatalf("saved %d results, want 3", len(store.results))
+	}
+	for _, r := range store.results {
+		if r.Status != StatusDelivered {
+			t.Fatalf("result = %+v, want delivered", r)
+		}
+	}
+}
+
+func TestErrorText(t *testing.T) {
+	long := make([]byte, maxErrorLength+10)
+	for i := range long {
+		long[i] = 'x'
+	}
+	if got := ErrorText(errors.New(string(long))); len(got) != maxErrorLength {
+		t.Fatalf("len = %d, want %d", len(got), maxErrorLength)
+	}
+	if got := ErrorText(nil); got != "" {
+		t.Fatalf("ErrorText(nil) = %q", got)
+	}
+}
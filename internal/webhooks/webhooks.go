@@ -0,0 +1,85 @@
+// Package webhooks доставляет события outbox на адреса, зарегистрированные
+// пользователями. FanoutSink раскладывает событие по вебхукам владельца,
+// Dispatcher отправляет подписанные запросы с повторами и ведёт журнал доставок.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/outbox"
+)
+
+// Статусы доставки.
+const (
+	StatusPending   = "PENDING"
+	StatusDelivered = "DELIVERED"
+	StatusFailed    = "FAILED"
+)
+
+// Types — события, о которых сообщается пользовательским вебхукам.
+var Types = []string{outbox.TypeOrderProcessed, outbox.TypeOrderInvalid, outbox.TypeWithdrawalCreated}
+
+// Delivery — одна доставка события на один вебхук.
+type Delivery struct {
+	ID        int64
+	WebhookID int32
+	URL       string
+	Secret    string
+	EventID   int64
+	EventType string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	// Attempts — число уже сделанных попыток.
+	Attempts int32
+}
+
+// Result — итог попытки. Status — StatusDelivered, StatusPending (будет
+// повтор в RetryAt) или StatusFailed (попытки исчерпаны).
+type Result struct {
+	ID         int64
+	Status     string
+	StatusCode int
+	Err        error
+	RetryAt    time.Time
+}
+
+type Store interface {
+	// EnqueueWebhookDeliveries создаёт доставки события для всех вебхуков его
+	// владельца. Повторный вызов для того же события ничего не добавляет.
+	EnqueueWebhookDeliveries(ctx context.Context, e outbox.Event) (int64, error)
+	// LeaseWebhookDeliveries выдаёт до limit доставок, готовых на момент now, и
+	// откладывает их до leaseUntil: если экземпляр упадёт, доставку подхватит другой.
+	LeaseWebhookDeliveries(ctx context.Context, limit int32, now time.Time, leaseUntil time.Time) ([]Delivery, error)
+	SaveWebhookResult(ctx context.Context, r Result) error
+}
+
+// FanoutSink — outbox.Sink, который ставит событие в очередь доставки вебхукам.
+type FanoutSink struct {
+	store Store
+}
+
+func NewFanoutSink(store Store) *FanoutSink {
+	return &FanoutSink{store: store}
+}
+
+func (s *FanoutSink) Publish(ctx context.Context, e outbox.Event) error {
+	if !slices.Contains(Types, e.Type) {
+		return nil
+	}
+	if _, err := s.store.EnqueueWebhookDeliveries(ctx, e); err != nil {
+		return fmt.Errorf("webhooks.FanoutSink: %w", err)
+	}
+	return nil
+}
+
+// Payload — тело запроса к вебхуку.
+type Payload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
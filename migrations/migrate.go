@@ -1,11 +1,15 @@
 package migrate
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
 )
 
 // Вшиваем миграции в бинарь.
@@ -13,24 +17,122 @@ import (
 //go:embed schema/*.sql
 var migrationsFS embed.FS
 
+// Dir — каталог миграций относительно корня репозитория, в нём создаются новые файлы.
+const Dir = "migrations/schema"
+
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+// Migrator применяет вшитые миграции. Все изменяющие операции выполняются
+// под advisory lock Postgres, поэтому параллельно стартующие экземпляры
+// не мешают друг другу.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func New(db *sql.DB) (*Migrator, error) {
+	schema, err := fs.Sub(migrationsFS, "schema")
+	if err != nil {
+		return nil, fmt.Errorf("migrate.New: %w", err)
+	}
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("migrate.New: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, schema, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("migrate.New: %w", err)
+	}
+	return &Migrator{provider: provider}, nil
+}
+
 // Up применяет все миграции вверх.
-func Up(db *sql.DB) error {
-	goose.SetDialect("postgres")
-	goose.SetBaseFS(migrationsFS)
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	res, err := m.provider.Up(ctx)
+	if err != nil {
+		return res, fmt.Errorf("migrate up: %w", err)
+	}
+	return res, nil
+}
+
+// Down откатывает одну последнюю миграцию.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	res, err := m.provider.Down(ctx)
+	if err != nil {
+		return res, fmt.Errorf("migrate down: %w", err)
+	}
+	return res, nil
+}
 
-	if err := goose.Up(db, "schema"); err != nil {
-		return fmt.Errorf("goose up: %w", err)
+// Redo откатывает последнюю миграцию и применяет её заново.
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	down, err := m.provider.Down(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate redo: %w", err)
+	}
+	up, err := m.provider.UpByOne(ctx)
+	if err != nil {
+		return []*goose.MigrationResult{down}, fmt.Errorf("migrate redo: %w", err)
+	}
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// To приводит схему к версии version, двигаясь вверх или вниз.
+func (m *Migrator) To(ctx context.Context, version int64) ([]*goose.MigrationResult, error) {
+	current, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate to %d: %w", version, err)
+	}
+	var res []*goose.MigrationResult
+	if version >= current {
+		res, err = m.provider.UpTo(ctx, version)
+	} else {
+		res, err = m.provider.DownTo(ctx, version)
+	}
+	if err != nil {
+		return res, fmt.Errorf("migrate to %d: %w", version, err)
+	}
+	return res, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	st, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate status: %w", err)
+	}
+	return st, nil
+}
+
+// Versions возвращает текущую версию схемы и версию, которую ожидает бинарь.
+func (m *Migrator) Versions(ctx context.Context) (current, expected int64, err error) {
+	current, expected, err = m.provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("migrate versions: %w", err)
+	}
+	return current, expected, nil
+}
+
+// Check возвращает ErrSchemaOutdated, если в базе применены не все миграции бинаря.
+func (m *Migrator) Check(ctx context.Context) error {
+	current, expected, err := m.Versions(ctx)
+	if err != nil {
+		return err
+	}
+	if current < expected {
+		return fmt.Errorf("%w: version %d, expected %d", ErrSchemaOutdated, current, expected)
 	}
 	return nil
 }
 
-// Down откатывает одну миграцию (опционально).
-func Down(db *sql.DB) error {
-	goose.SetDialect("postgres")
-	goose.SetBaseFS(migrationsFS)
+// Close закрывает *sql.DB, переданный в New.
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
 
-	if err := goose.Down(db, "schema"); err != nil {
-		return fmt.Errorf("goose down: %w", err)
+// Create создаёт пустую SQL-миграцию со следующим порядковым номером в dir.
+func Create(dir string, name string) error {
+	goose.SetSequential(true)
+	if err := goose.Create(nil, dir, name, "sql"); err != nil {
+		return fmt.Errorf("migrate create: %w", err)
 	}
 	return nil
 }
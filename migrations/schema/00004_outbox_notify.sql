@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS outbox_user_id_idx ON outbox (user_id, id);
+
+-- Уведомление уходит при коммите транзакции, записавшей событие, поэтому
+-- подписчики не видят событий откаченных транзакций. Полезная нагрузка
+-- событий мала, лимит pg_notify в 8000 байт ей не грозит.
+CREATE OR REPLACE FUNCTION outbox_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_events', json_build_object(
+        'id', NEW.id,
+        'type', NEW.event_type,
+        'user_id', NEW.user_id,
+        'payload', NEW.payload,
+        'created_at', NEW.created_at
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_notify_trg
+AFTER INSERT ON outbox
+FOR EACH ROW EXECUTE FUNCTION outbox_notify();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS outbox_notify_trg ON outbox;
+DROP FUNCTION IF EXISTS outbox_notify();
+DROP INDEX IF EXISTS outbox_user_id_idx;
+-- +goose StatementEnd
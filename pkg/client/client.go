@@ -0,0 +1,230 @@
+// Package client — Go-клиент HTTP API накопительной системы gophermart.
+//
+// Client хранит токен, полученный при Register или Login, и отправляет его
+// в cookie со всеми запросами; CSRF-токен (если сервер его выдаёт)
+// дублируется в заголовке X-CSRF-Token. Client безопасен для конкурентного
+// использования.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	tokenCookieName = "token"
+	csrfCookieName  = "csrf_token"
+	csrfHeader      = "X-CSRF-Token"
+
+	// maxErrorBody — сколько байт тела ошибки читается для разбора.
+	maxErrorBody = 1 << 16
+)
+
+type Client struct {
+	baseURL string
+	http    *http.Client
+
+	mu    sync.RWMutex
+	token string
+	csrf  string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient задаёт http.Client, например с таймаутом или своим транспортом.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithToken задаёт токен, полученный раньше, чтобы не вызывать Login.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// New создаёт клиент для сервера baseURL, например "https://gophermart.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("client.New: invalid base url %q", baseURL)
+	}
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token возвращает текущий токен, чтобы сохранить его между запусками.
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// Register создаёт пользователя и сразу авторизует клиент.
+func (c *Client) Register(ctx context.Context, login string, password string) error {
+	return c.auth(ctx, "/api/user/register", login, password)
+}
+
+func (c *Client) Login(ctx context.Context, login string, password string) error {
+	return c.auth(ctx, "/api/user/login", login, password)
+}
+
+func (c *Client) auth(ctx context.Context, path string, login string, password string) error {
+	resp, err := c.do(ctx, http.MethodPost, path, credentials{Login: login, Password: password})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var token, csrf string
+	for _, ck := range resp.Cookies() {
+		switch ck.Name {
+		case tokenCookieName:
+			token = ck.Value
+		case csrfCookieName:
+			csrf = ck.Value
+		}
+	}
+	if token == "" {
+		return fmt.Errorf("client: %s: no token in response", path)
+	}
+	c.mu.Lock()
+	c.token, c.csrf = token, csrf
+	c.mu.Unlock()
+	return nil
+}
+
+// AddOrder загружает номер заказа. alreadyUploaded — этот пользователь
+// загружал заказ раньше; заказ другого пользователя даёт ErrConflict.
+func (c *Client) AddOrder(ctx context.Context, number string) (alreadyUploaded bool, err error) {
+	resp, err := c.do(ctx, http.MethodPost, "/api/user/orders", number)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// Orders возвращает заказы пользователя, новые первыми. Пустой список — не ошибка.
+func (c *Client) Orders(ctx context.Context) ([]Order, error) {
+	orders := []Order{}
+	if err := c.getJSON(ctx, "/api/user/orders", &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (c *Client) Balance(ctx context.Context) (Balance, error) {
+	var b Balance
+	if err := c.getJSON(ctx, "/api/user/balance", &b); err != nil {
+		return Balance{}, err
+	}
+	return b, nil
+}
+
+// Withdraw списывает sum баллов в счёт заказа order.
+func (c *Client) Withdraw(ctx context.Context, order string, sum float64) error {
+	resp, err := c.do(ctx, http.MethodPost, "/api/user/balance/withdraw", withdrawRequest{Order: order, Sum: sum})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Withdrawals возвращает списания пользователя. Пустой список — не ошибка.
+func (c *Client) Withdrawals(ctx context.Context) ([]Withdrawal, error) {
+	list := []Withdrawal{}
+	if err := c.getJSON(ctx, "/api/user/withdrawals", &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// getJSON декодирует ответ в v; при 204 v остаётся как есть.
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("client: GET %s: decode response: %w", path, err)
+	}
+	return nil
+}
+
+// do отправляет запрос с токеном. Строковое тело уходит как text/plain,
+// остальное — как JSON. Ответы 4xx/5xx превращаются в *Error.
+func (c *Client) do(ctx context.Context, method string, path string, body any) (*http.Response, error) {
+	var (
+		r           io.Reader
+		contentType string
+	)
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r, contentType = strings.NewReader(b), "text/plain"
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("client: %s %s: %w", method, path, err)
+		}
+		r, contentType = bytes.NewReader(raw), "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, r)
+	if err != nil {
+		return nil, fmt.Errorf("client: %s %s: %w", method, path, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.mu.RLock()
+	if c.token != "" {
+		req.AddCookie(&http.Cookie{Name: tokenCookieName, Value: c.token})
+	}
+	if c.csrf != "" {
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: c.csrf})
+		req.Header.Set(csrfHeader, c.csrf)
+	}
+	c.mu.RUnlock()
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("client: %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, errorFromResponse(resp)
+}
+
+func errorFromResponse(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var p problem
+	if json.Unmarshal(raw, &p) == nil && p.Code != "" {
+		e.Code, e.Message = p.Code, p.Message
+		if p.RequestID != "" {
+			e.RequestID = p.RequestID
+		}
+		return e
+	}
+	e.Message = strings.TrimSpace(string(raw))
+	return e
+}
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IvanOplesnin/gofermart.git/internal/handler"
+	"github.com/IvanOplesnin/gofermart.git/internal/service/gophermart"
+)
+
+// memService — сервис в памяти за настоящим роутером handler.InitHandler.
+type memService struct {
+	mu        sync.Mutex
+	users     map[string]int32
+	owners    map[string]int32
+	orders    map[int32][]gophermart.UserOrder
+	balance   map[int32]float64
+	withdrawn map[int32][]gophermart.Withdrawal
+}
+
+func newMemService() *memService {
+	return &memService{
+		users:     map[string]int32{},
+		owners:    map[string]int32{},
+		orders:    map[int32][]gophermart.UserOrder{},
+		balance:   map[int32]float64{},
+		withdrawn: map[int32][]gophermart.Withdrawal{},
+	}
+}
+
+func token(userID int32) string { return "token-" + strconv.Itoa(int(userID)) }
+
+func (s *memService) Register(_ context.Context, login string, _ string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[login]; ok {
+		return "", gophermart.ErrUserAlreadyExists
+	}
+	id := int32(len(s.users) + 1)
+	s.users[login] = id
+	s.balance[id] = 100
+	return token(id), nil
+}
+
+func (s *memService) Auth(_ context.Context, login string, password string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.users[login]
+	if !ok || password != "pw" {
+		return "", gophermart.ErrInvalidPassword
+	}
+	return token(id), nil
+}
+
+func (s *memService) CheckToken(_ context.Context, tok string) (int32, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(tok, "token-"))
+	if err != nil || !strings.HasPrefix(tok, "token-") {
+		return 0, gophermart.ErrInvalidToken
+	}
+	return int32(id), nil
+}
+
+func (s *memService) AddOrder(_ context.Context, userID int32, number string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := strconv.Atoi(number); err != nil {
+		return false, gophermart.ErrInvalidOrderNumber
+	}
+	if owner, ok := s.owners[number]; ok {
+		if owner != userID {
+			return false, gophermart.ErrOrderOwnedByAnotherUser
+		}
+		return true, nil
+	}
+	s.owners[number] = userID
+	s.orders[userID] = append(s.orders[userID], gophermart.UserOrder{
+		Number:     number,
+		Status:     gophermart.StatusNew,
+		UploadedAt: time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC),
+	})
+	return false, nil
+}
+
+func (s *memService) Orders(_ context.Context, userID int32) ([]gophermart.UserOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.orders[userID], nil
+}
+
+func (s *memService) Balance(_ context.Context, userID int32) (gophermart.UserBalance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var withdrawn float64
+	for _, w := range s.withdrawn[userID] {
+		withdrawn += w.Sum
+	}
+	return gophermart.UserBalance{Current: s.balance[userID], Withdrawn: withdrawn}, nil
+}
+
+func (s *memService) Withdraw(_ context.Context, userID int32, order string, sum float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sum > s.balance[userID] {
+		return gophermart.ErrNotEnoughBalance
+	}
+	s.balance[userID] -= sum
+	s.withdrawn[userID] = append(s.withdrawn[userID], gophermart.Withdrawal{Order: order, Sum: sum, ProcessedAt: time.Now()})
+	return nil
+}
+
+func (s *memService) ListWithdraws(_ context.Context, userID int32) ([]gophermart.Withdrawal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.withdrawn[userID], nil
+}
+
+func newTestServer(t *testing.T, csrf bool) string {
+	t.Helper()
+	svc := newMemService()
+	router := handler.InitHandler(handler.HandlerDeps{
+		Reqistrar:    svc,
+		Auther:       svc,
+		TokenChecker: svc,
+		Ordered:      svc,
+		Balancer:     svc,
+		Withdrawer:   svc,
+		Cookies:      handler.CookieOptions{CSRF: csrf},
+	})
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestClient_Flow(t *testing.T) {
+	for _, csrf := range []bool{false, true} {
+		t.Run("csrf="+strconv.FormatBool(csrf), func(t *testing.T) {
+			ctx := context.Background()
+			c, err := New(newTestServer(t, csrf))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			if _, err := c.Orders(ctx); !errors.Is(err, ErrUnauthorized) {
+				t.Fatalf("Orders without token: err = %v", err)
+			}
+			if err := c.Register(ctx, "alice", "pw"); err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+			if c.Token() == "" {
+				t.Fatal("token must be stored after Register")
+			}
+
+			orders, err := c.Orders(ctx)
+			if err != nil || orders == nil || len(orders) != 0 {
+				t.Fatalf("empty Orders = %#v, %v", orders, err)
+			}
+			if again, err := c.AddOrder(ctx, "12345678903"); err != nil || again {
+				t.Fatalf("AddOrder = %v, %v", again, err)
+			}
+			if again, err := c.AddOrder(ctx, "12345678903"); err != nil || !again {
+				t.Fatalf("AddOrder again = %v, %v", again, err)
+			}
+			orders, err = c.Orders(ctx)
+			if err != nil || len(orders) != 1 || orders[0].Number != "12345678903" || orders[0].Status != StatusNew || orders[0].Accrual != nil {
+				t.Fatalf("Orders = %+v, %v", orders, err)
+			}
+
+			if err := c.Withdraw(ctx, "2377225624", 30); err != nil {
+				t.Fatalf("Withdraw: %v", err)
+			}
+			if err := c.Withdraw(ctx, "2377225624", 1000); !errors.Is(err, ErrPaymentRequired) {
+				t.Fatalf("Withdraw over balance: err = %v", err)
+			}
+			b, err := c.Balance(ctx)
+			if err != nil || b != (Balance{Current: 70, Withdrawn: 30}) {
+				t.Fatalf("Balance = %+v, %v", b, err)
+			}
+			list, err := c.Withdrawals(ctx)
+			if err != nil || len(list) != 1 || list[0].Order != "2377225624" || list[0].Sum != 30 {
+				t.Fatalf("Withdrawals = %+v, %v", list, err)
+			}
+		})
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctx := context.Background()
+	url := newTestServer(t, false)
+	alice, _ := New(url)
+	bob, _ := New(url)
+	if err := alice.Register(ctx, "alice", "pw"); err != nil {
+		t.Fatalf("Register alice: %v", err)
+	}
+	if err := bob.Register(ctx, "bob", "pw"); err != nil {
+		t.Fatalf("Register bob: %v", err)
+	}
+	if _, err := alice.AddOrder(ctx, "12345678903"); err != nil {
+		t.Fatalf("AddOrder: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		call     func() error
+		want     error
+		wantCode string
+	}{
+		{
+			name: "login taken",
+			call: func() error { return bob.Register(ctx, "alice", "pw") },
+			want: ErrConflict,
+		},
+		{
+			name: "wrong password",
+			call: func() error { return bob.Login(ctx, "bob", "nope") },
+			want: ErrUnauthorized,
+		},
+		{
+			name:     "order of another user",
+			call:     func() error { _, err := bob.AddOrder(ctx, "12345678903"); return err },
+			want:     ErrConflict,
+			wantCode: "order_owned_by_another_user",
+		},
+		{
+			name: "invalid order number",
+			call: func() error { _, err := bob.AddOrder(ctx, "abc"); return err },
+			want: ErrInvalidOrderNumber,
+		},
+		{
+			name: "empty withdrawals",
+			call: func() error {
+				list, err := bob.Withdrawals(ctx)
+				if err == nil && (list == nil || len(list) != 0) {
+					return errors.New("expected empty non-nil list")
+				}
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.RequestID == "" {
+				t.Fatalf("err = %#v, want *Error with request id", err)
+			}
+			if tt.wantCode != "" && apiErr.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", apiErr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, u := range []string{"", "localhost:8080", "ftp://example.com"} {
+		if _, err := New(u); err == nil {
+			t.Errorf("New(%q) must fail", u)
+		}
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	ctx := context.Background()
+	url := newTestServer(t, false)
+	first, _ := New(url)
+	if err := first.Register(ctx, "alice", "pw"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	second, _ := New(url, WithToken(first.Token()), WithHTTPClient(&http.Client{Timeout: time.Second}))
+	if _, err := second.Balance(ctx); err != nil {
+		t.Fatalf("Balance with stored token: %v", err)
+	}
+}
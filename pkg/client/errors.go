@@ -0,0 +1,56 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Ошибки, которые удобно проверять через errors.Is. Полные сведения
+// об ответе сервера — в *Error (errors.As).
+var (
+	// ErrUnauthorized — нет токена, он недействителен или неверны логин и пароль.
+	ErrUnauthorized = errors.New("gophermart: unauthorized")
+	// ErrConflict — логин занят или заказ загружен другим пользователем.
+	ErrConflict = errors.New("gophermart: conflict")
+	// ErrPaymentRequired — на балансе недостаточно баллов для списания.
+	ErrPaymentRequired = errors.New("gophermart: payment required")
+	// ErrInvalidOrderNumber — номер заказа не проходит проверку или списание по нему уже было.
+	ErrInvalidOrderNumber = errors.New("gophermart: invalid order number")
+)
+
+// codeInvalidOrderNumber — код ошибки API (поле code), см. /api/openapi.json.
+const codeInvalidOrderNumber = "invalid_order_number"
+
+// Error — ответ сервера со статусом 4xx/5xx в формате application/problem+json.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("gophermart: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrPaymentRequired:
+		return e.StatusCode == http.StatusPaymentRequired
+	case ErrInvalidOrderNumber:
+		return e.Code == codeInvalidOrderNumber
+	}
+	return false
+}
@@ -17,4 +17,4 @@ set +a
 # echo "dsn: ${DATABASE_DSN-}"
 # echo
 
-go run cmd/gophermart/main.go
+go run ./cmd/gophermart